@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/baseline"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	BaselineFlag       = "baseline"
+	UpdateBaselineFlag = "update-baseline"
+
+	ImportCycleExitCode = 2
+)
+
+type ImportCycleError struct {
+	Packages int
+	Files    int
+}
+
+func (err *ImportCycleError) Error() string {
+	return fmt.Sprintf(
+		"import cycles found: %d package(s), %d file(s)",
+		err.Packages,
+		err.Files,
+	)
+}
+
+// ExitCode is the status to exit with after the command returned err,
+// ImportCycleExitCode for an ImportCycleError
+func ExitCode(err error) int {
+	var importCycleErr *ImportCycleError
+	if errors.As(err, &importCycleErr) {
+		return ImportCycleExitCode
+	}
+	if err != nil {
+		return 1
+	}
+	return 0
+}
+
+func Check() *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:          "check",
+		Short:        "Fail if any import cycles are found",
+		Long:         "Report the packages and files in import cycles and exit with a non-zero status if any are found",
+		SilenceUsage: true,
+		RunE: func(self *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return self.Help()
+			}
+
+			path, err := self.Flags().GetString(PathFlag)
+			if err != nil {
+				return err
+			}
+			buildOpts, err := getBuildOptions(self)
+			if err != nil {
+				return err
+			}
+
+			_, pkgs, err := buildForPath(path, buildOpts)
+			if err != nil {
+				return err
+			}
+
+			baselineFile, err := self.Flags().GetString(BaselineFlag)
+			if err != nil {
+				return err
+			}
+			updateBaseline, err := self.Flags().GetBool(UpdateBaselineFlag)
+			if err != nil {
+				return err
+			}
+
+			if baselineFile == "" {
+				if updateBaseline {
+					return fmt.Errorf("--%s requires --%s", UpdateBaselineFlag, BaselineFlag)
+				}
+				return checkImportCycles(self.OutOrStdout(), pkgs)
+			}
+
+			maxCycles, err := getMaxCycles(self)
+			if err != nil {
+				return err
+			}
+			// a baseline of part of the cycles would hide the others
+			pkgCycles, pkgTruncated := cycles.FindAtMost(pkgs, internal.PackageResolution, maxCycles)
+			fileCycles, fileTruncated := cycles.FindAtMost(pkgs, internal.FileResolution, maxCycles)
+			if pkgTruncated || fileTruncated {
+				return fmt.Errorf("more than %d import cycles, raise --%s to compare them to the baseline", maxCycles, MaxCyclesFlag)
+			}
+			if updateBaseline {
+				return baseline.New(pkgCycles, fileCycles).WriteToFile(baselineFile)
+			}
+
+			known, err := baseline.GetBaselineFromFile(baselineFile)
+			if err != nil {
+				return err
+			}
+			return checkImportCyclesAgainstBaseline(
+				self.OutOrStdout(),
+				known.Unknown(pkgCycles),
+				known.Unknown(fileCycles),
+			)
+		},
+	}
+
+	checkCmd.Flags().String(PathFlag, "", "files to process")
+	addBuildFlags(checkCmd)
+	checkCmd.Flags().String(BaselineFlag, "", "baseline file of accepted import cycles")
+	checkCmd.Flags().Bool(UpdateBaselineFlag, false, "rewrite the baseline file with the current import cycles")
+	checkCmd.Flags().Int(MaxCyclesFlag, defaultMaxCycles, "maximum number of import cycles compared to the baseline, 0 compares every one")
+
+	return checkCmd
+}
+
+func checkImportCycles(w io.Writer, pkgs []*internal.Package) error {
+	pkgNames, fileNames := namesInImportCycles(pkgs)
+	if len(pkgNames) == 0 && len(fileNames) == 0 {
+		return nil
+	}
+	err := writeCheckReport(w, pkgNames, fileNames)
+	if err != nil {
+		return err
+	}
+	return &ImportCycleError{
+		Packages: len(pkgNames),
+		Files:    len(fileNames),
+	}
+}
+
+func checkImportCyclesAgainstBaseline(w io.Writer, pkgCycles, fileCycles []cycles.Cycle) error {
+	if len(pkgCycles) == 0 && len(fileCycles) == 0 {
+		return nil
+	}
+	sections := []struct {
+		header string
+		cycles []cycles.Cycle
+	}{
+		{header: "New package import cycles:", cycles: pkgCycles},
+		{header: "New file import cycles:", cycles: fileCycles},
+	}
+	for _, section := range sections {
+		if len(section.cycles) == 0 {
+			continue
+		}
+		_, err := fmt.Fprintln(w, section.header)
+		if err != nil {
+			return err
+		}
+		for _, cycle := range section.cycles {
+			_, err = fmt.Fprintf(w, "  %s\n", cycle.String())
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return &ImportCycleError{
+		Packages: countNodesInCycles(pkgCycles),
+		Files:    countNodesInCycles(fileCycles),
+	}
+}
+
+func countNodesInCycles(found []cycles.Cycle) int {
+	nodes := make(map[string]struct{})
+	for _, cycle := range found {
+		for _, node := range cycle.Nodes() {
+			nodes[node] = struct{}{}
+		}
+	}
+	return len(nodes)
+}
+
+func namesInImportCycles(pkgs []*internal.Package) ([]string, []string) {
+	var pkgNames []string
+	var fileNames []string
+	for _, pkg := range pkgs {
+		if pkg.InImportCycle {
+			pkgNames = append(pkgNames, pkg.ModuleRelativePath())
+		}
+		for _, file := range pkg.Files {
+			if !file.InImportCycle {
+				continue
+			}
+			fileNames = append(fileNames, cycles.FileName(file))
+		}
+	}
+	slices.Sort(pkgNames)
+	slices.Sort(fileNames)
+	return pkgNames, fileNames
+}
+
+func writeCheckReport(w io.Writer, pkgNames, fileNames []string) error {
+	sections := []struct {
+		header string
+		names  []string
+	}{
+		{header: "Packages in import cycles:", names: pkgNames},
+		{header: "Files in import cycles:", names: fileNames},
+	}
+	for _, section := range sections {
+		if len(section.names) == 0 {
+			continue
+		}
+		_, err := fmt.Fprintln(w, section.header)
+		if err != nil {
+			return err
+		}
+		for _, name := range section.names {
+			_, err = fmt.Fprintf(w, "  %s\n", name)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package cmd_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/cmd/godepvis/cmd"
+	"github.com/samlitowitz/godepvis/internal/test"
+	"github.com/spf13/cobra"
+)
+
+func TestCheck(t *testing.T) {
+	testCases := map[string]struct {
+		dir            string
+		expectedOutput string
+		expectedErr    *cmd.ImportCycleError
+		expectedCode   int
+	}{
+		"no cycles": {
+			dir: "no-circular-dependencies",
+		},
+		"direct cycle": {
+			dir: "direct-circular-dependency",
+			expectedOutput: `Packages in import cycles:
+  a
+  b
+Files in import cycles:
+  a/a.go
+  b/b.go
+`,
+			expectedErr:  &cmd.ImportCycleError{Packages: 2, Files: 2},
+			expectedCode: cmd.ImportCycleExitCode,
+		},
+		"transitive cycle": {
+			dir: "transitive-circular-dependency",
+			expectedOutput: `Packages in import cycles:
+  a
+  b
+  c
+Files in import cycles:
+  a/a.go
+  b/b.go
+  c/c.go
+`,
+			expectedErr:  &cmd.ImportCycleError{Packages: 3, Files: 3},
+			expectedCode: cmd.ImportCycleExitCode,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		output, err := runCommand(t, cmd.Check(), "--path", copyModule(t, testCase.dir), "--no-cache")
+
+		if testCase.expectedErr == nil {
+			if err != nil {
+				t.Errorf("%s: expected no error, got %v", desc, err)
+			}
+		} else {
+			var importCycleErr *cmd.ImportCycleError
+			if !errors.As(err, &importCycleErr) {
+				t.Fatalf("%s: expected an import cycle error, got %v", desc, err)
+			}
+			if diff := cmp.Diff(testCase.expectedErr, importCycleErr); diff != "" {
+				t.Error(desc, test.Mismatch(": expected error: ", diff))
+			}
+		}
+		if code := cmd.ExitCode(err); code != testCase.expectedCode {
+			t.Errorf("%s: expected exit code %d, got %d", desc, testCase.expectedCode, code)
+		}
+		if diff := cmp.Diff(testCase.expectedOutput, output); diff != "" {
+			t.Error(desc, test.Mismatch(": expected report: ", diff))
+		}
+	}
+}
+
+// copyModule copies the module of the primitives package's build-for-module
+// test data to a temporary directory
+func TestExitCode(t *testing.T) {
+	testCases := map[string]struct {
+		err          error
+		expectedCode int
+	}{
+		"no error":           {},
+		"error":              {err: errors.New("failed to find go.mod"), expectedCode: 1},
+		"import cycle error": {err: &cmd.ImportCycleError{Packages: 1}, expectedCode: cmd.ImportCycleExitCode},
+		"wrapped import cycle error": {
+			err:          fmt.Errorf("check: %w", &cmd.ImportCycleError{Files: 1}),
+			expectedCode: cmd.ImportCycleExitCode,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		if code := cmd.ExitCode(testCase.err); code != testCase.expectedCode {
+			t.Errorf("%s: expected exit code %d, got %d", desc, testCase.expectedCode, code)
+		}
+	}
+}
+
+func copyModule(t *testing.T, dir string) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	err := os.CopyFS(tmpDir, os.DirFS(filepath.Join("..", "..", "..", "internal", "primitives", "testdata", "build-for-module", dir)))
+	if err != nil {
+		t.Fatal("copy test data:", err)
+	}
+	return tmpDir
+}
+
+// runCommand runs the command with the arguments and returns its output
+func runCommand(t *testing.T, command *cobra.Command, args ...string) (string, error) {
+	t.Helper()
+	out := &bytes.Buffer{}
+	command.SetOut(out)
+	command.SetErr(io.Discard)
+	command.SetArgs(args)
+	err := command.Execute()
+	return out.String(), err
+}
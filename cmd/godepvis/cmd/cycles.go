@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/spf13/cobra"
+)
+
+const (
+	SuggestFlag   = "suggest"
+	MaxCyclesFlag = "max-cycles"
+
+	// defaultMaxCycles keeps listing the cycles of densely connected
+	// packages, the number of which grows exponentially, from running away
+	defaultMaxCycles = 1000
+)
+
+func Cycles() *cobra.Command {
+	resolution := resolutionFlag(internal.FileResolution)
+	cyclesCmd := &cobra.Command{
+		Use:          "cycles",
+		Short:        "List every elementary import cycle",
+		Long:         "List every elementary import cycle as an ordered path",
+		SilenceUsage: true,
+		RunE: func(self *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return self.Help()
+			}
+
+			path, err := self.Flags().GetString(PathFlag)
+			if err != nil {
+				return err
+			}
+			buildOpts, err := getBuildOptions(self)
+			if err != nil {
+				return err
+			}
+
+			if internal.Resolution(resolution.String()) == internal.DeclResolution {
+				return fmt.Errorf("--%s %s is not supported by cycles", ResolutionFlag, internal.DeclResolution)
+			}
+			suggest, err := self.Flags().GetBool(SuggestFlag)
+			if err != nil {
+				return err
+			}
+			maxCycles, err := getMaxCycles(self)
+			if err != nil {
+				return err
+			}
+
+			_, pkgs, err := buildForPath(path, buildOpts)
+			if err != nil {
+				return err
+			}
+
+			if suggest {
+				for _, suggestion := range cycles.Suggest(pkgs, internal.Resolution(resolution.String())) {
+					_, err = fmt.Fprintln(self.OutOrStdout(), suggestion.String())
+					if err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			found, truncated := cycles.FindAtMost(pkgs, internal.Resolution(resolution.String()), maxCycles)
+			for _, cycle := range found {
+				_, err = fmt.Fprintln(self.OutOrStdout(), cycle.String())
+				if err != nil {
+					return err
+				}
+			}
+			if truncated {
+				_, err = fmt.Fprintf(self.ErrOrStderr(), "stopped after %d import cycles, raise --%s to list more\n", maxCycles, MaxCyclesFlag)
+			}
+			return err
+		},
+	}
+
+	cyclesCmd.Flags().String(PathFlag, "", "files to process")
+	addBuildFlags(cyclesCmd)
+	cyclesCmd.Flags().Var(&resolution, ResolutionFlag, "resolution at which to list import cycles")
+	cyclesCmd.Flags().Bool(SuggestFlag, false, "list a minimal set of edges to cut to break every import cycle instead")
+	cyclesCmd.Flags().Int(MaxCyclesFlag, defaultMaxCycles, "maximum number of import cycles listed, 0 lists every one")
+
+	return cyclesCmd
+}
+
+func getMaxCycles(cmd *cobra.Command) (int, error) {
+	maxCycles, err := cmd.Flags().GetInt(MaxCyclesFlag)
+	if err != nil {
+		return 0, err
+	}
+	if maxCycles < 0 {
+		return 0, fmt.Errorf("--%s must not be negative", MaxCyclesFlag)
+	}
+	return maxCycles, nil
+}
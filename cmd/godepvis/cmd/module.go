@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/modfile"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/spf13/cobra"
+)
+
+const (
+	TypesFlag  = "types"
+	GOOSFlag   = "goos"
+	GOARCHFlag = "goarch"
+	TagsFlag   = "tags"
+	JobsFlag   = "jobs"
+
+	NoCacheFlag = "no-cache"
+
+	IncludeTestsFlag = "include-tests"
+)
+
+type buildOptions struct {
+	types bool
+	opts  []primitives.Option
+}
+
+// addBuildFlags adds the flags controlling how the module is analyzed
+func addBuildFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool(TypesFlag, false, "resolve references with the type checker, slower but exact")
+	cmd.Flags().String(GOOSFlag, "", "operating system to select files for, defaults to the host's")
+	cmd.Flags().String(GOARCHFlag, "", "architecture to select files for, defaults to the host's")
+	cmd.Flags().StringSlice(TagsFlag, nil, "comma separated build tags to select files for")
+	cmd.Flags().Bool(IncludeTestsFlag, false, "include test files and external test packages")
+	cmd.Flags().Int(JobsFlag, 0, "number of files parsed at once, defaults to the number of CPUs")
+	cmd.Flags().Bool(NoCacheFlag, false, "parse every file rather than reusing what's cached of unchanged files")
+}
+
+func getBuildOptions(cmd *cobra.Command) (buildOptions, error) {
+	types, err := cmd.Flags().GetBool(TypesFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	goos, err := cmd.Flags().GetString(GOOSFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	goarch, err := cmd.Flags().GetString(GOARCHFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	tags, err := cmd.Flags().GetStringSlice(TagsFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	includeTests, err := cmd.Flags().GetBool(IncludeTestsFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	jobs, err := cmd.Flags().GetInt(JobsFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	if jobs < 0 {
+		return buildOptions{}, fmt.Errorf("--%s must not be negative", JobsFlag)
+	}
+	noCache, err := cmd.Flags().GetBool(NoCacheFlag)
+	if err != nil {
+		return buildOptions{}, err
+	}
+	opts := buildOptions{
+		types: types,
+		opts: []primitives.Option{
+			primitives.WithGOOS(goos),
+			primitives.WithGOARCH(goarch),
+			primitives.WithBuildTags(tags...),
+			primitives.WithJobs(jobs),
+		},
+	}
+	if includeTests {
+		opts.opts = append(opts.opts, primitives.WithTests())
+	}
+	// without a user cache directory every file is parsed
+	if cacheDir, err := os.UserCacheDir(); err == nil && !noCache {
+		opts.opts = append(opts.opts, primitives.WithCacheDir(filepath.Join(cacheDir, "godepvis")))
+	}
+	return opts, nil
+}
+
+// buildForPath builds the workspace containing path, see go.work, or the
+// module containing path when it isn't part of a workspace. The name of the
+// module, or of the workspace's directory, is returned along with the
+// packages.
+func buildForPath(path string, opts buildOptions) (string, []*internal.Package, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	goWorkFile, err := modfile.FindGoWorkFile(absPath)
+	if err == nil {
+		return buildForWorkspace(goWorkFile, opts)
+	}
+	var notFound *modfile.FileNotFoundError
+	if !errors.As(err, &notFound) {
+		return "", nil, fmt.Errorf("failed to find go.work: %w", err)
+	}
+
+	goModFile, err := modfile.FindGoModFile(absPath)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to find go.mod: %w", err)
+	}
+
+	modulePath, err := modfile.GetModulePath(goModFile)
+	if err != nil {
+		return "", nil, err
+	}
+	moduleDir := filepath.Dir(goModFile)
+
+	build := primitives.BuildForModule
+	if opts.types {
+		build = primitives.BuildForModuleWithTypes
+	}
+	pkgs, err := build(modulePath, moduleDir, opts.opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	return modulePath, pkgs, nil
+}
+
+func buildForWorkspace(goWorkFile string, opts buildOptions) (string, []*internal.Package, error) {
+	moduleDirs, err := modfile.GetWorkspaceModuleDirs(goWorkFile)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get workspace modules: %w", err)
+	}
+	modules := make([]primitives.WorkspaceModule, 0, len(moduleDirs))
+	for _, moduleDir := range moduleDirs {
+		modulePath, err := modfile.GetModulePath(filepath.Join(moduleDir, "go.mod"))
+		if err != nil {
+			return "", nil, fmt.Errorf("%s: %w", moduleDir, err)
+		}
+		modules = append(modules, primitives.WorkspaceModule{
+			Path: modulePath,
+			Dir:  moduleDir,
+		})
+	}
+
+	build := primitives.BuildForWorkspace
+	if opts.types {
+		build = primitives.BuildForWorkspaceWithTypes
+	}
+	pkgs, err := build(modules, opts.opts...)
+	if err != nil {
+		return "", nil, err
+	}
+	return filepath.Base(filepath.Dir(goWorkFile)), pkgs, nil
+}
+
+// moduleDirsForPath returns the directories of the modules of the workspace
+// containing path, or of the module containing path, see buildForPath
+func moduleDirsForPath(path string) ([]string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	goWorkFile, err := modfile.FindGoWorkFile(absPath)
+	if err == nil {
+		moduleDirs, err := modfile.GetWorkspaceModuleDirs(goWorkFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get workspace modules: %w", err)
+		}
+		// the go.work file itself
+		return append(moduleDirs, filepath.Dir(goWorkFile)), nil
+	}
+	var notFound *modfile.FileNotFoundError
+	if !errors.As(err, &notFound) {
+		return nil, fmt.Errorf("failed to find go.work: %w", err)
+	}
+
+	goModFile, err := modfile.FindGoModFile(absPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find go.mod: %w", err)
+	}
+	return []string{filepath.Dir(goModFile)}, nil
+}
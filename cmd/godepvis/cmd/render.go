@@ -0,0 +1,293 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/dot"
+	"github.com/samlitowitz/godepvis/internal/gexf"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/graphml"
+	"github.com/samlitowitz/godepvis/internal/html"
+	"github.com/samlitowitz/godepvis/internal/json"
+	"github.com/samlitowitz/godepvis/internal/mermaid"
+	"github.com/samlitowitz/godepvis/internal/svg"
+	"github.com/spf13/cobra"
+)
+
+type renderOptions struct {
+	format        internal.Format
+	resolution    internal.Resolution
+	palette       *color.Palette
+	highlightCuts bool
+	stdlib        bool
+	external      externalOptions
+}
+
+// externalOptions select the packages of other modules and of the standard
+// library which are rendered
+type externalOptions struct {
+	show     bool
+	collapse bool
+	prefixes []string
+}
+
+// addRenderFlags adds the flags controlling how the dependency graph is
+// rendered
+func addRenderFlags(cmd *cobra.Command, resolution *resolutionFlag, format *formatFlag) {
+	cmd.Flags().String(PaletteFlag, "", "palette file")
+	cmd.Flags().Var(resolution, ResolutionFlag, "resolution at which to visualize dependencies")
+	cmd.Flags().Var(format, FormatFlag, "output format")
+	cmd.Flags().Bool(HighlightCutsFlag, false, "highlight a minimal set of edges to cut to break every import cycle")
+	cmd.Flags().Bool(StdlibFlag, false, "show the standard library module at the module resolution")
+	cmd.Flags().Bool(ExternalFlag, false, "show packages of other modules and of the standard library")
+	cmd.Flags().Bool(CollapseExternalFlag, false, "show a node per module rather than per package of other modules, implies --"+ExternalFlag)
+	cmd.Flags().StringSlice(ExternalPrefixFlag, nil, "only show packages of other modules whose import path starts with the prefix, implies --"+ExternalFlag)
+}
+
+// getRenderOptions fails on flags the format doesn't support and loads the
+// palette
+func getRenderOptions(cmd *cobra.Command, resolution resolutionFlag, format formatFlag) (renderOptions, error) {
+	paletteFile, err := cmd.Flags().GetString(PaletteFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	highlightCuts, err := cmd.Flags().GetBool(HighlightCutsFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	stdlib, err := cmd.Flags().GetBool(StdlibFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	external, err := cmd.Flags().GetBool(ExternalFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	collapseExternal, err := cmd.Flags().GetBool(CollapseExternalFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	externalPrefixes, err := cmd.Flags().GetStringSlice(ExternalPrefixFlag)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	external = external || collapseExternal || len(externalPrefixes) > 0
+	// the json output holds every resolution at once
+	err = checkFormatSupport(
+		ResolutionFlag,
+		cmd.Flags().Changed(ResolutionFlag),
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.GraphMLFormat,
+		internal.GEXFFormat,
+		internal.HTMLFormat,
+		internal.SVGFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	// declarations are clustered by file and package, which the
+	// html report and the svg layout don't nest
+	err = checkFormatSupport(
+		ResolutionFlag+" "+string(internal.DeclResolution),
+		internal.Resolution(resolution.String()) == internal.DeclResolution,
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.GraphMLFormat,
+		internal.GEXFFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	// the html report only expands packages into files
+	err = checkFormatSupport(
+		ResolutionFlag+" "+string(internal.ModuleResolution),
+		internal.Resolution(resolution.String()) == internal.ModuleResolution,
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.GraphMLFormat,
+		internal.GEXFFormat,
+		internal.SVGFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	err = checkFormatSupport(
+		HighlightCutsFlag,
+		highlightCuts,
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.GraphMLFormat,
+		internal.GEXFFormat,
+		internal.HTMLFormat,
+		internal.SVGFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	// graphml and gexf include every stub module
+	err = checkFormatSupport(
+		StdlibFlag,
+		stdlib,
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.SVGFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+	// the json output lists every stub package marked isStub, graphml and
+	// gexf include them unless narrowed by prefix or collapsed
+	err = checkFormatSupport(
+		ExternalFlag,
+		external,
+		internal.Format(format.String()),
+		internal.DOTFormat,
+		internal.MermaidFormat,
+		internal.GraphMLFormat,
+		internal.GEXFFormat,
+		internal.HTMLFormat,
+		internal.SVGFormat,
+	)
+	if err != nil {
+		return renderOptions{}, err
+	}
+
+	palette := color.DefaultPalette
+	if paletteFile != "" {
+		palette, err = color.GetPaletteFromFile(paletteFile)
+		if err != nil {
+			return renderOptions{}, err
+		}
+		if palette == nil {
+			palette = color.DefaultPalette
+		}
+	}
+
+	return renderOptions{
+		format:        internal.Format(format.String()),
+		resolution:    internal.Resolution(resolution.String()),
+		palette:       palette,
+		highlightCuts: highlightCuts,
+		stdlib:        stdlib,
+		external: externalOptions{
+			show:     external,
+			collapse: collapseExternal,
+			prefixes: externalPrefixes,
+		},
+	}, nil
+}
+
+// checkFormatSupport fails when the flag is set and the format is not one of
+// the formats supporting it, rather than silently ignoring the flag
+func checkFormatSupport(flag string, set bool, format internal.Format, supported ...internal.Format) error {
+	if !set || slices.Contains(supported, format) {
+		return nil
+	}
+	return fmt.Errorf("--%s is not supported for format %s", flag, format)
+}
+
+func render(modulePath string, pkgs []*internal.Package, opts renderOptions) ([]byte, error) {
+	switch opts.format {
+	case internal.DOTFormat:
+		dotOpts := []dot.Option{
+			dot.WithResolution(opts.resolution),
+			dot.WithPalette(*opts.palette),
+			dot.WithStdlib(opts.stdlib),
+			dot.WithStubs(opts.external.show),
+			dot.WithCollapsedStubs(opts.external.collapse),
+			dot.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			dotOpts = append(dotOpts, dot.WithHighlightedEdges(suggestedCuts(pkgs, opts.resolution)))
+		}
+		return dot.Marshal(modulePath, pkgs, dotOpts...)
+
+	case internal.JSONFormat:
+		return json.Marshal(modulePath, pkgs, json.WithIndent("\t"))
+
+	case internal.MermaidFormat:
+		mermaidOpts := []mermaid.Option{
+			mermaid.WithResolution(opts.resolution),
+			mermaid.WithPalette(*opts.palette),
+			mermaid.WithStdlib(opts.stdlib),
+			mermaid.WithStubs(opts.external.show),
+			mermaid.WithCollapsedStubs(opts.external.collapse),
+			mermaid.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			mermaidOpts = append(mermaidOpts, mermaid.WithHighlightedEdges(suggestedCuts(pkgs, opts.resolution)))
+		}
+		return mermaid.Marshal(modulePath, pkgs, mermaidOpts...)
+
+	case internal.GraphMLFormat:
+		graphmlOpts := []graphml.Option{
+			graphml.WithResolution(opts.resolution),
+			graphml.WithCollapsedStubs(opts.external.collapse),
+			graphml.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			graphmlOpts = append(graphmlOpts, graphml.WithHighlightedEdges(suggestedCuts(pkgs, opts.resolution)))
+		}
+		return graphml.Marshal(modulePath, pkgs, graphmlOpts...)
+
+	case internal.GEXFFormat:
+		gexfOpts := []gexf.Option{
+			gexf.WithResolution(opts.resolution),
+			gexf.WithCollapsedStubs(opts.external.collapse),
+			gexf.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			gexfOpts = append(gexfOpts, gexf.WithHighlightedEdges(suggestedCuts(pkgs, opts.resolution)))
+		}
+		return gexf.Marshal(modulePath, pkgs, gexfOpts...)
+
+	case internal.HTMLFormat:
+		htmlOpts := []html.Option{
+			html.WithResolution(opts.resolution),
+			html.WithPalette(*opts.palette),
+			html.WithStubs(opts.external.show),
+			html.WithCollapsedStubs(opts.external.collapse),
+			html.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			// the report shows both the package and the file edges
+			cuts := suggestedCuts(pkgs, internal.PackageResolution)
+			cuts = append(cuts, suggestedCuts(pkgs, internal.FileResolution)...)
+			htmlOpts = append(htmlOpts, html.WithHighlightedEdges(cuts))
+		}
+		return html.Marshal(modulePath, pkgs, htmlOpts...)
+
+	case internal.SVGFormat:
+		svgOpts := []svg.Option{
+			svg.WithResolution(opts.resolution),
+			svg.WithPalette(*opts.palette),
+			svg.WithStdlib(opts.stdlib),
+			svg.WithStubs(opts.external.show),
+			svg.WithCollapsedStubs(opts.external.collapse),
+			svg.WithStubPrefixes(opts.external.prefixes),
+		}
+		if opts.highlightCuts {
+			svgOpts = append(svgOpts, svg.WithHighlightedEdges(suggestedCuts(pkgs, opts.resolution)))
+		}
+		return svg.Marshal(modulePath, pkgs, svgOpts...)
+	}
+	return nil, fmt.Errorf("unsupported format: %s", opts.format)
+}
+
+func suggestedCuts(pkgs []*internal.Package, resolution internal.Resolution) []graph.EdgeID {
+	var cuts []graph.EdgeID
+	for _, suggestion := range cycles.Suggest(pkgs, resolution) {
+		cuts = append(cuts, suggestion.Edge.ID())
+	}
+	return cuts
+}
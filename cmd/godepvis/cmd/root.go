@@ -3,26 +3,31 @@
 import (
 	"fmt"
 	"github.com/samlitowitz/godepvis/internal"
-	"github.com/samlitowitz/godepvis/internal/color"
-	"github.com/samlitowitz/godepvis/internal/dot"
-	"github.com/samlitowitz/godepvis/internal/modfile"
-	"github.com/samlitowitz/godepvis/internal/primitives"
 	"github.com/spf13/cobra"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
 )
 
 const (
 	PaletteFlag    = "palette"
+	OutputFlag     = "output"
 	DotFlag        = "dot"
 	PathFlag       = "path"
 	ResolutionFlag = "resolution"
+	FormatFlag     = "format"
+
+	HighlightCutsFlag = "highlight-cuts"
+	StdlibFlag        = "stdlib"
+
+	ExternalFlag         = "external"
+	CollapseExternalFlag = "collapse-external"
+	ExternalPrefixFlag   = "external-prefix"
 )
 
 func Root() *cobra.Command {
 	var resolution resolutionFlag
+	format := formatFlag(internal.DOTFormat)
 	rootCmd := &cobra.Command{
 		Use:          "godepvis",
 		Short:        "Go Dependency Visualizer",
@@ -33,11 +38,7 @@ func Root() *cobra.Command {
 				return self.Help()
 			}
 
-			paletteFile, err := self.Flags().GetString(PaletteFlag)
-			if err != nil {
-				return err
-			}
-			dotFile, err := self.Flags().GetString(DotFlag)
+			outputFile, err := getOutputFile(self)
 			if err != nil {
 				return err
 			}
@@ -45,56 +46,32 @@ func Root() *cobra.Command {
 			if err != nil {
 				return nil
 			}
-
-			palette := color.DefaultPalette
-			if paletteFile != "" {
-				palette, err = color.GetPaletteFromFile(paletteFile)
-				if err != nil {
-					return err
-				}
-				if palette == nil {
-					palette = color.DefaultPalette
-				}
-			}
-
-			absPath, err := filepath.Abs(path)
+			buildOpts, err := getBuildOptions(self)
 			if err != nil {
 				return err
 			}
-
-			goModFile, err := modfile.FindGoModFile(absPath)
-			if err != nil {
-				log.Fatal(fmt.Errorf("failed to find go.mod: %w", err))
-			}
-
-			modulePath, err := modfile.GetModulePath(goModFile)
+			renderOpts, err := getRenderOptions(self, resolution, format)
 			if err != nil {
 				return err
 			}
-			moduleDir := filepath.Dir(goModFile)
 
-			pkgs, err := primitives.BuildForModule(modulePath, moduleDir)
+			modulePath, pkgs, err := buildForPath(path, buildOpts)
 			if err != nil {
 				log.Fatal(err)
 			}
 
-			output, err := dot.Marshal(
-				modulePath,
-				pkgs,
-				dot.WithResolution(internal.Resolution(resolution.String())),
-				dot.WithPalette(*palette),
-			)
+			output, err := render(modulePath, pkgs, renderOpts)
 			if err != nil {
 				log.Fatal(fmt.Errorf("marshal dependency graph: %w", err))
 			}
-			if dotFile == "" {
-				_, err := os.Stdout.Write(output)
+			if outputFile == "" {
+				_, err := self.OutOrStdout().Write(output)
 				if err != nil {
 					return err
 				}
 				return nil
 			}
-			err = os.WriteFile(dotFile, output, 0644)
+			err = os.WriteFile(outputFile, output, 0644)
 			if err != nil {
 				return err
 			}
@@ -102,17 +79,32 @@ func Root() *cobra.Command {
 		},
 	}
 
-	rootCmd.Flags().String(PaletteFlag, "", "palette file")
-	rootCmd.Flags().String(DotFlag, "", "DOT file to output")
+	addOutputFlags(rootCmd, "file to output, stdout if not set")
 	rootCmd.Flags().String(PathFlag, "", "files to process")
-	rootCmd.Flags().Var(&resolution, ResolutionFlag, "resolution at which to visualize dependencies")
+	addBuildFlags(rootCmd)
+	addRenderFlags(rootCmd, &resolution, &format)
 
-	err := rootCmd.MarkFlagRequired(DotFlag)
+	return rootCmd
+}
+
+// addOutputFlags adds --output along with --dot, its deprecated name from
+// when DOT was the only format
+func addOutputFlags(cmd *cobra.Command, usage string) {
+	cmd.Flags().StringP(OutputFlag, "o", "", usage)
+	cmd.Flags().String(DotFlag, "", usage)
+	err := cmd.Flags().MarkDeprecated(DotFlag, "use --"+OutputFlag+" instead")
 	if err != nil {
 		panic(err)
 	}
+	cmd.MarkFlagsMutuallyExclusive(OutputFlag, DotFlag)
+}
 
-	return rootCmd
+// getOutputFile returns the file set by --output, or by --dot
+func getOutputFile(cmd *cobra.Command) (string, error) {
+	if cmd.Flags().Changed(DotFlag) {
+		return cmd.Flags().GetString(DotFlag)
+	}
+	return cmd.Flags().GetString(OutputFlag)
 }
 
 type resolutionFlag []byte
@@ -132,3 +124,21 @@ func (rf *resolutionFlag) Set(v string) error {
 func (rf *resolutionFlag) Type() string {
 	return "resolutionFlag"
 }
+
+type formatFlag []byte
+
+func (ff *formatFlag) String() string {
+	return string(*ff)
+}
+
+func (ff *formatFlag) Set(v string) error {
+	if internal.IsValidFormat(internal.Format(v)) {
+		*ff = formatFlag(v)
+		return nil
+	}
+	return fmt.Errorf("must be one of: %s", strings.Join(internal.ValidFormats(), ", "))
+}
+
+func (ff *formatFlag) Type() string {
+	return "formatFlag"
+}
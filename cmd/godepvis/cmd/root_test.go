@@ -0,0 +1,45 @@
+package cmd_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/samlitowitz/godepvis/cmd/godepvis/cmd"
+)
+
+func TestRoot_Output(t *testing.T) {
+	dir := copyModule(t, "direct-circular-dependency")
+
+	stdout, err := runCommand(t, cmd.Root(), "--path", dir, "--no-cache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stdout == "" {
+		t.Fatal("expected the output on stdout")
+	}
+
+	for _, flag := range []string{"--output", "-o", "--dot"} {
+		outputFile := filepath.Join(t.TempDir(), "imports.gv")
+		out, err := runCommand(t, cmd.Root(), "--path", dir, "--no-cache", flag, outputFile)
+		if err != nil {
+			t.Fatalf("%s: %s", flag, err)
+		}
+		// the deprecation notice of --dot is written to stdout
+		if out != "" && flag != "--dot" {
+			t.Errorf("%s: expected nothing on stdout, got\n%s", flag, out)
+		}
+		output, err := os.ReadFile(outputFile)
+		if err != nil {
+			t.Fatalf("%s: %s", flag, err)
+		}
+		if len(output) == 0 {
+			t.Errorf("%s: expected the output in %s", flag, outputFile)
+		}
+	}
+
+	_, err = runCommand(t, cmd.Root(), "--path", dir, "--output", "a.gv", "--dot", "b.gv")
+	if err == nil {
+		t.Error("expected an error for both --output and --dot")
+	}
+}
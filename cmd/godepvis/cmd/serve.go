@@ -0,0 +1,171 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/serve"
+	"github.com/samlitowitz/godepvis/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+const (
+	AddrFlag = "addr"
+
+	defaultAddr = "localhost:8080"
+
+	// shutdownTimeout is how long requests in flight are waited for once
+	// interrupted
+	shutdownTimeout = 5 * time.Second
+)
+
+func Serve() *cobra.Command {
+	serveCmd := &cobra.Command{
+		Use:          "serve",
+		Short:        "Serve a web UI and a JSON API exploring the dependency graph",
+		Long:         "Serve a web UI and a JSON API exploring the dependency graph, which is rebuilt whenever a file of the module changes",
+		SilenceUsage: true,
+		RunE: func(self *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return self.Help()
+			}
+
+			addr, err := self.Flags().GetString(AddrFlag)
+			if err != nil {
+				return err
+			}
+			path, err := self.Flags().GetString(PathFlag)
+			if err != nil {
+				return err
+			}
+			buildOpts, err := getBuildOptions(self)
+			if err != nil {
+				return err
+			}
+			paletteFile, err := self.Flags().GetString(PaletteFlag)
+			if err != nil {
+				return err
+			}
+			interval, err := self.Flags().GetDuration(IntervalFlag)
+			if err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--%s must be positive", IntervalFlag)
+			}
+			maxCycles, err := getMaxCycles(self)
+			if err != nil {
+				return err
+			}
+
+			palette := color.DefaultPalette
+			if paletteFile != "" {
+				palette, err = color.GetPaletteFromFile(paletteFile)
+				if err != nil {
+					return err
+				}
+				if palette == nil {
+					palette = color.DefaultPalette
+				}
+			}
+
+			dirs, err := moduleDirsForPath(path)
+			if err != nil {
+				return err
+			}
+			poller, err := watch.NewPoller(dirs, interval)
+			if err != nil {
+				return err
+			}
+
+			server := serve.New(
+				func() (string, []*internal.Package, error) {
+					return buildForPath(path, buildOpts)
+				},
+				serve.WithPalette(*palette),
+				serve.WithMaxCycles(maxCycles),
+			)
+			err = server.Rebuild()
+			if err != nil {
+				return err
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(self.OutOrStdout(), "serving http://%s/\n", listener.Addr())
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(self.Context(), os.Interrupt)
+			defer stop()
+
+			httpServer := &http.Server{Handler: server.Handler()}
+			served := make(chan error, 1)
+			go func() {
+				served <- httpServer.Serve(listener)
+			}()
+			watched := make(chan error, 1)
+			go func() {
+				watched <- rebuildOnChange(ctx, poller, server, self)
+			}()
+
+			select {
+			case err = <-served:
+			case err = <-watched:
+			case <-ctx.Done():
+			}
+			stop()
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+			defer cancel()
+			if shutdownErr := httpServer.Shutdown(shutdownCtx); err == nil {
+				err = shutdownErr
+			}
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			return err
+		},
+	}
+
+	serveCmd.Flags().String(AddrFlag, defaultAddr, "address to listen on")
+	serveCmd.Flags().String(PathFlag, "", "files to process")
+	serveCmd.Flags().String(PaletteFlag, "", "palette file")
+	addBuildFlags(serveCmd)
+	serveCmd.Flags().Duration(IntervalFlag, defaultInterval, "how often the module is checked for changes")
+	serveCmd.Flags().Int(MaxCyclesFlag, defaultMaxCycles, "maximum number of import cycles listed, 0 lists every one")
+
+	return serveCmd
+}
+
+// rebuildOnChange rebuilds the served module whenever a file changes until
+// the context is done, the previous build is served when the module doesn't
+// build, e.g. halfway through an edit
+func rebuildOnChange(ctx context.Context, poller *watch.Poller, server *serve.Server, cmd *cobra.Command) error {
+	for {
+		_, err := poller.Wait(ctx)
+		if errors.Is(err, context.Canceled) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := server.Rebuild(); err != nil {
+			_, err = fmt.Fprintln(cmd.ErrOrStderr(), err)
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
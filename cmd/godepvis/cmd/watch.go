@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+const (
+	IntervalFlag = "interval"
+
+	defaultInterval = 500 * time.Millisecond
+)
+
+var watchFormats = []internal.Format{
+	internal.DOTFormat,
+	internal.SVGFormat,
+	internal.HTMLFormat,
+}
+
+func Watch() *cobra.Command {
+	resolution := resolutionFlag(internal.FileResolution)
+	format := formatFlag(internal.DOTFormat)
+	watchCmd := &cobra.Command{
+		Use:          "watch",
+		Short:        "Rewrite the output whenever the module changes",
+		Long:         "Rewrite the output whenever a file of the module changes and print the import cycles which appear or disappear",
+		SilenceUsage: true,
+		RunE: func(self *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return self.Help()
+			}
+
+			outputFile, err := getOutputFile(self)
+			if err != nil {
+				return err
+			}
+			path, err := self.Flags().GetString(PathFlag)
+			if err != nil {
+				return err
+			}
+			buildOpts, err := getBuildOptions(self)
+			if err != nil {
+				return err
+			}
+			renderOpts, err := getRenderOptions(self, resolution, format)
+			if err != nil {
+				return err
+			}
+			// outputs which are kept open and reloaded
+			if !slices.Contains(watchFormats, renderOpts.format) {
+				return fmt.Errorf("--%s %s is not supported by watch", FormatFlag, format.String())
+			}
+			interval, err := self.Flags().GetDuration(IntervalFlag)
+			if err != nil {
+				return err
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--%s must be positive", IntervalFlag)
+			}
+			maxCycles, err := getMaxCycles(self)
+			if err != nil {
+				return err
+			}
+
+			dirs, err := moduleDirsForPath(path)
+			if err != nil {
+				return err
+			}
+			poller, err := watch.NewPoller(dirs, interval)
+			if err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(self.Context(), os.Interrupt)
+			defer stop()
+
+			noCache, err := self.Flags().GetBool(NoCacheFlag)
+			if err != nil {
+				return err
+			}
+			// only the changed files are parsed again on rebuilds
+			var cache *primitives.Cache
+			if !noCache {
+				cache = primitives.NewCache()
+				buildOpts.opts = append(buildOpts.opts, primitives.WithCache(cache))
+			}
+			w := &watcher{
+				out:        self.OutOrStdout(),
+				errOut:     self.ErrOrStderr(),
+				path:       path,
+				outputFile: outputFile,
+				buildOpts:  buildOpts,
+				renderOpts: renderOpts,
+				maxCycles:  maxCycles,
+				cache:      cache,
+			}
+			var changed []string
+			for {
+				// the build and the output are left as they were until
+				// the next change when the module doesn't build, e.g.
+				// halfway through an edit
+				if err := w.rebuild(changed); err != nil {
+					_, err = fmt.Fprintln(w.errOut, err)
+					if err != nil {
+						return err
+					}
+				}
+				changed, err = poller.Wait(ctx)
+				if errors.Is(err, context.Canceled) {
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+			}
+		},
+	}
+
+	addOutputFlags(watchCmd, "file to output")
+	watchCmd.Flags().String(PathFlag, "", "files to process")
+	addBuildFlags(watchCmd)
+	addRenderFlags(watchCmd, &resolution, &format)
+	watchCmd.Flags().Duration(IntervalFlag, defaultInterval, "how often the module is checked for changes")
+	watchCmd.Flags().Int(MaxCyclesFlag, defaultMaxCycles, "maximum number of import cycles compared between builds, 0 compares every one")
+
+	watchCmd.MarkFlagsOneRequired(OutputFlag, DotFlag)
+
+	return watchCmd
+}
+
+// watcher rebuilds the module, rewrites the output and reports the import
+// cycles which appeared or disappeared since the last build
+type watcher struct {
+	out, errOut io.Writer
+
+	path       string
+	outputFile string
+	buildOpts  buildOptions
+	renderOpts renderOptions
+	maxCycles  int
+	cache      *primitives.Cache
+
+	// built is set once the module built, found are the import cycles of
+	// the last build and truncated is set when more than maxCycles of
+	// either resolution were found
+	built     bool
+	found     []cycles.Cycle
+	truncated bool
+}
+
+// rebuild builds the module again, only the changed files and the files
+// which weren't built before are parsed
+func (w *watcher) rebuild(changed []string) error {
+	w.cache.Forget(changed...)
+	modulePath, pkgs, err := buildForPath(w.path, w.buildOpts)
+	if err != nil {
+		return err
+	}
+	output, err := render(modulePath, pkgs, w.renderOpts)
+	if err != nil {
+		return fmt.Errorf("marshal dependency graph: %w", err)
+	}
+	err = os.WriteFile(w.outputFile, output, 0644)
+	if err != nil {
+		return err
+	}
+
+	pkgCycles, pkgTruncated := cycles.FindAtMost(pkgs, internal.PackageResolution, w.maxCycles)
+	fileCycles, fileTruncated := cycles.FindAtMost(pkgs, internal.FileResolution, w.maxCycles)
+	found := append(pkgCycles, fileCycles...)
+	truncated := pkgTruncated || fileTruncated
+	if truncated {
+		_, err = fmt.Fprintf(w.errOut, "stopped after %d import cycles, raise --%s to compare them\n", w.maxCycles, MaxCyclesFlag)
+		if err != nil {
+			return err
+		}
+	}
+	built, previous, wasTruncated := w.built, w.found, w.truncated
+	w.built, w.found, w.truncated = true, found, truncated
+	// which cycles are found within the limit changes along with the
+	// module, comparing them would report cycles which neither appeared
+	// nor disappeared
+	if !built || truncated || wasTruncated {
+		return nil
+	}
+	appeared, disappeared := watch.DiffCycles(previous, found)
+	for _, cycle := range appeared {
+		_, err = fmt.Fprintf(w.out, "+ %s import cycle: %s\n", cycle.Resolution, cycle.String())
+		if err != nil {
+			return err
+		}
+	}
+	for _, cycle := range disappeared {
+		_, err = fmt.Fprintf(w.out, "- %s import cycle: %s\n", cycle.Resolution, cycle.String())
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
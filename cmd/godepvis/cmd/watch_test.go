@@ -0,0 +1,133 @@
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/samlitowitz/godepvis/cmd/godepvis/cmd"
+)
+
+func TestWatch_DefaultsToFileResolution(t *testing.T) {
+	dir := copyModule(t, "direct-circular-dependency")
+	outputFile := filepath.Join(t.TempDir(), "imports.gv")
+
+	// the module is built once before the canceled context is noticed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watchCmd := cmd.Watch()
+	watchCmd.SetOut(&bytes.Buffer{})
+	watchCmd.SetErr(&bytes.Buffer{})
+	watchCmd.SetArgs([]string{"--path", dir, "--output", outputFile, "--no-cache"})
+	err := watchCmd.ExecuteContext(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := os.ReadFile(outputFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(output, []byte("a.go")) {
+		t.Errorf("expected the files of the module in the output, got\n%s", output)
+	}
+}
+
+func TestWatch_DoesNotCompareTruncatedCycles(t *testing.T) {
+	dir := copyModule(t, "multiple-interlinked-direct-circular-dependencies")
+	outputFile := filepath.Join(t.TempDir(), "imports.gv")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+	watchCmd := cmd.Watch()
+	watchCmd.SetOut(out)
+	watchCmd.SetErr(errOut)
+	watchCmd.SetArgs([]string{"--path", dir, "--output", outputFile, "--no-cache", "--max-cycles", "1", "--interval", "10ms"})
+	done := make(chan error, 1)
+	go func() {
+		done <- watchCmd.ExecuteContext(ctx)
+	}()
+
+	initial := waitForOutput(t, outputFile, nil)
+	// a no longer imports b, which leaves the cycle between b and c
+	err := os.WriteFile(filepath.Join(dir, "a", "a.go"), []byte("package a\n\nfunc Fn() {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, outputFile, initial)
+	// the rebuild in progress completes before the canceled context is
+	// noticed
+	cancel()
+	err = <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("expected no import cycle diff, got\n%s", out.String())
+	}
+	notices := strings.Count(errOut.String(), "stopped after 1 import cycles")
+	if notices != 1 {
+		t.Errorf("expected 1 notice for the first build, got %d in\n%s", notices, errOut.String())
+	}
+}
+
+func TestWatch_ParsesChangedFiles(t *testing.T) {
+	// the files are cached in memory, and on disk below the test's directory
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	dir := copyModule(t, "direct-circular-dependency")
+	outputFile := filepath.Join(t.TempDir(), "imports.gv")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	out := &bytes.Buffer{}
+	watchCmd := cmd.Watch()
+	watchCmd.SetOut(out)
+	watchCmd.SetErr(&bytes.Buffer{})
+	watchCmd.SetArgs([]string{"--path", dir, "--output", outputFile, "--interval", "10ms"})
+	done := make(chan error, 1)
+	go func() {
+		done <- watchCmd.ExecuteContext(ctx)
+	}()
+
+	initial := waitForOutput(t, outputFile, nil)
+	// a no longer imports b, which breaks the cycle
+	err := os.WriteFile(filepath.Join(dir, "a", "a.go"), []byte("package a\n\nfunc Fn() {}\n"), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	waitForOutput(t, outputFile, initial)
+	// the rebuild in progress completes before the canceled context is
+	// noticed
+	cancel()
+	err = <-done
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "- file import cycle: a/a.go -> b/b.go via b.Fn -> a/a.go via a.Fn\n"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("expected %q in\n%s", expected, out.String())
+	}
+}
+
+// waitForOutput waits until the file exists and its content differs from
+// previous, and returns the content
+func waitForOutput(t *testing.T, file string, previous []byte) []byte {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		output, err := os.ReadFile(file)
+		if err == nil && len(output) != 0 && !bytes.Equal(output, previous) {
+			return output
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("%s wasn't written", file)
+	return nil
+}
@@ -15,12 +15,19 @@ func main() {
 	// Setup commands
 	rootCmd := cmd.Root()
 	versionCmd := cmd.Version(Build, Commit, Version)
+	cyclesCmd := cmd.Cycles()
+	checkCmd := cmd.Check()
+	watchCmd := cmd.Watch()
+	serveCmd := cmd.Serve()
 
 	rootCmd.AddCommand(versionCmd)
+	rootCmd.AddCommand(cyclesCmd)
+	rootCmd.AddCommand(checkCmd)
+	rootCmd.AddCommand(watchCmd)
+	rootCmd.AddCommand(serveCmd)
 
 	err := rootCmd.Execute()
-
-	if err != nil {
-		os.Exit(1)
+	if code := cmd.ExitCode(err); code != 0 {
+		os.Exit(code)
 	}
 }
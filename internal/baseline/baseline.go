@@ -0,0 +1,115 @@
+package baseline
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	cycleKeySeparator = " -> "
+)
+
+// Baseline is the set of known import cycles which are accepted, each cycle
+// is the ordered list of package or file names along its path
+type Baseline struct {
+	Packages [][]string `mapstructure:"packages" yaml:"packages"`
+	Files    [][]string `mapstructure:"files" yaml:"files"`
+}
+
+func New(pkgCycles, fileCycles []cycles.Cycle) *Baseline {
+	b := &Baseline{}
+	for _, cycle := range pkgCycles {
+		b.Packages = append(b.Packages, normalize(cycle.Nodes()))
+	}
+	for _, cycle := range fileCycles {
+		b.Files = append(b.Files, normalize(cycle.Nodes()))
+	}
+	return b
+}
+
+func GetBaselineFromFile(file string) (*Baseline, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	err := v.ReadInConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+
+	b := &Baseline{}
+	err = v.Unmarshal(b)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load baseline: %w", err)
+	}
+	return b, nil
+}
+
+func (b *Baseline) WriteToFile(file string) error {
+	data, err := yaml.Marshal(b)
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	err = os.WriteFile(file, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write baseline: %w", err)
+	}
+	return nil
+}
+
+// Contains reports whether the cycle, regardless of which node it starts
+// from, is part of the baseline
+func (b *Baseline) Contains(cycle cycles.Cycle) bool {
+	var known [][]string
+	switch cycle.Resolution {
+	case internal.PackageResolution:
+		known = b.Packages
+	case internal.FileResolution:
+		known = b.Files
+	default:
+		return false
+	}
+	key := cycleKey(normalize(cycle.Nodes()))
+	for _, nodes := range known {
+		if cycleKey(normalize(nodes)) == key {
+			return true
+		}
+	}
+	return false
+}
+
+// Unknown returns the cycles which are not part of the baseline
+func (b *Baseline) Unknown(found []cycles.Cycle) []cycles.Cycle {
+	var unknown []cycles.Cycle
+	for _, cycle := range found {
+		if b.Contains(cycle) {
+			continue
+		}
+		unknown = append(unknown, cycle)
+	}
+	return unknown
+}
+
+// normalize rotates the cycle to start with its lexically smallest node so
+// the same cycle always has the same representation
+func normalize(nodes []string) []string {
+	if len(nodes) == 0 {
+		return nodes
+	}
+	start := 0
+	for i, node := range nodes {
+		if node < nodes[start] {
+			start = i
+		}
+	}
+	return slices.Concat(nodes[start:], nodes[:start])
+}
+
+func cycleKey(nodes []string) string {
+	return strings.Join(nodes, cycleKeySeparator)
+}
@@ -0,0 +1,124 @@
+package baseline_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/baseline"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGetBaselineFromFile(t *testing.T) {
+	// REFURL: https://github.com/golang/go/blob/988b718f4130ab5b3ce5a5774e1a58e83c92a163/src/path/filepath/path_test.go#L600
+	// -- START -- //
+	if runtime.GOOS == "ios" {
+		restore := test.Chtmpdir(t)
+		defer restore()
+	}
+
+	tmpDir := t.TempDir()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal("finding working dir:", err)
+	}
+	if err = os.Chdir(tmpDir); err != nil {
+		t.Fatal("entering temp dir:", err)
+	}
+	defer os.Chdir(origDir)
+	// -- END -- //
+
+	expectedBaseline := baseline.New(
+		[]cycles.Cycle{
+			buildCycle(internal.PackageResolution, "a", "b"),
+		},
+		[]cycles.Cycle{
+			buildCycle(internal.FileResolution, "a/a.go", "b/b.go", "c/c.go"),
+		},
+	)
+	baselinePath := tmpDir + string(os.PathSeparator) + "baseline.yaml"
+	err = expectedBaseline.WriteToFile(baselinePath)
+	if err != nil {
+		t.Fatalf("failed to write baseline: %v", err)
+	}
+	actualBaseline, err := baseline.GetBaselineFromFile(baselinePath)
+	if err != nil {
+		t.Fatalf("failed to load baseline: %v", err)
+	}
+	if diff := cmp.Diff(expectedBaseline, actualBaseline); diff != "" {
+		t.Fatal(test.Mismatch("", diff))
+	}
+}
+
+func TestBaseline_Unknown(t *testing.T) {
+	known := baseline.New(
+		[]cycles.Cycle{
+			buildCycle(internal.PackageResolution, "a", "b"),
+		},
+		[]cycles.Cycle{
+			buildCycle(internal.FileResolution, "a/a.go", "b/b.go", "c/c.go"),
+		},
+	)
+
+	testCases := map[string]struct {
+		found           []cycles.Cycle
+		expectedUnknown []string
+	}{
+		"all known": {
+			found: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+				buildCycle(internal.FileResolution, "a/a.go", "b/b.go", "c/c.go"),
+			},
+		},
+		"known with different starting node": {
+			found: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "b", "a"),
+				buildCycle(internal.FileResolution, "c/c.go", "a/a.go", "b/b.go"),
+			},
+		},
+		"same nodes in a different order": {
+			found: []cycles.Cycle{
+				buildCycle(internal.FileResolution, "a/a.go", "c/c.go", "b/b.go"),
+			},
+			expectedUnknown: []string{"a/a.go -> c/c.go -> b/b.go -> a/a.go"},
+		},
+		"same nodes at a different resolution": {
+			found: []cycles.Cycle{
+				buildCycle(internal.FileResolution, "a", "b"),
+			},
+			expectedUnknown: []string{"a -> b -> a"},
+		},
+		"new cycle": {
+			found: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+				buildCycle(internal.PackageResolution, "b", "c"),
+			},
+			expectedUnknown: []string{"b -> c -> b"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		var actualUnknown []string
+		for _, cycle := range known.Unknown(testCase.found) {
+			actualUnknown = append(actualUnknown, cycle.String())
+		}
+		if diff := cmp.Diff(testCase.expectedUnknown, actualUnknown); diff != "" {
+			t.Error(desc, test.Mismatch(": expected unknown cycles: ", diff))
+		}
+	}
+}
+
+func buildCycle(resolution internal.Resolution, nodes ...string) cycles.Cycle {
+	cycle := cycles.Cycle{Resolution: resolution}
+	for i, node := range nodes {
+		cycle.Hops = append(cycle.Hops, cycles.Hop{
+			From: node,
+			To:   nodes[(i+1)%len(nodes)],
+		})
+	}
+	return cycle
+}
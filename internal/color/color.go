@@ -26,10 +26,14 @@ func colorHookFunc() mapstructure.DecodeHookFunc {
 		t reflect.Type,
 		data interface{},
 	) (interface{}, error) {
-		if f.Kind() != reflect.String {
+		if t != reflect.TypeOf(Color{}) {
 			return data, nil
 		}
-		if t != reflect.TypeOf(Color{}) {
+		// a marshaled Color, e.g. {color: {r: 255, g: 0, b: 0, a: 0}}
+		if f.Kind() == reflect.Map {
+			return decodeRGBA(data)
+		}
+		if f.Kind() != reflect.String {
 			return data, nil
 		}
 		in := data.(string)
@@ -55,3 +59,20 @@ func colorHookFunc() mapstructure.DecodeHookFunc {
 		return nil, colors.ErrBadColor
 	}
 }
+
+func decodeRGBA(data interface{}) (interface{}, error) {
+	var marshaled struct {
+		Color map[string]interface{} `mapstructure:"color"`
+	}
+	if err := mapstructure.Decode(data, &marshaled); err != nil {
+		return nil, err
+	}
+	if marshaled.Color == nil {
+		return data, nil
+	}
+	rgba := &color.RGBA{}
+	if err := mapstructure.Decode(marshaled.Color, rgba); err != nil {
+		return nil, err
+	}
+	return Color{rgba}, nil
+}
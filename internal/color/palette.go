@@ -17,6 +17,40 @@ type HalfPalette struct {
 type Palette struct {
 	Base  *HalfPalette `mapstructure:"base"`
 	Cycle *HalfPalette `mapstructure:"cycle"`
+	// Test and TestCycle are used for test files and external test packages
+	Test      *HalfPalette `mapstructure:"test"`
+	TestCycle *HalfPalette `mapstructure:"testcycle"`
+	// External and Stdlib are used for packages and modules outside of the
+	// analyzed modules, i.e. stubs, and of the standard library respectively
+	External *HalfPalette `mapstructure:"external"`
+	Stdlib   *HalfPalette `mapstructure:"stdlib"`
+}
+
+// Half selects the half-palette for a node, or the edge leaving it, falling
+// back to the base and cycle half-palettes for tests if they are not set
+func (p *Palette) Half(isTest, inImportCycle bool) *HalfPalette {
+	switch {
+	case isTest && inImportCycle && p.TestCycle != nil:
+		return p.TestCycle
+	case isTest && !inImportCycle && p.Test != nil:
+		return p.Test
+	case inImportCycle:
+		return p.Cycle
+	}
+	return p.Base
+}
+
+// Stub selects the half-palette for a stub package or module, falling back
+// to the external half-palette for the standard library and to the base
+// half-palette if they are not set
+func (p *Palette) Stub(isStdlib bool) *HalfPalette {
+	switch {
+	case isStdlib && p.Stdlib != nil:
+		return p.Stdlib
+	case p.External != nil:
+		return p.External
+	}
+	return p.Base
 }
 
 var (
@@ -105,6 +139,174 @@ type Palette struct {
 				},
 			},
 		},
+		Test: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+		},
+		TestCycle: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 0,
+					B: 255,
+					A: 0,
+				},
+			},
+		},
+		External: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+		},
+		Stdlib: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 128,
+					B: 0,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 128,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 255,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 128,
+					B: 0,
+					A: 0,
+				},
+			},
+		},
 	}
 	InvertedDefaultPalette = &Palette{
 		Base: &HalfPalette{
@@ -191,9 +393,180 @@ type Palette struct {
 				},
 			},
 		},
+		Test: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 255,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+		},
+		TestCycle: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 0,
+					A: 0,
+				},
+			},
+		},
+		External: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 128,
+					G: 128,
+					B: 128,
+					A: 0,
+				},
+			},
+		},
+		Stdlib: &HalfPalette{
+			PackageName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 128,
+					A: 0,
+				},
+			},
+			PackageBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			FileName: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 128,
+					A: 0,
+				},
+			},
+			FileBackground: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 0,
+					B: 0,
+					A: 0,
+				},
+			},
+			ImportArrow: Color{
+				Color: &color.RGBA{
+					R: 0,
+					G: 255,
+					B: 128,
+					A: 0,
+				},
+			},
+		},
 	}
 )
 
+// GetPaletteFromFile loads the palette file, the base and cycle entries and
+// their keys default to the default palette's and the keys of the optional
+// entries default to those of the entry they fall back to, see Half and Stub
 func GetPaletteFromFile(file string) (*Palette, error) {
 	v := viper.New()
 	v.SetConfigFile(file)
@@ -202,10 +575,48 @@ func GetPaletteFromFile(file string) (*Palette, error) {
 		return nil, fmt.Errorf("failed to load palette: %w", err)
 	}
 
-	p := DefaultPalette
+	// the optional entries are left unset unless in the file, so the
+	// fallbacks apply, rather than taking the default palette's
+	base := *DefaultPalette.Base
+	cycle := *DefaultPalette.Cycle
+	p := &Palette{
+		Base:  &base,
+		Cycle: &cycle,
+	}
 	err = v.Unmarshal(&p, viper.DecodeHook(colorHookFunc()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to load palette: %w", err)
 	}
+	// an entry set to null is left out
+	if p.Base == nil {
+		p.Base = &base
+	}
+	if p.Cycle == nil {
+		p.Cycle = &cycle
+	}
+	p.Base.fill(DefaultPalette.Base)
+	p.Cycle.fill(DefaultPalette.Cycle)
+	p.Test.fill(p.Base)
+	p.TestCycle.fill(p.Cycle)
+	p.External.fill(p.Base)
+	p.Stdlib.fill(p.Stub(false))
 	return p, nil
 }
+
+// fill sets the colors missing from the half-palette to those of fallback
+func (half *HalfPalette) fill(fallback *HalfPalette) {
+	if half == nil {
+		return
+	}
+	fillColor(&half.PackageName, fallback.PackageName)
+	fillColor(&half.PackageBackground, fallback.PackageBackground)
+	fillColor(&half.FileName, fallback.FileName)
+	fillColor(&half.FileBackground, fallback.FileBackground)
+	fillColor(&half.ImportArrow, fallback.ImportArrow)
+}
+
+func fillColor(c *Color, fallback Color) {
+	if c.Color == nil {
+		*c = fallback
+	}
+}
@@ -1,11 +1,13 @@
 package color_test
 
 import (
+	"github.com/go-playground/colors"
 	"github.com/google/go-cmp/cmp"
 	"github.com/samlitowitz/godepvis/internal/color"
 	"github.com/samlitowitz/godepvis/internal/test"
 	"gopkg.in/yaml.v3"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 )
@@ -39,6 +41,177 @@ func TestGetPaletteFromFile(t *testing.T) {
 	}
 	compareHalfPalette(t, expectedPalette.Base, actualPalette.Base)
 	compareHalfPalette(t, expectedPalette.Cycle, actualPalette.Cycle)
+	compareHalfPalette(t, expectedPalette.Test, actualPalette.Test)
+	compareHalfPalette(t, expectedPalette.TestCycle, actualPalette.TestCycle)
+	compareHalfPalette(t, expectedPalette.External, actualPalette.External)
+	compareHalfPalette(t, expectedPalette.Stdlib, actualPalette.Stdlib)
+}
+
+func TestGetPaletteFromFile_OptionalEntries(t *testing.T) {
+	const (
+		base = `base:
+  packageName: "#000001"
+  packageBackground: "#000002"
+  fileName: "#000003"
+  fileBackground: "#000004"
+  importArrow: "#000005"
+`
+		cycle = `cycle:
+  packageName: "#100001"
+  packageBackground: "#100002"
+  fileName: "#100003"
+  fileBackground: "#100004"
+  importArrow: "#100005"
+`
+	)
+	baseHalf := halfPalette("#000001", "#000002", "#000003", "#000004", "#000005")
+	cycleHalf := halfPalette("#100001", "#100002", "#100003", "#100004", "#100005")
+
+	testCases := map[string]struct {
+		palette string
+		// expected half-palettes selected by Half(isTest, inImportCycle)
+		// and Stub(isStdlib)
+		expectedBase, expectedCycle, expectedTest, expectedTestCycle *color.HalfPalette
+	}{
+		"without test entries": {
+			palette:           base + cycle,
+			expectedBase:      baseHalf,
+			expectedCycle:     cycleHalf,
+			expectedTest:      baseHalf,
+			expectedTestCycle: cycleHalf,
+		},
+		"with partial test entries": {
+			palette: base + cycle + `test:
+  packageName: "#200001"
+testCycle:
+  importArrow: "#300005"
+`,
+			expectedBase:      baseHalf,
+			expectedCycle:     cycleHalf,
+			expectedTest:      halfPalette("#200001", "#000002", "#000003", "#000004", "#000005"),
+			expectedTestCycle: halfPalette("#100001", "#100002", "#100003", "#100004", "#300005"),
+		},
+		"without cycle entry": {
+			palette:           base,
+			expectedBase:      baseHalf,
+			expectedCycle:     color.DefaultPalette.Cycle,
+			expectedTest:      baseHalf,
+			expectedTestCycle: color.DefaultPalette.Cycle,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		palettePath := filepath.Join(t.TempDir(), "palette.yaml")
+		if err := os.WriteFile(palettePath, []byte(testCase.palette), 0o644); err != nil {
+			t.Fatal(desc, ": write palette: ", err)
+		}
+		actual, err := color.GetPaletteFromFile(palettePath)
+		if err != nil {
+			t.Fatal(desc, ": failed to load palette: ", err)
+		}
+		compareHalfPalette(t, testCase.expectedBase, actual.Half(false, false))
+		compareHalfPalette(t, testCase.expectedCycle, actual.Half(false, true))
+		compareHalfPalette(t, testCase.expectedTest, actual.Half(true, false))
+		compareHalfPalette(t, testCase.expectedTestCycle, actual.Half(true, true))
+	}
+
+	// loading a palette never changes the default palette
+	compareHalfPalette(t, color.InvertedDefaultPalette.Test, invertedTest(t))
+	if hex := color.DefaultPalette.Base.PackageName.Hex(); hex != "#000000" {
+		t.Errorf("expected the default palette's base package name to be #000000, got %s", hex)
+	}
+}
+
+func TestGetPaletteFromFile_OptionalStubEntries(t *testing.T) {
+	const base = `base:
+  packageName: "#000001"
+  packageBackground: "#000002"
+  fileName: "#000003"
+  fileBackground: "#000004"
+  importArrow: "#000005"
+`
+	baseHalf := halfPalette("#000001", "#000002", "#000003", "#000004", "#000005")
+	externalHalf := halfPalette("#400001", "#400002", "#400003", "#400004", "#400005")
+
+	testCases := map[string]struct {
+		palette                          string
+		expectedExternal, expectedStdlib *color.HalfPalette
+	}{
+		"without stub entries": {
+			palette:          base,
+			expectedExternal: baseHalf,
+			expectedStdlib:   baseHalf,
+		},
+		"with external entry only": {
+			palette: base + `external:
+  packageName: "#400001"
+  packageBackground: "#400002"
+  fileName: "#400003"
+  fileBackground: "#400004"
+  importArrow: "#400005"
+`,
+			expectedExternal: externalHalf,
+			expectedStdlib:   externalHalf,
+		},
+		"with partial stub entries": {
+			palette: base + `external:
+  packageName: "#400001"
+stdlib:
+  importArrow: "#500005"
+`,
+			expectedExternal: halfPalette("#400001", "#000002", "#000003", "#000004", "#000005"),
+			expectedStdlib:   halfPalette("#400001", "#000002", "#000003", "#000004", "#500005"),
+		},
+		"with stdlib entry only": {
+			palette: base + `stdlib:
+  packageName: "#500001"
+`,
+			expectedExternal: baseHalf,
+			expectedStdlib:   halfPalette("#500001", "#000002", "#000003", "#000004", "#000005"),
+		},
+	}
+
+	for desc, testCase := range testCases {
+		palettePath := filepath.Join(t.TempDir(), "palette.yaml")
+		if err := os.WriteFile(palettePath, []byte(testCase.palette), 0o644); err != nil {
+			t.Fatal(desc, ": write palette: ", err)
+		}
+		actual, err := color.GetPaletteFromFile(palettePath)
+		if err != nil {
+			t.Fatal(desc, ": failed to load palette: ", err)
+		}
+		compareHalfPalette(t, testCase.expectedExternal, actual.Stub(false))
+		compareHalfPalette(t, testCase.expectedStdlib, actual.Stub(true))
+	}
+}
+
+// invertedTest loads the inverted default palette's test entry alone
+func invertedTest(t *testing.T) *color.HalfPalette {
+	t.Helper()
+	palettePath := filepath.Join(t.TempDir(), "palette.yaml")
+	writePalette(t, palettePath, &color.Palette{Test: color.InvertedDefaultPalette.Test})
+	p, err := color.GetPaletteFromFile(palettePath)
+	if err != nil {
+		t.Fatal("failed to load palette: ", err)
+	}
+	return p.Test
+}
+
+func halfPalette(packageName, packageBackground, fileName, fileBackground, importArrow string) *color.HalfPalette {
+	parse := func(hex string) color.Color {
+		c, err := colors.ParseHEX(hex)
+		if err != nil {
+			panic(err)
+		}
+		return color.Color{Color: c}
+	}
+	return &color.HalfPalette{
+		PackageName:       parse(packageName),
+		PackageBackground: parse(packageBackground),
+		FileName:          parse(fileName),
+		FileBackground:    parse(fileBackground),
+		ImportArrow:       parse(importArrow),
+	}
 }
 
 func compareHalfPalette(t *testing.T, expected, actual *color.HalfPalette) {
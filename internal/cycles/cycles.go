@@ -0,0 +1,145 @@
+package cycles
+
+import (
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+// Hop is a single dependency within a cycle along with the declarations
+// referenced by it
+type Hop struct {
+	From string
+	To   string
+
+	Decls []*internal.Decl
+}
+
+// Cycle is an elementary import cycle as an ordered path of hops, the last
+// hop ends where the first hop starts
+type Cycle struct {
+	Resolution internal.Resolution
+	Hops       []Hop
+}
+
+// Nodes returns the names of the nodes in the cycle in path order
+func (c Cycle) Nodes() []string {
+	nodes := make([]string, 0, len(c.Hops))
+	for _, hop := range c.Hops {
+		nodes = append(nodes, hop.From)
+	}
+	return nodes
+}
+
+// String formats the cycle as a path, e.g.
+// "a/a.go -> b/b.go via b.Foo -> a/a.go via a.Bar"
+func (c Cycle) String() string {
+	if len(c.Hops) == 0 {
+		return ""
+	}
+	sb := &strings.Builder{}
+	sb.WriteString(c.Hops[0].From)
+	for _, hop := range c.Hops {
+		sb.WriteString(" -> ")
+		sb.WriteString(hop.To)
+		if len(hop.Decls) == 0 {
+			continue
+		}
+		names := make([]string, 0, len(hop.Decls))
+		for _, decl := range hop.Decls {
+			names = append(names, DeclName(decl))
+		}
+		sb.WriteString(" via ")
+		sb.WriteString(strings.Join(names, ", "))
+	}
+	return sb.String()
+}
+
+// Find returns every elementary import cycle at the given resolution
+func Find(pkgs []*internal.Package, resolution internal.Resolution) []Cycle {
+	found, _ := FindAtMost(pkgs, resolution, 0)
+	return found
+}
+
+// FindAtMost returns at most limit elementary import cycles at the given
+// resolution, all of them when limit is 0, and whether cycles were left out
+func FindAtMost(pkgs []*internal.Package, resolution internal.Resolution, limit int) ([]Cycle, bool) {
+	var g *graph.Graph
+	switch resolution {
+	case internal.FileResolution:
+		g = graph.ForFiles(pkgs)
+	case internal.PackageResolution:
+		g = graph.ForPackageReferences(pkgs)
+	case internal.ModuleResolution:
+		g = graph.ForModules(pkgs)
+	default:
+		return nil, false
+	}
+
+	paths, truncated := g.ElementaryCycles(limit)
+	var found []Cycle
+	for _, path := range paths {
+		cycle := Cycle{
+			Resolution: resolution,
+			Hops:       make([]Hop, 0, len(path)),
+		}
+		for i, from := range path {
+			to := path[(i+1)%len(path)]
+			e := g.Edge(from.ID, to.ID)
+			cycle.Hops = append(cycle.Hops, Hop{
+				From:  NodeName(from),
+				To:    NodeName(to),
+				Decls: e.Decls,
+			})
+		}
+		found = append(found, cycle)
+	}
+
+	slices.SortStableFunc(found, func(a, b Cycle) int {
+		if c := cmp.Compare(len(a.Hops), len(b.Hops)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.String(), b.String())
+	})
+	return found, truncated
+}
+
+// NodeName returns the module relative name of a file or package node and
+// the path of a module node
+func NodeName(node *graph.Node) string {
+	if node.File != nil {
+		return FileName(node.File)
+	}
+	if node.Package == nil && node.Module != nil {
+		return node.Module.Path
+	}
+	return node.Package.ModuleRelativePath()
+}
+
+// FileName returns the path of the file relative to its module
+func FileName(file *internal.File) string {
+	if file.IsStub || file.Package == nil {
+		pkgName := ""
+		if file.Package != nil {
+			pkgName = file.Package.ModuleRelativePath()
+		}
+		return pkgName + "/" + file.FileName
+	}
+	relPath, err := filepath.Rel(file.Package.ModuleDir, file.AbsPath)
+	if err != nil {
+		return file.AbsPath
+	}
+	return filepath.ToSlash(relPath)
+}
+
+// DeclName returns the declaration qualified by its package name, e.g. "b.Foo"
+func DeclName(decl *internal.Decl) string {
+	if decl.File == nil || decl.File.Package == nil {
+		return decl.QualifiedName()
+	}
+	return decl.File.Package.Name + "." + decl.QualifiedName()
+}
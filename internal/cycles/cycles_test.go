@@ -0,0 +1,115 @@
+package cycles_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestFindAtMost(t *testing.T) {
+	testCases := map[string]struct {
+		test.GoldenCase
+		limit int
+	}{
+		"no cycles": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "no-circular-dependencies",
+				Resolution: internal.FileResolution,
+				Golden:     "no-circular-dependencies.file.txt",
+			},
+		},
+		"direct at file resolution": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "direct-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "direct-circular-dependency.file.txt",
+			},
+		},
+		"direct at package resolution": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "direct-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "direct-circular-dependency.package.txt",
+			},
+		},
+		"transitive at file resolution": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "transitive-circular-dependency.file.txt",
+			},
+		},
+		"interlinked at package resolution": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "multiple-interlinked-direct-circular-dependencies",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies.package.txt",
+			},
+		},
+		"interlinked truncated at the limit": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "multiple-interlinked-direct-circular-dependencies",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies.package.limit-1.txt",
+			},
+			limit: 1,
+		},
+		"interlinked within the limit": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "multiple-interlinked-direct-circular-dependencies",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies.package.txt",
+			},
+			limit: 2,
+		},
+		"declaration resolution is not supported": {
+			GoldenCase: test.GoldenCase{
+				Dir:        "direct-circular-dependency",
+				Resolution: internal.DeclResolution,
+				Golden:     "direct-circular-dependency.decl.txt",
+			},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		test.MarshalGolden(
+			t,
+			map[string]test.GoldenCase{desc: testCase.GoldenCase},
+			func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+				found, truncated := cycles.FindAtMost(pkgs, resolution, testCase.limit)
+				return formatCycles(found, truncated), nil
+			},
+		)
+	}
+}
+
+func TestFind(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"interlinked at package resolution": {
+				Dir:        "multiple-interlinked-direct-circular-dependencies",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies.package.txt",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return formatCycles(cycles.Find(pkgs, resolution), false), nil
+		},
+	)
+}
+
+// formatCycles writes a cycle per line, like the cycles command, followed by
+// whether cycles were left out
+func formatCycles(found []cycles.Cycle, truncated bool) []byte {
+	buf := &bytes.Buffer{}
+	for _, cycle := range found {
+		fmt.Fprintf(buf, "%s: %s\n", cycle.Resolution, cycle.String())
+	}
+	fmt.Fprintf(buf, "truncated: %t\n", truncated)
+	return buf.Bytes()
+}
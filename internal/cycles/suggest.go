@@ -0,0 +1,182 @@
+package cycles
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+// Suggestion is an edge which, along with every other suggested edge, should
+// be removed to break all import cycles
+type Suggestion struct {
+	Resolution internal.Resolution
+	Edge       *graph.Edge
+
+	From string
+	To   string
+}
+
+// Weight is the number of referenced declarations which need to be moved or
+// removed to cut the edge
+func (s Suggestion) Weight() int {
+	return s.Edge.Weight()
+}
+
+// Actions returns the individual changes which cut the edge, e.g.
+// "move decl b.Foo out of file b/b.go" or "drop import b from file a/a.go"
+func (s Suggestion) Actions() []string {
+	var actions []string
+	switch s.Resolution {
+	case internal.FileResolution:
+		dropImports := false
+		for _, decl := range s.Edge.Decls {
+			// blank imports reference the whole package, nothing can be moved
+			if decl.IsBlank() {
+				dropImports = true
+				continue
+			}
+			actions = append(actions, fmt.Sprintf(
+				"move decl %s out of file %s",
+				DeclName(decl),
+				s.To,
+			))
+		}
+		if dropImports {
+			actions = append(actions, s.dropImportActions()...)
+		}
+	case internal.PackageResolution, internal.ModuleResolution:
+		actions = s.dropImportActions()
+	}
+	return slices.Compact(actions)
+}
+
+func (s Suggestion) dropImportActions() []string {
+	var actions []string
+	for _, pkg := range s.fromPackages() {
+		for _, file := range sortedFiles(pkg) {
+			for _, imp := range s.Edge.Imports {
+				if !hasImport(file, imp) {
+					continue
+				}
+				actions = append(actions, fmt.Sprintf(
+					"drop import %s from file %s",
+					imp.Path,
+					FileName(file),
+				))
+			}
+		}
+	}
+	return actions
+}
+
+// fromPackages returns the packages the edge starts from, every package of
+// the module at the module resolution
+func (s Suggestion) fromPackages() []*internal.Package {
+	if s.Edge.From.Package != nil {
+		return []*internal.Package{s.Edge.From.Package}
+	}
+	if s.Edge.From.Module == nil {
+		return nil
+	}
+	pkgs := make([]*internal.Package, 0, len(s.Edge.From.Module.Packages))
+	for _, uid := range slices.Sorted(maps.Keys(s.Edge.From.Module.Packages)) {
+		pkgs = append(pkgs, s.Edge.From.Module.Packages[uid])
+	}
+	return pkgs
+}
+
+func (s Suggestion) String() string {
+	return fmt.Sprintf(
+		"%s -> %s (weight %d): %s",
+		s.From,
+		s.To,
+		s.Weight(),
+		strings.Join(s.Actions(), "; "),
+	)
+}
+
+// Suggest returns an approximately minimal set of edges to cut to break
+// every import cycle at the given resolution, cheapest first
+func Suggest(pkgs []*internal.Package, resolution internal.Resolution) []Suggestion {
+	var g *graph.Graph
+	switch resolution {
+	case internal.FileResolution:
+		g = graph.ForFiles(pkgs)
+	case internal.PackageResolution:
+		g = graph.ForPackageReferences(pkgs)
+	case internal.ModuleResolution:
+		g = graph.ForModules(pkgs)
+	default:
+		return nil
+	}
+
+	var suggestions []Suggestion
+	for _, e := range g.FeedbackArcSet() {
+		suggestions = append(suggestions, Suggestion{
+			Resolution: resolution,
+			Edge:       e,
+			From:       fromName(e),
+			To:         toName(e),
+		})
+	}
+	return suggestions
+}
+
+// fromName names the node the edge starts from. The files of a package
+// imported with a blank identifier are stood in for by a stub file carrying
+// their imports, the files of the package with the edge's imports are named
+// instead.
+func fromName(e *graph.Edge) string {
+	if e.From.File == nil || !e.From.File.IsBlankImport {
+		return NodeName(e.From)
+	}
+	var names []string
+	for _, file := range sortedFiles(e.From.Package) {
+		for _, imp := range e.Imports {
+			if hasImport(file, imp) {
+				names = append(names, FileName(file))
+				break
+			}
+		}
+	}
+	if len(names) == 0 {
+		return NodeName(e.From)
+	}
+	return strings.Join(names, ", ")
+}
+
+// toName names the node the edge ends at, the package imported with a blank
+// identifier rather than its stub file
+func toName(e *graph.Edge) string {
+	if e.To.File == nil || !e.To.File.IsBlankImport {
+		return NodeName(e.To)
+	}
+	return e.To.Package.ModuleRelativePath()
+}
+
+func sortedFiles(pkg *internal.Package) []*internal.File {
+	files := make([]*internal.File, 0, len(pkg.Files))
+	for _, file := range pkg.Files {
+		if file.IsStub {
+			continue
+		}
+		files = append(files, file)
+	}
+	slices.SortFunc(files, func(a, b *internal.File) int {
+		return strings.Compare(a.UID(), b.UID())
+	})
+	return files
+}
+
+func hasImport(file *internal.File, imp *internal.Import) bool {
+	for _, fileImp := range file.Imports {
+		if fileImp == imp {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package cycles_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestSuggest(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"no cycles": {
+				Dir:        "no-circular-dependencies",
+				Resolution: internal.FileResolution,
+				Golden:     "no-circular-dependencies.file.suggest.txt",
+			},
+			"direct at file resolution": {
+				Dir:        "direct-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "direct-circular-dependency.file.suggest.txt",
+			},
+			"direct at package resolution": {
+				Dir:        "direct-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "direct-circular-dependency.package.suggest.txt",
+			},
+			"interlinked at package resolution": {
+				Dir:        "multiple-interlinked-direct-circular-dependencies",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies.package.suggest.txt",
+			},
+			"blank import at file resolution": {
+				Dir:        "direct-circular-dependency-with-blank-identifier",
+				Resolution: internal.FileResolution,
+				Golden:     "direct-circular-dependency-with-blank-identifier.file.suggest.txt",
+			},
+			"blank imports at file resolution": {
+				Dir:        "direct-circular-dependency-blank-identifiers",
+				Resolution: internal.FileResolution,
+				Golden:     "direct-circular-dependency-blank-identifiers.file.suggest.txt",
+			},
+			"interlinked blank imports at file resolution": {
+				Dir:        "multiple-interlinked-direct-circular-dependencies-with-blank-identifier",
+				Resolution: internal.FileResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies-with-blank-identifier.file.suggest.txt",
+			},
+			"interlinked blank imports at package resolution": {
+				Dir:        "multiple-interlinked-direct-circular-dependencies-with-blank-identifier",
+				Resolution: internal.PackageResolution,
+				Golden:     "multiple-interlinked-direct-circular-dependencies-with-blank-identifier.package.suggest.txt",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			buf := &bytes.Buffer{}
+			for _, suggestion := range cycles.Suggest(pkgs, resolution) {
+				fmt.Fprintln(buf, suggestion.String())
+			}
+			return buf.Bytes(), nil
+		},
+	)
+}
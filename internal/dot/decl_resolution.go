@@ -0,0 +1,118 @@
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+// writeNodeDefsForDeclResolution clusters the declarations by file and the
+// files by package
+func writeNodeDefsForDeclResolution(buf *bytes.Buffer, palette *color.Palette, g *graph.Graph) {
+	var err error
+	pkgClusterDefHeader := `
+	subgraph "cluster_%s" {
+		label="%s";
+		style="filled";
+		fontcolor="%s";
+		fillcolor="%s";
+`
+	fileClusterDefHeader := `
+		subgraph "cluster_%s" {
+			label="%s";
+			style="filled";
+			fontcolor="%s";
+			fillcolor="%s";
+`
+	fileClusterDefFooter := `
+		};
+`
+	pkgClusterDefFooter := `
+	};
+`
+	nodeDef := `
+			"%s" [label="%s", style="filled", fontcolor="%s", fillcolor="%s"];`
+
+	var pkgs []*internal.Package
+	filesByPkg := make(map[*internal.Package][]*internal.File)
+	declsByFile := make(map[*internal.File][]*graph.Node)
+	for _, n := range g.Nodes() {
+		if n.Decl == nil {
+			continue
+		}
+		if _, ok := filesByPkg[n.Package]; !ok {
+			pkgs = append(pkgs, n.Package)
+		}
+		if _, ok := declsByFile[n.File]; !ok {
+			filesByPkg[n.Package] = append(filesByPkg[n.Package], n.File)
+		}
+		declsByFile[n.File] = append(declsByFile[n.File], n)
+	}
+
+	for _, pkg := range pkgs {
+		pkgHalf := palette.Half(pkg.IsTest, pkg.InImportCycle)
+		_, err = fmt.Fprintf(
+			buf,
+			pkgClusterDefHeader,
+			pkgNodeName(pkg),
+			pkg.ModuleRelativePath(),
+			pkgHalf.PackageName.Hex(),
+			pkgHalf.PackageBackground.Hex(),
+		)
+		if err != nil {
+			panic(err)
+		}
+		for _, file := range filesByPkg[pkg] {
+			fileHalf := palette.Half(file.IsTest, file.InImportCycle)
+			_, err = fmt.Fprintf(
+				buf,
+				fileClusterDefHeader,
+				fileNodeName(file),
+				file.FileName,
+				fileHalf.FileName.Hex(),
+				fileHalf.FileBackground.Hex(),
+			)
+			if err != nil {
+				panic(err)
+			}
+			for _, n := range declsByFile[file] {
+				_, err = fmt.Fprintf(
+					buf,
+					nodeDef,
+					nodeName(n),
+					n.Label(),
+					fileHalf.FileName.Hex(),
+					fileHalf.FileBackground.Hex(),
+				)
+				if err != nil {
+					panic(err)
+				}
+			}
+			buf.WriteString(fileClusterDefFooter)
+		}
+		buf.WriteString(pkgClusterDefFooter)
+	}
+}
+
+func writeRelationshipsForDeclResolution(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, g *graph.Graph) {
+	var err error
+	edgeDef := `
+	"%s" -> "%s" [color="%s"%s];`
+
+	for _, e := range g.Edges() {
+		arrowColor := palette.Half(e.From.IsTest(), e.InImportCycle()).ImportArrow
+		_, err = fmt.Fprintf(
+			buf,
+			edgeDef,
+			nodeName(e.From),
+			nodeName(e.To),
+			arrowColor.Hex(),
+			edgeStyle(highlightedEdges, e.From.ID, e.To.ID),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
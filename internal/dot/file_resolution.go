@@ -5,11 +5,12 @@
 	"fmt"
 	"github.com/samlitowitz/godepvis/internal"
 	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
 )
 
 const (
 	fileResolutionEdgeDef = `
-		"%s" -> "%s" [color="%s"];`
+		"%s" -> "%s" [color="%s"%s];`
 )
 
 func writeNodeDefsForFileResolution(buf *bytes.Buffer, palette *color.Palette, pkgs []*internal.Package) {
@@ -34,12 +35,9 @@ func writeNodeDefsForFileResolution(buf *bytes.Buffer, palette *color.Palette, p
 		if len(pkg.Files) == 0 {
 			continue
 		}
-		pkgText := palette.Base.PackageName
-		pkgBackground := palette.Base.PackageBackground
-		if pkg.InImportCycle {
-			pkgText = palette.Cycle.PackageName
-			pkgBackground = palette.Cycle.PackageBackground
-		}
+		pkgHalf := palette.Half(pkg.IsTest, pkg.InImportCycle)
+		pkgText := pkgHalf.PackageName
+		pkgBackground := pkgHalf.PackageBackground
 
 		_, err = fmt.Fprintf(
 			buf,
@@ -59,12 +57,9 @@ func writeNodeDefsForFileResolution(buf *bytes.Buffer, palette *color.Palette, p
 			if len(file.Decls) == 0 {
 				continue
 			}
-			fileText := palette.Base.FileName
-			fileBackground := palette.Base.FileBackground
-			if file.InImportCycle {
-				fileText = palette.Cycle.FileName
-				fileBackground = palette.Cycle.FileBackground
-			}
+			fileHalf := palette.Half(file.IsTest, file.InImportCycle)
+			fileText := fileHalf.FileName
+			fileBackground := fileHalf.FileBackground
 			_, err = fmt.Fprintf(
 				buf,
 				nodeDef,
@@ -81,7 +76,7 @@ func writeNodeDefsForFileResolution(buf *bytes.Buffer, palette *color.Palette, p
 	}
 }
 
-func writeRelationshipsForFileResolution(showMultipleReferences bool, buf *bytes.Buffer, palette *color.Palette, pkgs []*internal.Package) {
+func writeRelationshipsForFileResolution(showMultipleReferences bool, buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, pkgs []*internal.Package) {
 	writeEdgesFn := showOneReferencePerFileImportForFileResolution
 	if showMultipleReferences {
 		writeEdgesFn = showMultipleReferencesPerFileImportForFileResolution
@@ -95,12 +90,12 @@ func writeRelationshipsForFileResolution(showMultipleReferences bool, buf *bytes
 			if file.IsStub {
 				continue
 			}
-			writeEdgesFn(buf, palette, file)
+			writeEdgesFn(buf, palette, highlightedEdges, file)
 		}
 	}
 }
 
-func showMultipleReferencesPerFileImportForFileResolution(buf *bytes.Buffer, palette *color.Palette, file *internal.File) {
+func showMultipleReferencesPerFileImportForFileResolution(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, file *internal.File) {
 	var err error
 	for _, imp := range file.Imports {
 		if imp.Package == nil {
@@ -110,16 +105,15 @@ func showMultipleReferencesPerFileImportForFileResolution(buf *bytes.Buffer, pal
 			continue
 		}
 		for _, refTyp := range imp.ReferencedTypes {
-			arrowColor := palette.Base.ImportArrow
-			if _, ok := imp.ReferencedFilesInCycle[refTyp.File.UID()]; ok {
-				arrowColor = palette.Cycle.ImportArrow
-			}
+			_, inImportCycle := imp.ReferencedFilesInCycle[refTyp.File.UID()]
+			arrowColor := palette.Half(file.IsTest, inImportCycle).ImportArrow
 			_, err = fmt.Fprintf(
 				buf,
 				fileResolutionEdgeDef,
 				fileNodeName(file),
 				fileNodeName(refTyp.File),
 				arrowColor.Hex(),
+				edgeStyle(highlightedEdges, file.UID(), refTyp.File.UID()),
 			)
 			if err != nil {
 				panic(err)
@@ -128,7 +122,7 @@ func showMultipleReferencesPerFileImportForFileResolution(buf *bytes.Buffer, pal
 	}
 }
 
-func showOneReferencePerFileImportForFileResolution(buf *bytes.Buffer, palette *color.Palette, file *internal.File) {
+func showOneReferencePerFileImportForFileResolution(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, file *internal.File) {
 	var err error
 	for _, imp := range file.Imports {
 		if imp.Package == nil {
@@ -139,16 +133,15 @@ func showOneReferencePerFileImportForFileResolution(buf *bytes.Buffer, palette *
 		}
 
 		for _, refTyp := range imp.ReferencedTypes {
-			arrowColor := palette.Base.ImportArrow
-			if _, ok := imp.ReferencedFilesInCycle[refTyp.File.UID()]; ok {
-				arrowColor = palette.Cycle.ImportArrow
-			}
+			_, inImportCycle := imp.ReferencedFilesInCycle[refTyp.File.UID()]
+			arrowColor := palette.Half(file.IsTest, inImportCycle).ImportArrow
 			_, err = fmt.Fprintf(
 				buf,
 				fileResolutionEdgeDef,
 				fileNodeName(file),
 				fileNodeName(refTyp.File),
 				arrowColor.Hex(),
+				edgeStyle(highlightedEdges, file.UID(), refTyp.File.UID()),
 			)
 			if err != nil {
 				panic(err)
@@ -6,10 +6,15 @@
 	"fmt"
 	"github.com/samlitowitz/godepvis/internal"
 	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
 	"slices"
 	"strings"
 )
 
+const (
+	highlightedEdgeStyle = `, style="dashed", penwidth="2"`
+)
+
 func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
 	options := options{
 		palette:    *color.DefaultPalette,
@@ -25,12 +30,27 @@ func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byt
 
 	writeHeader(buf, modulePath)
 	switch options.resolution {
+	case internal.DeclResolution:
+		g := graph.Visible(pkgs, internal.DeclResolution)
+		writeNodeDefsForDeclResolution(buf, &options.palette, g)
+		writeRelationshipsForDeclResolution(buf, &options.palette, options.highlightedEdges, g)
+		writeStubs(buf, &options, pkgs)
 	case internal.FileResolution:
-		writeNodeDefsForFileResolution(buf, &options.palette, pkgs)
-		writeRelationshipsForFileResolution(options.showMultipleReferences, buf, &options.palette, pkgs)
+		writeNodeDefsByModule(buf, pkgs, func(buf *bytes.Buffer, pkgs []*internal.Package) {
+			writeNodeDefsForFileResolution(buf, &options.palette, pkgs)
+		})
+		writeRelationshipsForFileResolution(options.showMultipleReferences, buf, &options.palette, options.highlightedEdges, pkgs)
+		writeStubs(buf, &options, pkgs)
 	case internal.PackageResolution:
-		writeNodeDefsForPackageResolution(buf, &options.palette, pkgs)
-		writeRelationshipsForPackageResolution(buf, &options.palette, pkgs)
+		writeNodeDefsByModule(buf, pkgs, func(buf *bytes.Buffer, pkgs []*internal.Package) {
+			writeNodeDefsForPackageResolution(buf, &options.palette, pkgs)
+		})
+		writeRelationshipsForPackageResolution(buf, &options.palette, options.highlightedEdges, pkgs)
+		writeStubs(buf, &options, pkgs)
+	case internal.ModuleResolution:
+		g := graph.Visible(pkgs, internal.ModuleResolution, graph.WithStdlib(options.stdlib))
+		writeNodeDefsForModuleResolution(buf, &options.palette, g)
+		writeRelationshipsForModuleResolution(buf, &options.palette, options.highlightedEdges, g)
 	}
 	writeFooter(buf)
 
@@ -38,7 +58,10 @@ func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byt
 }
 
 func pkgCmpFn(a, b *internal.Package) int {
-	return cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name))
+	return cmp.Or(
+		cmp.Compare(strings.ToLower(a.Name), strings.ToLower(b.Name)),
+		cmp.Compare(a.UID(), b.UID()),
+	)
 }
 
 func writeHeader(buf *bytes.Buffer, modulePath string) {
@@ -57,6 +80,13 @@ func writeHeader(buf *bytes.Buffer, modulePath string) {
 	}
 }
 
+func edgeStyle(highlightedEdges map[graph.EdgeID]bool, from, to string) string {
+	if highlightedEdges[graph.EdgeID{From: from, To: to}] {
+		return highlightedEdgeStyle
+	}
+	return ""
+}
+
 func writeFooter(buf *bytes.Buffer) {
 	buf.WriteString(`
 }
@@ -64,23 +94,33 @@ func writeFooter(buf *bytes.Buffer) {
 	)
 }
 
+// pkgNodeName, like the other node names, is the kind of node followed by the
+// UID of what it draws, so same named packages, e.g. several main packages,
+// and their files are drawn as separate nodes. Labels are left short.
 func pkgNodeName(pkg *internal.Package) string {
-	return fmt.Sprintf(
-		"pkg_%s",
-		pkg.Name,
-	)
+	return escapeID("pkg:" + pkg.UID())
+}
+
+func declNodeName(file *internal.File, decl *internal.Decl) string {
+	return escapeID("decl:" + file.UID() + "#" + decl.UID())
+}
+
+func stubNodeName(pkg *internal.Package) string {
+	return escapeID("stub:" + pkg.ImportPath())
+}
+
+func modNodeName(mod *internal.Module) string {
+	return escapeID("mod:" + mod.Path)
 }
 
 func fileNodeName(file *internal.File) string {
-	if file.Package == nil {
-		return fmt.Sprintf(
-			"file_%s",
-			file.FileName,
-		)
-	}
-	return fmt.Sprintf(
-		"pkg_%s_file_%s",
-		file.Package.Name,
-		strings.TrimSuffix(file.FileName, ".go"),
-	)
+	return escapeID("file:" + file.UID())
+}
+
+// escapeID escapes the ID for use within a double-quoted DOT string, file
+// paths may contain backslashes, e.g. on Windows, or double quotes
+func escapeID(id string) string {
+	return idEscaper.Replace(id)
 }
+
+var idEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`)
@@ -3,12 +3,18 @@
 import (
 	"github.com/samlitowitz/godepvis/internal"
 	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
 )
 
 type options struct {
 	resolution             internal.Resolution
 	palette                color.Palette
 	showMultipleReferences bool
+	highlightedEdges       map[graph.EdgeID]bool
+	stdlib                 bool
+	stubs                  bool
+	collapseStubs          bool
+	stubPrefixes           []string
 }
 
 type Option interface {
@@ -44,3 +50,66 @@ func (opt showMultipleReferencesOption) apply(opts *options) {
 func WithShowMultipleReferences(showMultipleReferences bool) Option {
 	return showMultipleReferencesOption(showMultipleReferences)
 }
+
+type highlightedEdgesOption []graph.EdgeID
+
+func (opt highlightedEdgesOption) apply(opts *options) {
+	opts.highlightedEdges = make(map[graph.EdgeID]bool, len(opt))
+	for _, id := range opt {
+		opts.highlightedEdges[id] = true
+	}
+}
+
+// WithHighlightedEdges draws the edges, identified by package or file UIDs
+// depending on the resolution, dashed and bold, e.g. to show suggested cuts
+func WithHighlightedEdges(ids []graph.EdgeID) Option {
+	return highlightedEdgesOption(ids)
+}
+
+type stdlibOption bool
+
+func (opt stdlibOption) apply(opts *options) {
+	opts.stdlib = bool(opt)
+}
+
+// WithStdlib shows the standard library module at the module resolution
+func WithStdlib(stdlib bool) Option {
+	return stdlibOption(stdlib)
+}
+
+type stubsOption bool
+
+func (opt stubsOption) apply(opts *options) {
+	opts.stubs = bool(opt)
+}
+
+// WithStubs draws packages of other modules and of the standard library as
+// nodes at the file and package resolutions
+func WithStubs(stubs bool) Option {
+	return stubsOption(stubs)
+}
+
+type collapsedStubsOption bool
+
+func (opt collapsedStubsOption) apply(opts *options) {
+	opts.collapseStubs = bool(opt)
+}
+
+// WithCollapsedStubs draws a node per module rather than per package of
+// other modules and of the standard library, see WithStubs
+func WithCollapsedStubs(collapse bool) Option {
+	return collapsedStubsOption(collapse)
+}
+
+type stubPrefixesOption []string
+
+func (opt stubPrefixesOption) apply(opts *options) {
+	opts.stubPrefixes = []string(opt)
+}
+
+// WithStubPrefixes only draws the packages of other modules and of the
+// standard library whose import path starts with one of the prefixes, see
+// WithStubs
+func WithStubPrefixes(prefixes []string) Option {
+	return stubPrefixesOption(prefixes)
+}
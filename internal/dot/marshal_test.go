@@ -0,0 +1,94 @@
+package dot_test
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/dot"
+	"github.com/samlitowitz/godepvis/internal/modfile"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-same-named-packages at the file resolution": {
+				Dir:        "with-same-named-packages",
+				Resolution: internal.FileResolution,
+				Golden:     "with-same-named-packages.file.gv",
+			},
+			"with-same-named-packages at the package resolution": {
+				Dir:        "with-same-named-packages",
+				Resolution: internal.PackageResolution,
+				Golden:     "with-same-named-packages.package.gv",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return dot.Marshal(modulePath, pkgs, dot.WithResolution(resolution))
+		},
+	)
+}
+
+// TestMarshal_ImportCycles compares the import cycles marked in the output to
+// the golden files first written by the recursive marking of import cycles
+// the strongly connected components replaced. Packages and files are written
+// in map order, the lines are compared regardless of their order.
+func TestMarshal_ImportCycles(t *testing.T) {
+	var testCases []string
+	for _, dir := range []string{
+		"direct-circular-dependency",
+		"direct-circular-dependency-with-blank-identifier",
+		"direct-circular-dependency-with-dot-import",
+		"multiple-interlinked-direct-circular-dependencies",
+		"no-circular-dependencies",
+		"transitive-circular-dependency",
+	} {
+		for _, resolution := range []internal.Resolution{internal.FileResolution, internal.PackageResolution} {
+			testCases = append(testCases, dir+"."+string(resolution))
+		}
+	}
+
+	for _, desc := range testCases {
+		dir, resolution, _ := strings.Cut(desc, ".")
+		moduleDir := t.TempDir()
+		err := os.CopyFS(moduleDir, os.DirFS(filepath.Join("..", "primitives", "testdata", "build-for-module", dir)))
+		if err != nil {
+			t.Fatal(desc, ": copy test data: ", err)
+		}
+		modulePath, err := modfile.GetModulePath(filepath.Join(moduleDir, "go.mod"))
+		if err != nil {
+			t.Fatal(desc, ": failed to get module path: ", err)
+		}
+		pkgs, err := primitives.BuildForModule(modulePath, moduleDir)
+		if err != nil {
+			t.Fatal(desc, ": BuildForModule: ", err)
+		}
+		output, err := dot.Marshal(modulePath, pkgs, dot.WithResolution(internal.Resolution(resolution)))
+		if err != nil {
+			t.Fatal(desc, ": Marshal: ", err)
+		}
+		expected, err := os.ReadFile(filepath.Join("testdata", desc+".gv"))
+		if err != nil {
+			t.Fatal(desc, ": read golden file: ", err)
+		}
+
+		// the module is copied to a different directory every run
+		actual := strings.ReplaceAll(string(output), moduleDir, "/module")
+		if diff := cmp.Diff(sortedLines(string(expected)), sortedLines(actual)); diff != "" {
+			t.Error(desc, test.Mismatch(": expected output: ", diff))
+		}
+	}
+}
+
+func sortedLines(s string) []string {
+	lines := strings.Split(s, "\n")
+	slices.Sort(lines)
+	return lines
+}
@@ -0,0 +1,113 @@
+package dot
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"slices"
+)
+
+func writeNodeDefsForModuleResolution(buf *bytes.Buffer, palette *color.Palette, g *graph.Graph) {
+	var err error
+	nodeDef := `
+	"%s" [label="%s", style="%s", fontcolor="%s", fillcolor="%s"];`
+
+	for _, n := range g.Nodes() {
+		modHalf := palette.Half(false, n.InImportCycle())
+		style := "filled"
+		if n.Module.IsStub {
+			modHalf = palette.Stub(n.Module.IsStdlib)
+			style = "filled,dashed"
+		}
+		_, err = fmt.Fprintf(
+			buf,
+			nodeDef,
+			modNodeName(n.Module),
+			n.Label(),
+			style,
+			modHalf.PackageName.Hex(),
+			modHalf.PackageBackground.Hex(),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// writeRelationshipsForModuleResolution labels the edges with the number of
+// imports between the modules
+func writeRelationshipsForModuleResolution(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, g *graph.Graph) {
+	var err error
+	edgeDef := `
+	"%s" -> "%s" [label="%d", color="%s"%s];`
+
+	for _, e := range g.Edges() {
+		arrowColor := palette.Half(false, e.InImportCycle()).ImportArrow
+		_, err = fmt.Fprintf(
+			buf,
+			edgeDef,
+			modNodeName(e.From.Module),
+			modNodeName(e.To.Module),
+			e.Weight(),
+			arrowColor.Hex(),
+			edgeStyle(highlightedEdges, e.From.ID, e.To.ID),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// writeNodeDefsByModule clusters the node definitions of the packages by
+// module when the packages of more than one module, e.g. of a workspace, are
+// rendered
+func writeNodeDefsByModule(
+	buf *bytes.Buffer,
+	pkgs []*internal.Package,
+	writeNodeDefs func(*bytes.Buffer, []*internal.Package),
+) {
+	pkgsByModule := make(map[*internal.Module][]*internal.Package)
+	var mods []*internal.Module
+	for _, pkg := range pkgs {
+		if pkg.IsStub || pkg.Module == nil {
+			continue
+		}
+		if _, ok := pkgsByModule[pkg.Module]; !ok {
+			mods = append(mods, pkg.Module)
+		}
+		pkgsByModule[pkg.Module] = append(pkgsByModule[pkg.Module], pkg)
+	}
+	if len(mods) < 2 {
+		writeNodeDefs(buf, pkgs)
+		return
+	}
+
+	var err error
+	clusterDefHeader := `
+	subgraph "cluster_%s" {
+		label="%s";
+		style="dashed";
+`
+	clusterDefFooter := `
+	};
+`
+	slices.SortFunc(mods, func(a, b *internal.Module) int {
+		return cmp.Compare(a.Path, b.Path)
+	})
+	for _, mod := range mods {
+		_, err = fmt.Fprintf(
+			buf,
+			clusterDefHeader,
+			modNodeName(mod),
+			mod.Path,
+		)
+		if err != nil {
+			panic(err)
+		}
+		writeNodeDefs(buf, pkgsByModule[mod])
+		buf.WriteString(clusterDefFooter)
+	}
+}
@@ -5,6 +5,7 @@
 	"fmt"
 	"github.com/samlitowitz/godepvis/internal"
 	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
 )
 
 func writeNodeDefsForPackageResolution(buf *bytes.Buffer, palette *color.Palette, pkgs []*internal.Package) {
@@ -19,12 +20,9 @@ func writeNodeDefsForPackageResolution(buf *bytes.Buffer, palette *color.Palette
 		if len(pkg.Files) == 0 {
 			continue
 		}
-		pkgText := palette.Base.PackageName
-		pkgBackground := palette.Base.PackageBackground
-		if pkg.InImportCycle {
-			pkgText = palette.Cycle.PackageName
-			pkgBackground = palette.Cycle.PackageBackground
-		}
+		pkgHalf := palette.Half(pkg.IsTest, pkg.InImportCycle)
+		pkgText := pkgHalf.PackageName
+		pkgBackground := pkgHalf.PackageBackground
 
 		_, err = fmt.Fprintf(
 			buf,
@@ -40,10 +38,10 @@ func writeNodeDefsForPackageResolution(buf *bytes.Buffer, palette *color.Palette
 	}
 }
 
-func writeRelationshipsForPackageResolution(buf *bytes.Buffer, palette *color.Palette, pkgs []*internal.Package) {
+func writeRelationshipsForPackageResolution(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, pkgs []*internal.Package) {
 	var err error
 	edgeDef := `
-	"%s" -> "%s" [color="%s"];`
+	"%s" -> "%s" [color="%s"%s];`
 
 	pkgRelationships := make(map[string]map[string]bool)
 	for _, pkg := range pkgs {
@@ -73,16 +71,14 @@ func writeRelationshipsForPackageResolution(buf *bytes.Buffer, palette *color.Pa
 				}
 				pkgRelationships[pkgName][impPkgName] = true
 
-				arrowColor := palette.Base.ImportArrow
-				if imp.InImportCycle {
-					arrowColor = palette.Cycle.ImportArrow
-				}
+				arrowColor := palette.Half(pkg.IsTest, imp.InImportCycle).ImportArrow
 				_, err = fmt.Fprintf(
 					buf,
 					edgeDef,
 					pkgName,
 					impPkgName,
 					arrowColor.Hex(),
+					edgeStyle(highlightedEdges, pkg.UID(), imp.Package.UID()),
 				)
 				if err != nil {
 					panic(err)
@@ -0,0 +1,89 @@
+package dot
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+// writeStubs draws the stub packages, or their modules if collapsed, and the
+// imports of them when stubs are shown, see WithStubs
+func writeStubs(buf *bytes.Buffer, options *options, pkgs []*internal.Package) {
+	if !options.stubs {
+		return
+	}
+	g := graph.Visible(
+		pkgs,
+		options.resolution,
+		graph.WithStubs(true),
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	)
+	writeNodeDefsForStubs(buf, &options.palette, g)
+	writeRelationshipsForStubs(buf, &options.palette, options.highlightedEdges, g)
+}
+
+func writeNodeDefsForStubs(buf *bytes.Buffer, palette *color.Palette, g *graph.Graph) {
+	var err error
+	nodeDef := `
+	"%s" [label="%s", style="filled,dashed", fontcolor="%s", fillcolor="%s"];`
+
+	for _, n := range g.Nodes() {
+		if !n.IsStub() {
+			continue
+		}
+		stubHalf := palette.Stub(n.IsStdlib())
+		_, err = fmt.Fprintf(
+			buf,
+			nodeDef,
+			nodeName(n),
+			n.Label(),
+			stubHalf.PackageName.Hex(),
+			stubHalf.PackageBackground.Hex(),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func writeRelationshipsForStubs(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, g *graph.Graph) {
+	var err error
+	edgeDef := `
+	"%s" -> "%s" [color="%s"%s];`
+
+	for _, e := range g.Edges() {
+		if !e.To.IsStub() {
+			continue
+		}
+		arrowColor := palette.Stub(e.To.IsStdlib()).ImportArrow
+		_, err = fmt.Fprintf(
+			buf,
+			edgeDef,
+			nodeName(e.From),
+			nodeName(e.To),
+			arrowColor.Hex(),
+			edgeStyle(highlightedEdges, e.From.ID, e.To.ID),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// nodeName is the name of the node drawn for the graph node
+func nodeName(n *graph.Node) string {
+	switch {
+	case n.Decl != nil:
+		return declNodeName(n.File, n.Decl)
+	case n.File != nil:
+		return fileNodeName(n.File)
+	case n.Package != nil && n.Package.IsStub:
+		return stubNodeName(n.Package)
+	case n.Package != nil:
+		return pkgNodeName(n.Package)
+	}
+	return modNodeName(n.Module)
+}
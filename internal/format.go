@@ -0,0 +1,36 @@
+package internal
+
+type Format string
+
+const (
+	DOTFormat     Format = "dot"
+	JSONFormat    Format = "json"
+	MermaidFormat Format = "mermaid"
+	GraphMLFormat Format = "graphml"
+	GEXFFormat    Format = "gexf"
+	HTMLFormat    Format = "html"
+	SVGFormat     Format = "svg"
+)
+
+var validFormats = map[Format]bool{
+	DOTFormat:     true,
+	JSONFormat:    true,
+	MermaidFormat: true,
+	GraphMLFormat: true,
+	GEXFFormat:    true,
+	HTMLFormat:    true,
+	SVGFormat:     true,
+}
+
+func IsValidFormat(format Format) bool {
+	_, ok := validFormats[format]
+	return ok
+}
+
+func ValidFormats() []string {
+	formats := make([]string, 0, len(validFormats))
+	for k := range validFormats {
+		formats = append(formats, string(k))
+	}
+	return formats
+}
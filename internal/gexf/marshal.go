@@ -0,0 +1,149 @@
+package gexf
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+const (
+	namespace = "http://gexf.net/1.3"
+	version   = "1.3"
+
+	edgeInCycleID  = "inImportCycle"
+	suggestedCutID = "suggestedCut"
+)
+
+type document struct {
+	XMLName xml.Name `xml:"gexf"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Version string   `xml:"version,attr"`
+	Meta    meta     `xml:"meta"`
+	Graph   graphDef `xml:"graph"`
+}
+
+type meta struct {
+	Creator     string `xml:"creator"`
+	Description string `xml:"description"`
+}
+
+type graphDef struct {
+	DefaultEdgeType string       `xml:"defaultedgetype,attr"`
+	Mode            string       `xml:"mode,attr"`
+	Attributes      []attributes `xml:"attributes"`
+	Nodes           []node       `xml:"nodes>node"`
+	Edges           []edge       `xml:"edges>edge"`
+}
+
+type attributes struct {
+	Class      string      `xml:"class,attr"`
+	Attributes []attribute `xml:"attribute"`
+}
+
+type attribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+type node struct {
+	ID        string     `xml:"id,attr"`
+	Label     string     `xml:"label,attr"`
+	AttValues []attValue `xml:"attvalues>attvalue"`
+}
+
+type edge struct {
+	ID        string     `xml:"id,attr"`
+	Source    string     `xml:"source,attr"`
+	Target    string     `xml:"target,attr"`
+	Weight    int        `xml:"weight,attr"`
+	AttValues []attValue `xml:"attvalues>attvalue"`
+}
+
+type attValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Marshal serializes the dependency graph, including stub packages and
+// files unless restricted by WithStubPrefixes or merged by
+// WithCollapsedStubs, as GEXF
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{
+		resolution: internal.FileResolution,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	g := graph.WithAllStubs(
+		pkgs,
+		options.resolution,
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	)
+
+	nodeAttributes := attributes{Class: "node"}
+	for _, attr := range graph.NodeAttributes {
+		nodeAttributes.Attributes = append(nodeAttributes.Attributes, attribute{
+			ID:    attr.Name,
+			Title: attr.Name,
+			Type:  string(attr.Type),
+		})
+	}
+	doc := &document{
+		XMLNS:   namespace,
+		Version: version,
+		Meta: meta{
+			Creator:     "godepvis",
+			Description: modulePath,
+		},
+		Graph: graphDef{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []attributes{
+				nodeAttributes,
+				{
+					Class: "edge",
+					Attributes: []attribute{
+						{ID: edgeInCycleID, Title: edgeInCycleID, Type: string(graph.BooleanAttribute)},
+						{ID: suggestedCutID, Title: suggestedCutID, Type: string(graph.BooleanAttribute)},
+					},
+				},
+			},
+		},
+	}
+
+	nodeIDs := make(map[string]string)
+	for i, n := range g.Nodes() {
+		nodeIDs[n.ID] = "n" + strconv.Itoa(i)
+		def := node{
+			ID:    nodeIDs[n.ID],
+			Label: n.Label(),
+		}
+		for _, attr := range graph.NodeAttributes {
+			def.AttValues = append(def.AttValues, attValue{For: attr.Name, Value: attr.Value(n)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, def)
+	}
+	for i, e := range g.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: nodeIDs[e.From.ID],
+			Target: nodeIDs[e.To.ID],
+			Weight: e.Weight(),
+			AttValues: []attValue{
+				{For: edgeInCycleID, Value: strconv.FormatBool(e.InImportCycle())},
+				{For: suggestedCutID, Value: strconv.FormatBool(options.highlightedEdges[e.ID()])},
+			},
+		})
+	}
+
+	output, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
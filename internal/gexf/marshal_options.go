@@ -0,0 +1,66 @@
+package gexf
+
+import (
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+type options struct {
+	resolution       internal.Resolution
+	highlightedEdges map[graph.EdgeID]bool
+	collapseStubs    bool
+	stubPrefixes     []string
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type resolutionOption internal.Resolution
+
+func (opt resolutionOption) apply(opts *options) {
+	opts.resolution = internal.Resolution(opt)
+}
+
+func WithResolution(resolution internal.Resolution) Option {
+	return resolutionOption(resolution)
+}
+
+type highlightedEdgesOption []graph.EdgeID
+
+func (opt highlightedEdgesOption) apply(opts *options) {
+	opts.highlightedEdges = make(map[graph.EdgeID]bool, len(opt))
+	for _, id := range opt {
+		opts.highlightedEdges[id] = true
+	}
+}
+
+// WithHighlightedEdges marks the edges, identified by package or file UIDs
+// depending on the resolution, as suggested cuts
+func WithHighlightedEdges(ids []graph.EdgeID) Option {
+	return highlightedEdgesOption(ids)
+}
+
+type collapsedStubsOption bool
+
+func (opt collapsedStubsOption) apply(opts *options) {
+	opts.collapseStubs = bool(opt)
+}
+
+// WithCollapsedStubs merges the packages of other modules and of the
+// standard library into a node per module
+func WithCollapsedStubs(collapse bool) Option {
+	return collapsedStubsOption(collapse)
+}
+
+type stubPrefixesOption []string
+
+func (opt stubPrefixesOption) apply(opts *options) {
+	opts.stubPrefixes = []string(opt)
+}
+
+// WithStubPrefixes only includes the packages of other modules and of the
+// standard library whose import path starts with one of the prefixes
+func WithStubPrefixes(prefixes []string) Option {
+	return stubPrefixesOption(prefixes)
+}
@@ -0,0 +1,57 @@
+package gexf_test
+
+import (
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/gexf"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"transitive-circular-dependency at the file resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "transitive-circular-dependency.file.gexf",
+			},
+			"with-decl-references at the decl resolution": {
+				Dir:        "with-decl-references",
+				Resolution: internal.DeclResolution,
+				Golden:     "with-decl-references.decl.gexf",
+			},
+			"transitive-circular-dependency at the package resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "transitive-circular-dependency.package.gexf",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return gexf.Marshal(modulePath, pkgs, gexf.WithResolution(resolution))
+		},
+	)
+}
+
+func TestMarshal_WithStubs(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-third-party-modules at the package resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.PackageResolution,
+				Golden:     "with-third-party-modules.package.stubs.gexf",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return gexf.Marshal(
+				modulePath,
+				pkgs,
+				gexf.WithResolution(resolution),
+				gexf.WithCollapsedStubs(true),
+				gexf.WithStubPrefixes([]string{"github.com/fake/", "str"}),
+			)
+		},
+	)
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"strconv"
+)
+
+type AttributeType string
+
+const (
+	StringAttribute  AttributeType = "string"
+	BooleanAttribute AttributeType = "boolean"
+	IntegerAttribute AttributeType = "integer"
+)
+
+// NodeAttribute is a property of a node exported to graph analysis formats
+type NodeAttribute struct {
+	Name  string
+	Type  AttributeType
+	Value func(*Node) string
+}
+
+// NodeAttributes are the properties exported for every node, properties
+// which do not apply to a node, e.g. the file name of a package, are empty
+var NodeAttributes = []NodeAttribute{
+	{
+		Name: "module",
+		Type: StringAttribute,
+		Value: func(n *Node) string {
+			if n.Module != nil {
+				return n.Module.Path
+			}
+			if n.Package != nil && n.Package.Module != nil {
+				return n.Package.Module.Path
+			}
+			return ""
+		},
+	},
+	{
+		Name: "package",
+		Type: StringAttribute,
+		Value: func(n *Node) string {
+			if n.Package == nil {
+				return ""
+			}
+			return n.Package.ImportPath()
+		},
+	},
+	{
+		Name: "packageName",
+		Type: StringAttribute,
+		Value: func(n *Node) string {
+			if n.Package == nil {
+				return ""
+			}
+			return n.Package.Name
+		},
+	},
+	{
+		Name: "file",
+		Type: StringAttribute,
+		Value: func(n *Node) string {
+			if n.File == nil {
+				return ""
+			}
+			return n.File.FileName
+		},
+	},
+	{
+		Name: "decl",
+		Type: StringAttribute,
+		Value: func(n *Node) string {
+			if n.Decl == nil {
+				return ""
+			}
+			return n.Decl.QualifiedName()
+		},
+	},
+	{
+		Name: "isStub",
+		Type: BooleanAttribute,
+		Value: func(n *Node) string {
+			if n.File != nil {
+				return strconv.FormatBool(n.File.IsStub)
+			}
+			if n.Package != nil {
+				return strconv.FormatBool(n.Package.IsStub)
+			}
+			return strconv.FormatBool(n.Module != nil && n.Module.IsStub)
+		},
+	},
+	{
+		Name: "isBlankImport",
+		Type: BooleanAttribute,
+		Value: func(n *Node) string {
+			return strconv.FormatBool(n.File != nil && n.File.IsBlankImport)
+		},
+	},
+	{
+		Name: "isTest",
+		Type: BooleanAttribute,
+		Value: func(n *Node) string {
+			return strconv.FormatBool(n.IsTest())
+		},
+	},
+	{
+		Name: "inImportCycle",
+		Type: BooleanAttribute,
+		Value: func(n *Node) string {
+			return strconv.FormatBool(n.InImportCycle())
+		},
+	},
+	{
+		Name: "scc",
+		Type: IntegerAttribute,
+		Value: func(n *Node) string {
+			return strconv.Itoa(n.SCC())
+		},
+	},
+}
@@ -0,0 +1,243 @@
+package graph
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+)
+
+// ForDecls builds the graph of declarations where an edge exists for every
+// top level declaration of a file referencing a declaration of another
+// package, declarations are backed by their file and package too
+func ForDecls(pkgs []*internal.Package) *Graph {
+	g := New()
+	pkgs = sortedPackages(pkgs)
+	for _, pkg := range pkgs {
+		for _, file := range sortedFiles(pkg) {
+			for _, imp := range sortedImports(file) {
+				for _, name := range slices.Sorted(maps.Keys(imp.ReferencedTypesByDecl)) {
+					from := g.AddNode(declNode(file, enclosingDecl(file, name)))
+					for _, decl := range sortedDecls(imp.ReferencedTypesByDecl[name]) {
+						if decl.File == nil {
+							continue
+						}
+						to := g.AddNode(declNode(decl.File, decl))
+						e := g.AddEdge(from.ID, to.ID)
+						e.addImport(imp)
+						e.addDecl(decl)
+						e.weight++
+					}
+				}
+			}
+		}
+	}
+	return g
+}
+
+// DeclNodeID identifies a declaration by its file's UID and its own UID
+// separated by a "#"
+func DeclNodeID(file *internal.File, decl *internal.Decl) string {
+	return file.UID() + "#" + decl.UID()
+}
+
+func declNode(file *internal.File, decl *internal.Decl) *Node {
+	return &Node{
+		ID:      DeclNodeID(file, decl),
+		Package: file.Package,
+		File:    file,
+		Decl:    decl,
+	}
+}
+
+// enclosingDecl returns the top level declaration of the file with the UID,
+// declarations missing from the file are made up from the UID, e.g. (T).M
+// for methods
+func enclosingDecl(file *internal.File, uid string) *internal.Decl {
+	if decl, ok := file.Decls[uid]; ok {
+		return decl
+	}
+	decl := &internal.Decl{
+		File: file,
+		Name: uid,
+	}
+	if funcName, name, ok := strings.Cut(uid, "."); ok {
+		decl.FuncName = funcName
+		decl.Name = name
+	}
+	if receiverName, ok := strings.CutPrefix(decl.FuncName, "("); ok && strings.HasSuffix(receiverName, ")") {
+		decl.FuncName = strings.TrimSuffix(receiverName, ")")
+		decl.IsMethod = true
+	}
+	return decl
+}
+
+// ForFiles builds the graph of files where an edge exists for every file
+// containing a declaration referenced by another file
+func ForFiles(pkgs []*internal.Package) *Graph {
+	g := New()
+	pkgs = sortedPackages(pkgs)
+	for _, pkg := range pkgs {
+		for _, file := range sortedFiles(pkg) {
+			g.AddNode(&Node{
+				ID:      file.UID(),
+				Package: pkg,
+				File:    file,
+			})
+		}
+	}
+	for _, pkg := range pkgs {
+		for _, file := range sortedFiles(pkg) {
+			for _, imp := range sortedImports(file) {
+				for _, decl := range sortedDecls(imp.ReferencedTypes) {
+					if decl.File == nil {
+						continue
+					}
+					g.AddNode(&Node{
+						ID:      decl.File.UID(),
+						Package: decl.File.Package,
+						File:    decl.File,
+					})
+					e := g.AddEdge(file.UID(), decl.File.UID())
+					e.addImport(imp)
+					e.addDecl(decl)
+					e.weight++
+				}
+			}
+		}
+	}
+	return g
+}
+
+// ForPackages builds the graph of packages where an edge exists for every
+// package imported by another package
+func ForPackages(pkgs []*internal.Package) *Graph {
+	return forPackages(pkgs, false)
+}
+
+// ForPackageReferences builds the graph of packages where an edge exists for
+// every package containing a declaration referenced by another package, like
+// ForFiles imports referencing no declaration are left out. Import cycles are
+// found on this graph so packages are only in an import cycle through the
+// declarations they reference.
+func ForPackageReferences(pkgs []*internal.Package) *Graph {
+	return forPackages(pkgs, true)
+}
+
+func forPackages(pkgs []*internal.Package, referencesOnly bool) *Graph {
+	g := New()
+	pkgs = sortedPackages(pkgs)
+	for _, pkg := range pkgs {
+		g.AddNode(&Node{
+			ID:      pkg.UID(),
+			Package: pkg,
+		})
+	}
+	for _, pkg := range pkgs {
+		for _, file := range sortedFiles(pkg) {
+			for _, imp := range sortedImports(file) {
+				if imp.Package == nil {
+					continue
+				}
+				if referencesOnly && !referencesDecl(imp) {
+					continue
+				}
+				g.AddNode(&Node{
+					ID:      imp.Package.UID(),
+					Package: imp.Package,
+				})
+				e := g.AddEdge(pkg.UID(), imp.Package.UID())
+				if e.addImport(imp) {
+					e.weight += len(imp.ReferencedTypes)
+				}
+				for _, decl := range sortedDecls(imp.ReferencedTypes) {
+					e.addDecl(decl)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// ForModules builds the graph of modules where an edge exists for every
+// module importing a package of another module, including stub modules, i.e.
+// required modules and the standard library. Edges are weighted by the number
+// of imports rather than by referenced declarations.
+func ForModules(pkgs []*internal.Package) *Graph {
+	g := New()
+	pkgs = sortedPackages(pkgs)
+	for _, pkg := range pkgs {
+		if pkg.Module == nil {
+			continue
+		}
+		g.AddNode(&Node{
+			ID:     pkg.Module.UID(),
+			Module: pkg.Module,
+		})
+	}
+	for _, pkg := range pkgs {
+		if pkg.Module == nil {
+			continue
+		}
+		for _, file := range sortedFiles(pkg) {
+			for _, imp := range sortedImports(file) {
+				if imp.Package == nil || imp.Package.Module == nil || imp.Package.Module == pkg.Module {
+					continue
+				}
+				e := g.AddEdge(pkg.Module.UID(), imp.Package.Module.UID())
+				if e.addImport(imp) {
+					e.weight++
+				}
+				for _, decl := range sortedDecls(imp.ReferencedTypes) {
+					e.addDecl(decl)
+				}
+			}
+		}
+	}
+	return g
+}
+
+// referencesDecl reports whether the import references a declaration of a
+// file, see ForFiles
+func referencesDecl(imp *internal.Import) bool {
+	for _, decl := range imp.ReferencedTypes {
+		if decl.File != nil {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedPackages(pkgs []*internal.Package) []*internal.Package {
+	sorted := slices.Clone(pkgs)
+	slices.SortFunc(sorted, func(a, b *internal.Package) int {
+		return cmp.Compare(a.UID(), b.UID())
+	})
+	return sorted
+}
+
+func sortedFiles(pkg *internal.Package) []*internal.File {
+	files := make([]*internal.File, 0, len(pkg.Files))
+	for _, uid := range slices.Sorted(maps.Keys(pkg.Files)) {
+		files = append(files, pkg.Files[uid])
+	}
+	return files
+}
+
+func sortedImports(file *internal.File) []*internal.Import {
+	imps := make([]*internal.Import, 0, len(file.Imports))
+	for _, uid := range slices.Sorted(maps.Keys(file.Imports)) {
+		imps = append(imps, file.Imports[uid])
+	}
+	return imps
+}
+
+func sortedDecls(decls map[string]*internal.Decl) []*internal.Decl {
+	sorted := make([]*internal.Decl, 0, len(decls))
+	for _, uid := range slices.Sorted(maps.Keys(decls)) {
+		sorted = append(sorted, decls[uid])
+	}
+	return sorted
+}
@@ -0,0 +1,108 @@
+package graph
+
+// ElementaryCycles returns every elementary cycle in the graph using
+// Johnson's algorithm. Each cycle is an ordered path starting with the node
+// inserted earliest into the graph, the edge closing the cycle is implied
+// from the last node back to the first. The number of cycles grows
+// exponentially with the edges between the nodes of a component, so the
+// search stops once more than limit cycles are found, reporting the cycles
+// are truncated, unless limit is 0.
+func (g *Graph) ElementaryCycles(limit int) ([][]*Node, bool) {
+	var cycles [][]*Node
+	truncated := false
+
+	blocked := make([]bool, len(g.nodes))
+	blockedBy := make([]map[int]struct{}, len(g.nodes))
+	inComponent := make([]bool, len(g.nodes))
+	var path []int
+
+	var unblock func(v int)
+	unblock = func(v int) {
+		blocked[v] = false
+		for w := range blockedBy[v] {
+			delete(blockedBy[v], w)
+			if blocked[w] {
+				unblock(w)
+			}
+		}
+	}
+
+	var circuit func(start, v int) bool
+	circuit = func(start, v int) bool {
+		found := false
+		path = append(path, v)
+		blocked[v] = true
+		for _, w := range g.succ[v] {
+			if truncated {
+				break
+			}
+			if !inComponent[w] {
+				continue
+			}
+			if w == start {
+				if limit > 0 && len(cycles) == limit {
+					truncated = true
+					break
+				}
+				cycle := make([]*Node, 0, len(path))
+				for _, i := range path {
+					cycle = append(cycle, g.nodes[i])
+				}
+				cycles = append(cycles, cycle)
+				found = true
+				continue
+			}
+			if !blocked[w] && circuit(start, w) {
+				found = true
+			}
+		}
+		if found {
+			unblock(v)
+		} else {
+			for _, w := range g.succ[v] {
+				if !inComponent[w] {
+					continue
+				}
+				if blockedBy[w] == nil {
+					blockedBy[w] = make(map[int]struct{})
+				}
+				blockedBy[w][v] = struct{}{}
+			}
+		}
+		path = path[:len(path)-1]
+		return found
+	}
+
+	for start := range g.nodes {
+		if truncated {
+			break
+		}
+		// find the component containing start in the subgraph induced by
+		// start and every node inserted after it
+		var component []int
+		for _, c := range g.tarjan(func(i int) bool { return i >= start }) {
+			for _, i := range c {
+				if i == start {
+					component = c
+					break
+				}
+			}
+			if component != nil {
+				break
+			}
+		}
+		if len(component) == 1 && g.Edge(g.nodes[start].ID, g.nodes[start].ID) == nil {
+			continue
+		}
+		for _, i := range component {
+			inComponent[i] = true
+			blocked[i] = false
+			blockedBy[i] = nil
+		}
+		circuit(start, start)
+		for _, i := range component {
+			inComponent[i] = false
+		}
+	}
+	return cycles, truncated
+}
@@ -0,0 +1,101 @@
+package graph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGraph_ElementaryCycles(t *testing.T) {
+	testCases := map[string]struct {
+		nodes             []string
+		edges             [][2]string
+		limit             int
+		expectedCycles    []string
+		expectedTruncated bool
+	}{
+		"no cycles": {
+			nodes: []string{"main", "a", "b"},
+			edges: [][2]string{{"main", "a"}, {"a", "b"}},
+		},
+		"self loop": {
+			nodes:          []string{"a"},
+			edges:          [][2]string{{"a", "a"}},
+			expectedCycles: []string{"a"},
+		},
+		"direct cycle": {
+			nodes:          []string{"main", "a", "b"},
+			edges:          [][2]string{{"main", "a"}, {"a", "b"}, {"b", "a"}},
+			expectedCycles: []string{"a,b"},
+		},
+		"interlinked cycles": {
+			nodes: []string{"a", "b", "c"},
+			edges: [][2]string{
+				{"a", "b"}, {"b", "a"},
+				{"b", "c"}, {"c", "b"},
+				{"c", "a"},
+			},
+			expectedCycles: []string{"a,b", "a,b,c", "b,c"},
+		},
+		"complete graph": {
+			nodes: []string{"a", "b", "c"},
+			edges: [][2]string{
+				{"a", "b"}, {"a", "c"},
+				{"b", "a"}, {"b", "c"},
+				{"c", "a"}, {"c", "b"},
+			},
+			expectedCycles: []string{"a,b", "a,b,c", "a,c", "a,c,b", "b,c"},
+		},
+		"complete graph within the limit": {
+			nodes: []string{"a", "b", "c"},
+			edges: [][2]string{
+				{"a", "b"}, {"a", "c"},
+				{"b", "a"}, {"b", "c"},
+				{"c", "a"}, {"c", "b"},
+			},
+			limit:          5,
+			expectedCycles: []string{"a,b", "a,b,c", "a,c", "a,c,b", "b,c"},
+		},
+		"complete graph over the limit": {
+			nodes: []string{"a", "b", "c"},
+			edges: [][2]string{
+				{"a", "b"}, {"a", "c"},
+				{"b", "a"}, {"b", "c"},
+				{"c", "a"}, {"c", "b"},
+			},
+			limit:             2,
+			expectedCycles:    []string{"a,b", "a,b,c"},
+			expectedTruncated: true,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.New()
+		for _, id := range testCase.nodes {
+			g.AddNode(&graph.Node{ID: id})
+		}
+		for _, e := range testCase.edges {
+			g.AddEdge(e[0], e[1])
+		}
+
+		cycles, actualTruncated := g.ElementaryCycles(testCase.limit)
+		var actualCycles []string
+		for _, cycle := range cycles {
+			ids := make([]string, 0, len(cycle))
+			for _, node := range cycle {
+				ids = append(ids, node.ID)
+			}
+			actualCycles = append(actualCycles, strings.Join(ids, ","))
+		}
+
+		if diff := cmp.Diff(testCase.expectedCycles, actualCycles); diff != "" {
+			t.Error(desc, test.Mismatch(": expected cycles: ", diff))
+		}
+		if actualTruncated != testCase.expectedTruncated {
+			t.Errorf("%s: expected truncated %t, got %t", desc, testCase.expectedTruncated, actualTruncated)
+		}
+	}
+}
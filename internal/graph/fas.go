@@ -0,0 +1,185 @@
+package graph
+
+import (
+	"cmp"
+	"slices"
+)
+
+// FeedbackArcSet returns an approximately minimum weight set of edges whose
+// removal leaves the graph acyclic. Each cyclic strongly connected component
+// is ordered using the Eades-Lin-Smyth heuristic, the edges pointing
+// backwards in that order are cut and any cut edge which can be restored
+// without closing a cycle is restored. Edges are returned lightest first.
+func (g *Graph) FeedbackArcSet() []*Edge {
+	var cut []*Edge
+	for _, component := range g.tarjan(func(int) bool { return true }) {
+		if len(component) == 1 {
+			v := component[0]
+			if e, ok := g.edges[edgeKey{from: v, to: v}]; ok {
+				cut = append(cut, e)
+			}
+			continue
+		}
+		cut = append(cut, g.componentFeedbackArcSet(component)...)
+	}
+	slices.SortStableFunc(cut, func(a, b *Edge) int {
+		if c := cmp.Compare(a.Weight(), b.Weight()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.From.ID, b.From.ID); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.To.ID, b.To.ID)
+	})
+	return cut
+}
+
+func (g *Graph) componentFeedbackArcSet(component []int) []*Edge {
+	slices.Sort(component)
+	remaining := make(map[int]bool, len(component))
+	for _, v := range component {
+		remaining[v] = true
+	}
+	pred := make(map[int][]int, len(component))
+	for _, v := range component {
+		for _, w := range g.succ[v] {
+			if !remaining[w] {
+				continue
+			}
+			pred[w] = append(pred[w], v)
+		}
+	}
+
+	// degrees and weights only count edges between remaining nodes, self
+	// loops are always cut so never influence the order
+	degrees := func(v int) (int, int, int) {
+		var outDeg, inDeg, delta int
+		for _, w := range g.succ[v] {
+			if w == v || !remaining[w] {
+				continue
+			}
+			outDeg++
+			delta += cutCost(g.edges[edgeKey{from: v, to: w}])
+		}
+		for _, u := range pred[v] {
+			if u == v || !remaining[u] {
+				continue
+			}
+			inDeg++
+			delta -= cutCost(g.edges[edgeKey{from: u, to: v}])
+		}
+		return outDeg, inDeg, delta
+	}
+
+	var head, tail []int
+	for len(remaining) > 0 {
+		removed := true
+		for removed {
+			removed = false
+			for _, v := range component {
+				if !remaining[v] {
+					continue
+				}
+				outDeg, inDeg, _ := degrees(v)
+				if outDeg == 0 {
+					tail = append(tail, v)
+					delete(remaining, v)
+					removed = true
+					continue
+				}
+				if inDeg == 0 {
+					head = append(head, v)
+					delete(remaining, v)
+					removed = true
+				}
+			}
+		}
+		if len(remaining) == 0 {
+			break
+		}
+		best, bestDelta := -1, 0
+		for _, v := range component {
+			if !remaining[v] {
+				continue
+			}
+			_, _, delta := degrees(v)
+			if best == -1 || delta > bestDelta {
+				best, bestDelta = v, delta
+			}
+		}
+		head = append(head, best)
+		delete(remaining, best)
+	}
+	slices.Reverse(tail)
+	order := slices.Concat(head, tail)
+
+	position := make(map[int]int, len(order))
+	for i, v := range order {
+		position[v] = i
+	}
+	cut := make(map[edgeKey]bool)
+	for _, v := range component {
+		for _, w := range g.succ[v] {
+			if _, ok := position[w]; !ok {
+				continue
+			}
+			if position[v] >= position[w] {
+				cut[edgeKey{from: v, to: w}] = true
+			}
+		}
+	}
+
+	// restore the heaviest edges first, an edge can be restored if its
+	// target can not reach its source through the edges which are kept
+	keys := make([]edgeKey, 0, len(cut))
+	for key := range cut {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b edgeKey) int {
+		if c := cmp.Compare(cutCost(g.edges[b]), cutCost(g.edges[a])); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(a.from, b.from); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.to, b.to)
+	})
+	var edges []*Edge
+	for _, key := range keys {
+		delete(cut, key)
+		if g.reachable(key.to, key.from, position, cut) {
+			cut[key] = true
+			edges = append(edges, g.edges[key])
+		}
+	}
+	return edges
+}
+
+func (g *Graph) reachable(from, to int, within map[int]int, cut map[edgeKey]bool) bool {
+	visited := map[int]bool{from: true}
+	queue := []int{from}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if v == to {
+			return true
+		}
+		for _, w := range g.succ[v] {
+			if _, ok := within[w]; !ok {
+				continue
+			}
+			if visited[w] || cut[edgeKey{from: v, to: w}] {
+				continue
+			}
+			visited[w] = true
+			queue = append(queue, w)
+		}
+	}
+	return false
+}
+
+// cutCost is the cost of cutting an edge, an import without any referenced
+// declarations still has to be removed so never costs nothing
+func cutCost(e *Edge) int {
+	return max(e.Weight(), 1)
+}
@@ -0,0 +1,59 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGraph_FeedbackArcSet(t *testing.T) {
+	testCases := map[string]struct {
+		nodes       []string
+		edges       [][2]string
+		expectedCut []string
+	}{
+		"no cycles": {
+			nodes: []string{"main", "a", "b"},
+			edges: [][2]string{{"main", "a"}, {"a", "b"}},
+		},
+		"self loop": {
+			nodes:       []string{"a", "b"},
+			edges:       [][2]string{{"a", "a"}, {"a", "b"}},
+			expectedCut: []string{"a->a"},
+		},
+		"direct cycle": {
+			nodes:       []string{"main", "a", "b"},
+			edges:       [][2]string{{"main", "a"}, {"a", "b"}, {"b", "a"}},
+			expectedCut: []string{"b->a"},
+		},
+		"shared edge breaks both cycles": {
+			nodes: []string{"a", "b", "c", "d"},
+			edges: [][2]string{
+				{"a", "b"}, {"b", "c"}, {"c", "a"},
+				{"b", "d"}, {"d", "a"},
+			},
+			expectedCut: []string{"a->b"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.New()
+		for _, id := range testCase.nodes {
+			g.AddNode(&graph.Node{ID: id})
+		}
+		for _, e := range testCase.edges {
+			g.AddEdge(e[0], e[1])
+		}
+
+		var actualCut []string
+		for _, e := range g.FeedbackArcSet() {
+			actualCut = append(actualCut, e.From.ID+"->"+e.To.ID)
+		}
+
+		if diff := cmp.Diff(testCase.expectedCut, actualCut); diff != "" {
+			t.Error(desc, test.Mismatch(": expected cut edges: ", diff))
+		}
+	}
+}
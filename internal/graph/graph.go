@@ -0,0 +1,321 @@
+package graph
+
+import (
+	"github.com/samlitowitz/godepvis/internal"
+)
+
+// Node is a vertex in a dependency graph, backed by a module, a package, a
+// file or a declaration of a file
+type Node struct {
+	ID string
+
+	Module  *internal.Module
+	Package *internal.Package
+	File    *internal.File
+	Decl    *internal.Decl
+}
+
+// Label is the short name of the node, the qualified name for declarations,
+// the file name for files, the module
+// relative path for packages, the import path for stub packages and the
+// module path, along with the required version of stub modules, for modules
+func (n Node) Label() string {
+	if n.Decl != nil {
+		return n.Decl.QualifiedName()
+	}
+	if n.File != nil {
+		return n.File.FileName
+	}
+	if n.Package != nil && n.Package.IsStub {
+		return n.Package.ImportPath()
+	}
+	if n.Package != nil {
+		return n.Package.ModuleRelativePath()
+	}
+	if n.Module != nil && n.Module.Version != "" {
+		return n.Module.Path + "@" + n.Module.Version
+	}
+	if n.Module != nil {
+		return n.Module.Path
+	}
+	return n.ID
+}
+
+func (n Node) InImportCycle() bool {
+	if n.File != nil {
+		return n.File.InImportCycle
+	}
+	if n.Package != nil {
+		return n.Package.InImportCycle
+	}
+	if n.Module != nil {
+		return n.Module.InImportCycle
+	}
+	return false
+}
+
+// SCC is the strongly connected component of the node, see InImportCycle
+func (n Node) SCC() int {
+	if n.File != nil {
+		return n.File.SCC
+	}
+	if n.Package != nil {
+		return n.Package.SCC
+	}
+	if n.Module != nil {
+		return n.Module.SCC
+	}
+	return 0
+}
+
+// IsTest reports whether the node is a test file or an external test
+// package
+func (n Node) IsTest() bool {
+	if n.File != nil {
+		return n.File.IsTest
+	}
+	if n.Package != nil {
+		return n.Package.IsTest
+	}
+	return false
+}
+
+// IsStub reports whether the node is a package or a module outside of the
+// analyzed modules
+func (n Node) IsStub() bool {
+	if n.Package != nil {
+		return n.Package.IsStub
+	}
+	return n.Module != nil && n.Module.IsStub
+}
+
+// IsStdlib reports whether the node is a package or the module of the
+// standard library
+func (n Node) IsStdlib() bool {
+	if n.Package != nil {
+		return n.Package.IsStub && n.Package.Module != nil && n.Package.Module.IsStdlib
+	}
+	return n.Module != nil && n.Module.IsStdlib
+}
+
+// Edge is a dependency from one node to another along with the imports and
+// declarations which cause it
+type Edge struct {
+	From *Node
+	To   *Node
+
+	Imports []*internal.Import
+	Decls   []*internal.Decl
+
+	weight int
+}
+
+// EdgeID identifies an edge by the IDs of the nodes it connects
+type EdgeID struct {
+	From string
+	To   string
+}
+
+func (e Edge) ID() EdgeID {
+	return EdgeID{From: e.From.ID, To: e.To.ID}
+}
+
+// Weight is the number of declarations referenced through the imports
+// carried by the edge, or the number of imports at the module resolution
+func (e Edge) Weight() int {
+	return e.weight
+}
+
+// InImportCycle reports whether both nodes of the edge belong to the same
+// import cycle
+func (e Edge) InImportCycle() bool {
+	if e.From.File != nil && e.To.File != nil {
+		return e.From.File.InImportCycle && e.From.File.SCC == e.To.File.SCC
+	}
+	if e.From.Package != nil && e.To.Package != nil {
+		return e.From.Package.InImportCycle && e.From.Package.SCC == e.To.Package.SCC
+	}
+	if e.From.Module != nil && e.To.Module != nil {
+		return e.From.Module.InImportCycle && e.From.Module.SCC == e.To.Module.SCC
+	}
+	return false
+}
+
+func (e *Edge) addImport(imp *internal.Import) bool {
+	for _, existing := range e.Imports {
+		if existing == imp {
+			return false
+		}
+	}
+	e.Imports = append(e.Imports, imp)
+	return true
+}
+
+func (e *Edge) addDecl(decl *internal.Decl) {
+	for _, existing := range e.Decls {
+		if existing == decl {
+			return
+		}
+	}
+	e.Decls = append(e.Decls, decl)
+}
+
+type edgeKey struct {
+	from, to int
+}
+
+// Graph is a directed graph with nodes kept in insertion order
+type Graph struct {
+	nodes     []*Node
+	nodeIndex map[string]int
+	succ      [][]int
+	edges     map[edgeKey]*Edge
+}
+
+func New() *Graph {
+	return &Graph{
+		nodeIndex: make(map[string]int),
+		edges:     make(map[edgeKey]*Edge),
+	}
+}
+
+// AddNode adds the node to the graph, if a node with the same ID already
+// exists the existing node is returned instead
+func (g *Graph) AddNode(n *Node) *Node {
+	if i, ok := g.nodeIndex[n.ID]; ok {
+		return g.nodes[i]
+	}
+	g.nodeIndex[n.ID] = len(g.nodes)
+	g.nodes = append(g.nodes, n)
+	g.succ = append(g.succ, nil)
+	return n
+}
+
+// AddEdge adds an edge between two existing nodes, if the edge already
+// exists the existing edge is returned instead
+func (g *Graph) AddEdge(from, to string) *Edge {
+	fromIdx, ok := g.nodeIndex[from]
+	if !ok {
+		return nil
+	}
+	toIdx, ok := g.nodeIndex[to]
+	if !ok {
+		return nil
+	}
+	key := edgeKey{from: fromIdx, to: toIdx}
+	if e, ok := g.edges[key]; ok {
+		return e
+	}
+	e := &Edge{
+		From: g.nodes[fromIdx],
+		To:   g.nodes[toIdx],
+	}
+	g.edges[key] = e
+	g.succ[fromIdx] = append(g.succ[fromIdx], toIdx)
+	return e
+}
+
+func (g *Graph) Node(id string) *Node {
+	i, ok := g.nodeIndex[id]
+	if !ok {
+		return nil
+	}
+	return g.nodes[i]
+}
+
+func (g *Graph) Nodes() []*Node {
+	nodes := make([]*Node, len(g.nodes))
+	copy(nodes, g.nodes)
+	return nodes
+}
+
+func (g *Graph) Edge(from, to string) *Edge {
+	fromIdx, ok := g.nodeIndex[from]
+	if !ok {
+		return nil
+	}
+	toIdx, ok := g.nodeIndex[to]
+	if !ok {
+		return nil
+	}
+	return g.edges[edgeKey{from: fromIdx, to: toIdx}]
+}
+
+// Edges returns all edges ordered by source node then insertion order
+func (g *Graph) Edges() []*Edge {
+	edges := make([]*Edge, 0, len(g.edges))
+	for from, succ := range g.succ {
+		for _, to := range succ {
+			edges = append(edges, g.edges[edgeKey{from: from, to: to}])
+		}
+	}
+	return edges
+}
+
+func (g *Graph) Successors(id string) []*Node {
+	i, ok := g.nodeIndex[id]
+	if !ok {
+		return nil
+	}
+	succ := make([]*Node, 0, len(g.succ[i]))
+	for _, j := range g.succ[i] {
+		succ = append(succ, g.nodes[j])
+	}
+	return succ
+}
+
+// Filter returns a graph containing the nodes and edges for which keepNode
+// and keepEdge return true, edges are only kept if both of their nodes are
+func (g *Graph) Filter(keepNode func(*Node) bool, keepEdge func(*Edge) bool) *Graph {
+	filtered := New()
+	for _, n := range g.nodes {
+		if !keepNode(n) {
+			continue
+		}
+		filtered.AddNode(n)
+	}
+	for _, e := range g.Edges() {
+		if !keepEdge(e) {
+			continue
+		}
+		fromIdx, ok := filtered.nodeIndex[e.From.ID]
+		if !ok {
+			continue
+		}
+		toIdx, ok := filtered.nodeIndex[e.To.ID]
+		if !ok {
+			continue
+		}
+		filtered.edges[edgeKey{from: fromIdx, to: toIdx}] = e
+		filtered.succ[fromIdx] = append(filtered.succ[fromIdx], toIdx)
+	}
+	return filtered
+}
+
+// Collapse returns a graph where every node is replaced by the node into
+// returns for it. Nodes replaced by the same node are merged along with the
+// imports, declarations and weights of their edges, edges between merged
+// nodes are dropped.
+func (g *Graph) Collapse(into func(*Node) *Node) *Graph {
+	collapsed := New()
+	ids := make([]string, len(g.nodes))
+	for i, n := range g.nodes {
+		ids[i] = collapsed.AddNode(into(n)).ID
+	}
+	for _, e := range g.Edges() {
+		from := ids[g.nodeIndex[e.From.ID]]
+		to := ids[g.nodeIndex[e.To.ID]]
+		if from == to {
+			continue
+		}
+		merged := collapsed.AddEdge(from, to)
+		for _, imp := range e.Imports {
+			merged.addImport(imp)
+		}
+		for _, decl := range e.Decls {
+			merged.addDecl(decl)
+		}
+		merged.weight += e.weight
+	}
+	return collapsed
+}
@@ -0,0 +1,66 @@
+package graph
+
+// Predecessors returns the nodes with an edge to the node, in insertion order
+func (g *Graph) Predecessors(id string) []*Node {
+	i, ok := g.nodeIndex[id]
+	if !ok {
+		return nil
+	}
+	var pred []*Node
+	for from, succ := range g.succ {
+		for _, to := range succ {
+			if to == i {
+				pred = append(pred, g.nodes[from])
+				break
+			}
+		}
+	}
+	return pred
+}
+
+// ShortestPath returns the nodes of a path with the fewest edges from one
+// node to the other, both included, or nil when there is none. Ties are
+// broken by insertion order so the same path is returned every time.
+func (g *Graph) ShortestPath(from, to string) []*Node {
+	fromIdx, ok := g.nodeIndex[from]
+	if !ok {
+		return nil
+	}
+	toIdx, ok := g.nodeIndex[to]
+	if !ok {
+		return nil
+	}
+
+	prev := make([]int, len(g.nodes))
+	for i := range prev {
+		prev[i] = -1
+	}
+	prev[fromIdx] = fromIdx
+	queue := []int{fromIdx}
+	for len(queue) > 0 && prev[toIdx] == -1 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, w := range g.succ[v] {
+			if prev[w] != -1 {
+				continue
+			}
+			prev[w] = v
+			queue = append(queue, w)
+		}
+	}
+	if prev[toIdx] == -1 {
+		return nil
+	}
+
+	var path []*Node
+	for v := toIdx; ; v = prev[v] {
+		path = append(path, g.nodes[v])
+		if v == fromIdx {
+			break
+		}
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
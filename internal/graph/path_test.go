@@ -0,0 +1,111 @@
+package graph_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGraph_ShortestPath(t *testing.T) {
+	testCases := map[string]struct {
+		nodes        []string
+		edges        [][2]string
+		from, to     string
+		expectedPath []string
+	}{
+		"same node": {
+			nodes:        []string{"a", "b"},
+			edges:        [][2]string{{"a", "b"}},
+			from:         "a",
+			to:           "a",
+			expectedPath: []string{"a"},
+		},
+		"direct edge": {
+			nodes:        []string{"a", "b"},
+			edges:        [][2]string{{"a", "b"}},
+			from:         "a",
+			to:           "b",
+			expectedPath: []string{"a", "b"},
+		},
+		"fewest edges": {
+			nodes: []string{"main", "a", "b", "c"},
+			edges: [][2]string{
+				{"main", "a"}, {"a", "b"}, {"b", "c"},
+				{"main", "c"},
+			},
+			from:         "main",
+			to:           "c",
+			expectedPath: []string{"main", "c"},
+		},
+		"ties broken by insertion order": {
+			nodes: []string{"main", "a", "b", "c"},
+			edges: [][2]string{
+				{"main", "b"}, {"main", "a"},
+				{"a", "c"}, {"b", "c"},
+			},
+			from:         "main",
+			to:           "c",
+			expectedPath: []string{"main", "b", "c"},
+		},
+		"through a cycle": {
+			nodes: []string{"a", "b", "c"},
+			edges: [][2]string{
+				{"a", "b"}, {"b", "a"}, {"b", "c"},
+			},
+			from:         "a",
+			to:           "c",
+			expectedPath: []string{"a", "b", "c"},
+		},
+		"edges are directed": {
+			nodes: []string{"a", "b"},
+			edges: [][2]string{{"a", "b"}},
+			from:  "b",
+			to:    "a",
+		},
+		"unknown node": {
+			nodes: []string{"a"},
+			from:  "a",
+			to:    "b",
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.New()
+		for _, id := range testCase.nodes {
+			g.AddNode(&graph.Node{ID: id})
+		}
+		for _, e := range testCase.edges {
+			g.AddEdge(e[0], e[1])
+		}
+
+		var actualPath []string
+		for _, n := range g.ShortestPath(testCase.from, testCase.to) {
+			actualPath = append(actualPath, n.ID)
+		}
+
+		if diff := cmp.Diff(testCase.expectedPath, actualPath); diff != "" {
+			t.Error(desc, test.Mismatch(": expected path: ", diff))
+		}
+	}
+}
+
+func TestGraph_Predecessors(t *testing.T) {
+	g := graph.New()
+	for _, id := range []string{"main", "a", "b", "c"} {
+		g.AddNode(&graph.Node{ID: id})
+	}
+	for _, e := range [][2]string{{"main", "a"}, {"main", "b"}, {"a", "b"}, {"b", "c"}} {
+		g.AddEdge(e[0], e[1])
+	}
+
+	var actual []string
+	for _, n := range g.Predecessors("b") {
+		actual = append(actual, n.ID)
+	}
+
+	if diff := cmp.Diff([]string{"main", "a"}, actual); diff != "" {
+		t.Error(test.Mismatch("expected predecessors: ", diff))
+	}
+}
@@ -0,0 +1,195 @@
+package graph
+
+import (
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+)
+
+// ForResolution builds the graph of the given resolution
+func ForResolution(pkgs []*internal.Package, resolution internal.Resolution) *Graph {
+	switch resolution {
+	case internal.DeclResolution:
+		return ForDecls(pkgs)
+	case internal.FileResolution:
+		return ForFiles(pkgs)
+	case internal.PackageResolution:
+		return ForPackages(pkgs)
+	case internal.ModuleResolution:
+		return ForModules(pkgs)
+	}
+	return New()
+}
+
+// VisibleOption shows nodes which are hidden by default
+type VisibleOption interface {
+	apply(*visibility)
+}
+
+type visibility struct {
+	stdlib        bool
+	stubs         bool
+	collapseStubs bool
+	stubPrefixes  []string
+}
+
+type stdlibOption bool
+
+func (o stdlibOption) apply(v *visibility) {
+	v.stdlib = bool(o)
+}
+
+// WithStdlib shows the standard library module at the module resolution
+func WithStdlib(show bool) VisibleOption {
+	return stdlibOption(show)
+}
+
+type stubsOption bool
+
+func (o stubsOption) apply(v *visibility) {
+	v.stubs = bool(o)
+}
+
+// WithStubs shows stub packages, i.e. packages of other modules and of the
+// standard library, as nodes at the file and package resolutions
+func WithStubs(show bool) VisibleOption {
+	return stubsOption(show)
+}
+
+type collapsedStubsOption bool
+
+func (o collapsedStubsOption) apply(v *visibility) {
+	v.collapseStubs = bool(o)
+}
+
+// WithCollapsedStubs merges the shown stub packages of each module into a
+// node of the module, see WithStubs
+func WithCollapsedStubs(collapse bool) VisibleOption {
+	return collapsedStubsOption(collapse)
+}
+
+type stubPrefixesOption []string
+
+func (o stubPrefixesOption) apply(v *visibility) {
+	v.stubPrefixes = []string(o)
+}
+
+// WithStubPrefixes only shows the stub packages whose import path starts
+// with one of the prefixes, see WithStubs
+func WithStubPrefixes(prefixes []string) VisibleOption {
+	return stubPrefixesOption(prefixes)
+}
+
+// Visible returns the graph of the given resolution restricted to the nodes
+// and edges which are rendered, stub packages and stub files other than blank
+// import files are hidden, unless WithStubs is set, as are files without
+// declarations. Stub modules are shown at the module resolution, except for
+// the standard library unless WithStdlib is set.
+func Visible(pkgs []*internal.Package, resolution internal.Resolution, opts ...VisibleOption) *Graph {
+	v := &visibility{}
+	for _, opt := range opts {
+		opt.apply(v)
+	}
+	g := ForResolution(pkgs, resolution).Filter(
+		func(n *Node) bool {
+			return v.isVisibleNode(n)
+		},
+		isVisibleEdge,
+	)
+	if !v.stubs || resolution == internal.ModuleResolution {
+		return g
+	}
+	return g.Collapse(v.stubNode)
+}
+
+// WithAllStubs returns the graph of the given resolution like ForResolution,
+// which keeps every stub package, restricted to the stub packages selected by
+// WithStubPrefixes and merged into their modules if WithCollapsedStubs is set
+func WithAllStubs(pkgs []*internal.Package, resolution internal.Resolution, opts ...VisibleOption) *Graph {
+	v := &visibility{}
+	for _, opt := range opts {
+		opt.apply(v)
+	}
+	v.stubs = true
+	g := ForResolution(pkgs, resolution)
+	if resolution == internal.ModuleResolution {
+		return g
+	}
+	g = g.Filter(
+		func(n *Node) bool {
+			return n.Package == nil || !n.Package.IsStub || v.isVisibleStub(n.Package)
+		},
+		func(*Edge) bool {
+			return true
+		},
+	)
+	if !v.collapseStubs {
+		return g
+	}
+	return g.Collapse(v.stubNode)
+}
+
+// stubNode replaces the files of a stub package by the package and, if
+// collapsed, the stub package by its module
+func (v *visibility) stubNode(n *Node) *Node {
+	if n.Package == nil || !n.Package.IsStub {
+		return n
+	}
+	if v.collapseStubs && n.Package.Module != nil {
+		return &Node{
+			ID:     n.Package.Module.UID(),
+			Module: n.Package.Module,
+		}
+	}
+	if n.File == nil {
+		return n
+	}
+	return &Node{
+		ID:      n.Package.UID(),
+		Package: n.Package,
+	}
+}
+
+func (v *visibility) isVisibleStub(pkg *internal.Package) bool {
+	if !v.stubs {
+		return false
+	}
+	if len(v.stubPrefixes) == 0 {
+		return true
+	}
+	for _, prefix := range v.stubPrefixes {
+		if strings.HasPrefix(pkg.ImportPath(), prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *visibility) isVisibleNode(n *Node) bool {
+	if n.Module != nil && n.Package == nil {
+		if n.Module.IsStdlib && !v.stdlib {
+			return false
+		}
+		return len(n.Module.Packages) > 0
+	}
+	if n.Package == nil {
+		return false
+	}
+	if n.Package.IsStub {
+		return v.isVisibleStub(n.Package) && (n.File == nil || len(n.File.Decls) > 0)
+	}
+	if n.File == nil {
+		return len(n.Package.Files) > 0
+	}
+	if n.File.IsStub && !n.File.IsBlankImport {
+		return false
+	}
+	return len(n.File.Decls) > 0
+}
+
+func isVisibleEdge(e *Edge) bool {
+	if e.From.File != nil && e.From.File.IsStub {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,190 @@
+package graph_test
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGraph_Collapse(t *testing.T) {
+	g := graph.New()
+	for _, id := range []string{"a", "b", "x/1", "x/2"} {
+		g.AddNode(&graph.Node{ID: id})
+	}
+	g.AddEdge("a", "x/1")
+	g.AddEdge("a", "x/2")
+	g.AddEdge("b", "x/2")
+	g.AddEdge("x/1", "x/2")
+
+	collapsed := g.Collapse(func(n *graph.Node) *graph.Node {
+		if strings.HasPrefix(n.ID, "x/") {
+			return &graph.Node{ID: "x"}
+		}
+		return n
+	})
+
+	var actualEdges []string
+	for _, e := range collapsed.Edges() {
+		actualEdges = append(actualEdges, e.From.ID+" -> "+e.To.ID)
+	}
+	if diff := cmp.Diff([]string{"a -> x", "b -> x"}, actualEdges); diff != "" {
+		t.Error(test.Mismatch("expected edges: ", diff))
+	}
+}
+
+func TestVisible_WithStubs(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	moduleDir, err := filepath.Abs(filepath.Join("..", "primitives", "testdata", "build-for-module", "with-third-party-modules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := primitives.BuildForModule("github.com/fake/fake", moduleDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		resolution    internal.Resolution
+		opts          []graph.VisibleOption
+		expectedEdges []string
+	}{
+		"hidden": {
+			resolution:    internal.PackageResolution,
+			expectedEdges: []string{"a -> b"},
+		},
+		"packages": {
+			resolution: internal.PackageResolution,
+			opts:       []graph.VisibleOption{graph.WithStubs(true)},
+			expectedEdges: []string{
+				"a -> b",
+				"a -> fmt",
+				"a -> github.com/fake/ext",
+				"b -> github.com/fake/other/sub",
+				"b -> strings",
+			},
+		},
+		"packages by prefix": {
+			resolution: internal.PackageResolution,
+			opts: []graph.VisibleOption{
+				graph.WithStubs(true),
+				graph.WithStubPrefixes([]string{"github.com/fake/", "str"}),
+			},
+			expectedEdges: []string{
+				"a -> b",
+				"a -> github.com/fake/ext",
+				"b -> github.com/fake/other/sub",
+				"b -> strings",
+			},
+		},
+		"files collapsed to modules": {
+			resolution: internal.FileResolution,
+			opts: []graph.VisibleOption{
+				graph.WithStubs(true),
+				graph.WithCollapsedStubs(true),
+			},
+			expectedEdges: []string{
+				"a.go -> b.go",
+				"a.go -> github.com/fake/ext@v1.0.0",
+				"a.go -> std",
+				"a2.go -> github.com/fake/ext@v1.0.0",
+				"b.go -> github.com/fake/other@v1.2.0",
+				"b.go -> std",
+			},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.Visible(pkgs, testCase.resolution, testCase.opts...)
+
+		var actualEdges []string
+		for _, e := range g.Edges() {
+			actualEdges = append(actualEdges, e.From.Label()+" -> "+e.To.Label())
+		}
+		if diff := cmp.Diff(testCase.expectedEdges, actualEdges, opts); diff != "" {
+			t.Error(desc, test.Mismatch(": expected edges: ", diff))
+		}
+	}
+}
+
+func TestWithAllStubs(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	moduleDir, err := filepath.Abs(filepath.Join("..", "primitives", "testdata", "build-for-module", "with-third-party-modules"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pkgs, err := primitives.BuildForModule("github.com/fake/fake", moduleDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := map[string]struct {
+		opts          []graph.VisibleOption
+		expectedEdges []string
+	}{
+		"every package": {
+			expectedEdges: []string{
+				"a -> b",
+				"a -> fmt",
+				"a -> github.com/fake/ext",
+				"b -> github.com/fake/other/sub",
+				"b -> strings",
+			},
+		},
+		"packages by prefix": {
+			opts: []graph.VisibleOption{
+				graph.WithStubPrefixes([]string{"github.com/fake/", "str"}),
+			},
+			expectedEdges: []string{
+				"a -> b",
+				"a -> github.com/fake/ext",
+				"b -> github.com/fake/other/sub",
+				"b -> strings",
+			},
+		},
+		"packages collapsed to modules": {
+			opts: []graph.VisibleOption{
+				graph.WithCollapsedStubs(true),
+			},
+			expectedEdges: []string{
+				"a -> b",
+				"a -> github.com/fake/ext@v1.0.0",
+				"a -> std",
+				"b -> github.com/fake/other@v1.2.0",
+				"b -> std",
+			},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.WithAllStubs(pkgs, internal.PackageResolution, testCase.opts...)
+
+		var actualEdges []string
+		for _, e := range g.Edges() {
+			actualEdges = append(actualEdges, e.From.Label()+" -> "+e.To.Label())
+		}
+		if diff := cmp.Diff(testCase.expectedEdges, actualEdges, opts); diff != "" {
+			t.Error(desc, test.Mismatch(": expected edges: ", diff))
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package graph
+
+// StronglyConnectedComponents returns the strongly connected components of
+// the graph using Tarjan's algorithm. Components are returned in reverse
+// topological order and every node belongs to exactly one component.
+func (g *Graph) StronglyConnectedComponents() [][]*Node {
+	var components [][]*Node
+	for _, indices := range g.tarjan(func(int) bool { return true }) {
+		component := make([]*Node, 0, len(indices))
+		for _, i := range indices {
+			component = append(component, g.nodes[i])
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// tarjan returns the strongly connected components, as node indices, of the
+// subgraph induced by the nodes for which include returns true
+func (g *Graph) tarjan(include func(int) bool) [][]int {
+	// index and lowLink are offset by one so the zero value means unvisited
+	index := make([]int, len(g.nodes))
+	lowLink := make([]int, len(g.nodes))
+	onStack := make([]bool, len(g.nodes))
+	var stack []int
+	var components [][]int
+
+	// an explicit call stack keeps deep graphs from exhausting the goroutine stack
+	type frame struct {
+		node     int
+		nextSucc int
+	}
+	nextIndex := 1
+	visit := func(v int) {
+		index[v] = nextIndex
+		lowLink[v] = nextIndex
+		nextIndex++
+		stack = append(stack, v)
+		onStack[v] = true
+	}
+
+	for root := range g.nodes {
+		if index[root] != 0 || !include(root) {
+			continue
+		}
+		visit(root)
+		callStack := []frame{{node: root}}
+		for len(callStack) > 0 {
+			top := &callStack[len(callStack)-1]
+			v := top.node
+			if top.nextSucc < len(g.succ[v]) {
+				w := g.succ[v][top.nextSucc]
+				top.nextSucc++
+				if !include(w) {
+					continue
+				}
+				if index[w] == 0 {
+					visit(w)
+					callStack = append(callStack, frame{node: w})
+					continue
+				}
+				if onStack[w] {
+					lowLink[v] = min(lowLink[v], index[w])
+				}
+				continue
+			}
+
+			callStack = callStack[:len(callStack)-1]
+			if len(callStack) > 0 {
+				parent := callStack[len(callStack)-1].node
+				lowLink[parent] = min(lowLink[parent], lowLink[v])
+			}
+			if lowLink[v] != index[v] {
+				continue
+			}
+
+			var component []int
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				component = append(component, w)
+				if w == v {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// IsCyclic reports whether the component contains a cycle, either by having
+// more than one node or by having a node which depends on itself
+func (g *Graph) IsCyclic(component []*Node) bool {
+	if len(component) > 1 {
+		return true
+	}
+	if len(component) == 0 {
+		return false
+	}
+	return g.Edge(component[0].ID, component[0].ID) != nil
+}
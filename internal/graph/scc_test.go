@@ -0,0 +1,94 @@
+package graph_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestGraph_StronglyConnectedComponents(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	testCases := map[string]struct {
+		nodes              []string
+		edges              [][2]string
+		expectedComponents []string
+		expectedCyclic     []string
+	}{
+		"no edges": {
+			nodes:              []string{"a", "b", "c"},
+			expectedComponents: []string{"a", "b", "c"},
+		},
+		"self loop": {
+			nodes:              []string{"a", "b"},
+			edges:              [][2]string{{"a", "a"}, {"a", "b"}},
+			expectedComponents: []string{"a", "b"},
+			expectedCyclic:     []string{"a"},
+		},
+		"direct cycle": {
+			nodes:              []string{"main", "a", "b"},
+			edges:              [][2]string{{"main", "a"}, {"a", "b"}, {"b", "a"}},
+			expectedComponents: []string{"a,b", "main"},
+			expectedCyclic:     []string{"a,b"},
+		},
+		"transitive cycle": {
+			nodes:              []string{"main", "a", "b", "c"},
+			edges:              [][2]string{{"main", "a"}, {"a", "b"}, {"b", "c"}, {"c", "a"}},
+			expectedComponents: []string{"a,b,c", "main"},
+			expectedCyclic:     []string{"a,b,c"},
+		},
+		"independent cycles": {
+			nodes: []string{"main", "a", "b", "c", "d"},
+			edges: [][2]string{
+				{"main", "a"}, {"main", "c"},
+				{"a", "b"}, {"b", "a"},
+				{"c", "d"}, {"d", "c"},
+				{"b", "c"},
+			},
+			expectedComponents: []string{"a,b", "c,d", "main"},
+			expectedCyclic:     []string{"a,b", "c,d"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		g := graph.New()
+		for _, id := range testCase.nodes {
+			g.AddNode(&graph.Node{ID: id})
+		}
+		for _, e := range testCase.edges {
+			g.AddEdge(e[0], e[1])
+		}
+
+		var actualComponents []string
+		var actualCyclic []string
+		for _, component := range g.StronglyConnectedComponents() {
+			ids := make([]string, 0, len(component))
+			for _, node := range component {
+				ids = append(ids, node.ID)
+			}
+			slices.Sort(ids)
+			actualComponents = append(actualComponents, strings.Join(ids, ","))
+			if g.IsCyclic(component) {
+				actualCyclic = append(actualCyclic, strings.Join(ids, ","))
+			}
+		}
+
+		if diff := cmp.Diff(testCase.expectedComponents, actualComponents, opts); diff != "" {
+			t.Error(desc, test.Mismatch(": expected components: ", diff))
+		}
+		if diff := cmp.Diff(testCase.expectedCyclic, actualCyclic, opts); diff != "" {
+			t.Error(desc, test.Mismatch(": expected cyclic components: ", diff))
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package graphml
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+const (
+	namespace = "http://graphml.graphdrawing.org/xmlns"
+
+	labelKey         = "label"
+	weightKey        = "weight"
+	edgeInCycleKey   = "edgeInImportCycle"
+	edgeInCycleTitle = "inImportCycle"
+	suggestedCutKey  = "suggestedCut"
+)
+
+type document struct {
+	XMLName xml.Name `xml:"graphml"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	Keys    []key    `xml:"key"`
+	Graph   graphDef `xml:"graph"`
+}
+
+type key struct {
+	ID       string `xml:"id,attr"`
+	For      string `xml:"for,attr"`
+	AttrName string `xml:"attr.name,attr"`
+	AttrType string `xml:"attr.type,attr"`
+}
+
+type graphDef struct {
+	ID          string `xml:"id,attr"`
+	EdgeDefault string `xml:"edgedefault,attr"`
+	Nodes       []node `xml:"node"`
+	Edges       []edge `xml:"edge"`
+}
+
+type node struct {
+	ID   string `xml:"id,attr"`
+	Data []data `xml:"data"`
+}
+
+type edge struct {
+	ID     string `xml:"id,attr"`
+	Source string `xml:"source,attr"`
+	Target string `xml:"target,attr"`
+	Data   []data `xml:"data"`
+}
+
+type data struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
+
+// Marshal serializes the dependency graph, including stub packages and
+// files unless restricted by WithStubPrefixes or merged by
+// WithCollapsedStubs, as GraphML
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{
+		resolution: internal.FileResolution,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	g := graph.WithAllStubs(
+		pkgs,
+		options.resolution,
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	)
+
+	doc := &document{
+		XMLNS: namespace,
+		Keys: []key{
+			{ID: labelKey, For: "node", AttrName: labelKey, AttrType: string(graph.StringAttribute)},
+		},
+		Graph: graphDef{
+			ID:          modulePath,
+			EdgeDefault: "directed",
+		},
+	}
+	for _, attr := range graph.NodeAttributes {
+		doc.Keys = append(doc.Keys, key{
+			ID:       attr.Name,
+			For:      "node",
+			AttrName: attr.Name,
+			AttrType: attributeType(attr.Type),
+		})
+	}
+	doc.Keys = append(
+		doc.Keys,
+		key{ID: weightKey, For: "edge", AttrName: weightKey, AttrType: "int"},
+		key{ID: edgeInCycleKey, For: "edge", AttrName: edgeInCycleTitle, AttrType: "boolean"},
+		key{ID: suggestedCutKey, For: "edge", AttrName: suggestedCutKey, AttrType: "boolean"},
+	)
+
+	nodeIDs := make(map[string]string)
+	for i, n := range g.Nodes() {
+		nodeIDs[n.ID] = "n" + strconv.Itoa(i)
+		def := node{
+			ID:   nodeIDs[n.ID],
+			Data: []data{{Key: labelKey, Value: n.Label()}},
+		}
+		for _, attr := range graph.NodeAttributes {
+			def.Data = append(def.Data, data{Key: attr.Name, Value: attr.Value(n)})
+		}
+		doc.Graph.Nodes = append(doc.Graph.Nodes, def)
+	}
+	for i, e := range g.Edges() {
+		doc.Graph.Edges = append(doc.Graph.Edges, edge{
+			ID:     "e" + strconv.Itoa(i),
+			Source: nodeIDs[e.From.ID],
+			Target: nodeIDs[e.To.ID],
+			Data: []data{
+				{Key: weightKey, Value: strconv.Itoa(e.Weight())},
+				{Key: edgeInCycleKey, Value: strconv.FormatBool(e.InImportCycle())},
+				{Key: suggestedCutKey, Value: strconv.FormatBool(options.highlightedEdges[e.ID()])},
+			},
+		})
+	}
+
+	output, err := xml.MarshalIndent(doc, "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), output...), nil
+}
+
+func attributeType(typ graph.AttributeType) string {
+	if typ == graph.IntegerAttribute {
+		return "int"
+	}
+	return string(typ)
+}
@@ -0,0 +1,43 @@
+(function () {
+	"use strict";
+
+	const pollInterval = 2000;
+
+	const rebuild = document.getElementById("rebuild");
+	const status = document.getElementById("status");
+
+	function showStatus(s) {
+		if (s.version !== live.version) {
+			window.location.reload();
+			return;
+		}
+		status.textContent = s.error ? "rebuild failed: " + s.error : "";
+		status.title = s.error || "";
+	}
+
+	function poll() {
+		fetch(live.statusURL, { cache: "no-store" })
+			.then((response) => response.json())
+			.then(showStatus)
+			.catch(() => {
+				status.textContent = "server unreachable";
+			})
+			.finally(() => window.setTimeout(poll, pollInterval));
+	}
+
+	rebuild.addEventListener("click", () => {
+		rebuild.disabled = true;
+		status.textContent = "rebuilding";
+		fetch(live.rebuildURL, { method: "POST" })
+			.then((response) => response.json())
+			.then(showStatus)
+			.catch(() => {
+				status.textContent = "server unreachable";
+			})
+			.finally(() => {
+				rebuild.disabled = false;
+			});
+	});
+
+	window.setTimeout(poll, pollInterval);
+})();
@@ -0,0 +1,731 @@
+(function () {
+	"use strict";
+
+	const svgNS = "http://www.w3.org/2000/svg";
+	const nodeHeight = 28;
+	const springLength = 140;
+
+	const svg = document.getElementById("graph");
+	const viewport = document.getElementById("viewport");
+	const edgeLayer = document.getElementById("edges");
+	const nodeLayer = document.getElementById("nodes");
+	const panel = document.getElementById("panel");
+	const search = document.getElementById("search");
+	const cycles = document.getElementById("cycles");
+
+	const packages = new Map(report.packages.map((p) => [p.id, p]));
+	const files = new Map(report.files.map((f) => [f.id, f]));
+
+	const expanded = new Set();
+	if (report.resolution === "file") {
+		report.packages.forEach((p) => expanded.add(p.id));
+	}
+
+	const positions = new Map();
+	const view = { x: 0, y: 0, scale: 1 };
+	const state = {
+		nodes: [],
+		edges: [],
+		nodeElems: new Map(),
+		edgeElems: new Map(),
+		selectedNode: null,
+		selectedEdge: null,
+		matches: new Set(),
+	};
+
+	// graph construction
+
+	function isExpanded(pkgID) {
+		return expanded.has(pkgID) && packages.get(pkgID).files.length > 0;
+	}
+
+	function representative(fileID) {
+		const pkgID = files.get(fileID).package;
+		return isExpanded(pkgID) ? fileID : pkgID;
+	}
+
+	function buildGraph() {
+		const nodes = [];
+		report.packages.forEach((p) => {
+			if (!isExpanded(p.id)) {
+				nodes.push({
+					id: p.id,
+					kind: "package",
+					label: p.label,
+					title: p.importPath,
+					isTest: p.isTest,
+					inImportCycle: p.inImportCycle,
+					isStub: p.isStub,
+					isStdlib: p.isStdlib,
+					pkg: p.id,
+				});
+				return;
+			}
+			p.files.forEach((id) => {
+				const f = files.get(id);
+				nodes.push({
+					id: f.id,
+					kind: "file",
+					label: f.label,
+					title: p.importPath + "/" + f.label,
+					isTest: f.isTest,
+					inImportCycle: f.inImportCycle,
+					pkg: p.id,
+				});
+			});
+		});
+
+		const edges = new Map();
+		const add = (from, to, e) => {
+			const key = from + "\u0000" + to;
+			let edge = edges.get(key);
+			if (!edge) {
+				const target = packages.get(to);
+				edge = {
+					id: key,
+					from: from,
+					to: to,
+					weight: 0,
+					// test only if every aggregated edge leaves a test node
+					isTest: e.isTest,
+					isStub: target !== undefined && target.isStub,
+					isStdlib: target !== undefined && target.isStdlib,
+					inImportCycle: false,
+					suggestedCut: false,
+					decls: new Set(),
+					imports: new Set(),
+				};
+				edges.set(key, edge);
+			}
+			edge.weight += e.weight;
+			edge.isTest = edge.isTest && e.isTest;
+			edge.inImportCycle = edge.inImportCycle || e.inImportCycle;
+			edge.suggestedCut = edge.suggestedCut || e.suggestedCut;
+			e.decls.forEach((d) => edge.decls.add(d));
+			e.imports.forEach((i) => edge.imports.add(i));
+		};
+		report.packageEdges.forEach((e) => {
+			if (!packages.has(e.from) || !packages.has(e.to)) {
+				return;
+			}
+			if (isExpanded(e.from) || isExpanded(e.to)) {
+				return;
+			}
+			add(e.from, e.to, e);
+		});
+		report.fileEdges.forEach((e) => {
+			// files import stub packages, which have no files, directly
+			if (!files.has(e.from) || !(files.has(e.to) || packages.has(e.to))) {
+				return;
+			}
+			const from = representative(e.from);
+			const to = files.has(e.to) ? representative(e.to) : e.to;
+			// edges between collapsed packages are taken from the package graph
+			if (packages.has(from) && packages.has(to)) {
+				return;
+			}
+			add(from, to, e);
+		});
+
+		state.nodes = nodes;
+		state.edges = Array.from(edges.values()).map((e) => {
+			e.decls = Array.from(e.decls).sort();
+			e.imports = Array.from(e.imports).sort();
+			return e;
+		});
+	}
+
+	// layout
+
+	function nodeWidth(node) {
+		return Math.max(60, node.label.length * 7 + 24);
+	}
+
+	function hash(s) {
+		let h = 0;
+		for (let i = 0; i < s.length; i++) {
+			h = (h * 31 + s.charCodeAt(i)) | 0;
+		}
+		return Math.abs(h);
+	}
+
+	function placeNewNodes() {
+		const radius = springLength * Math.sqrt(state.nodes.length);
+		state.nodes.forEach((node) => {
+			if (positions.has(node.id)) {
+				return;
+			}
+			const h = hash(node.id);
+			const angle = (h % 3600) / 3600 * 2 * Math.PI;
+			let center = { x: 0, y: 0 };
+			let distance = radius * ((h % 1000) / 1000);
+			if (node.kind === "file" && positions.has(node.pkg)) {
+				// expanding a package places its files around it
+				center = positions.get(node.pkg);
+				distance = springLength / 2;
+			} else if (node.kind === "package") {
+				// collapsing a package places it at the centroid of its files
+				const placed = packages.get(node.id).files.filter((id) => positions.has(id));
+				if (placed.length > 0) {
+					center = placed.reduce((c, id) => {
+						const p = positions.get(id);
+						return { x: c.x + p.x / placed.length, y: c.y + p.y / placed.length };
+					}, { x: 0, y: 0 });
+					distance = 0;
+				}
+			}
+			positions.set(node.id, {
+				x: center.x + Math.cos(angle) * distance,
+				y: center.y + Math.sin(angle) * distance,
+			});
+		});
+	}
+
+	// layout runs a Fruchterman-Reingold force simulation, the number of
+	// iterations shrinks as the graph grows to keep large graphs responsive
+	function layout() {
+		placeNewNodes();
+		const nodes = state.nodes;
+		const n = nodes.length;
+		if (n === 0) {
+			return;
+		}
+		const index = new Map(nodes.map((node, i) => [node.id, i]));
+		const pos = nodes.map((node) => positions.get(node.id));
+		const links = state.edges
+			.filter((e) => e.from !== e.to)
+			.map((e) => [index.get(e.from), index.get(e.to)]);
+		const k = springLength;
+		const iterations = Math.max(30, Math.min(300, Math.floor(3e7 / (n * n))));
+		let temperature = k * Math.sqrt(n);
+
+		for (let iter = 0; iter < iterations; iter++) {
+			const disp = nodes.map(() => ({ x: 0, y: 0 }));
+			for (let i = 0; i < n; i++) {
+				for (let j = i + 1; j < n; j++) {
+					let dx = pos[i].x - pos[j].x;
+					let dy = pos[i].y - pos[j].y;
+					let d2 = dx * dx + dy * dy;
+					if (d2 < 0.01) {
+						dx = (i - j) * 0.1;
+						dy = 0.1;
+						d2 = dx * dx + dy * dy;
+					}
+					const force = (k * k) / d2;
+					disp[i].x += dx * force;
+					disp[i].y += dy * force;
+					disp[j].x -= dx * force;
+					disp[j].y -= dy * force;
+				}
+			}
+			links.forEach(([a, b]) => {
+				const dx = pos[a].x - pos[b].x;
+				const dy = pos[a].y - pos[b].y;
+				const d = Math.sqrt(dx * dx + dy * dy) || 0.1;
+				const force = d / k;
+				disp[a].x -= dx * force;
+				disp[a].y -= dy * force;
+				disp[b].x += dx * force;
+				disp[b].y += dy * force;
+			});
+			for (let i = 0; i < n; i++) {
+				// gravity keeps disconnected components on screen
+				disp[i].x -= pos[i].x * 0.01;
+				disp[i].y -= pos[i].y * 0.01;
+				const d = Math.sqrt(disp[i].x * disp[i].x + disp[i].y * disp[i].y) || 1;
+				const step = Math.min(d, temperature);
+				pos[i].x += (disp[i].x / d) * step;
+				pos[i].y += (disp[i].y / d) * step;
+			}
+			temperature = Math.max(1, temperature * 0.95);
+		}
+	}
+
+	// rendering
+
+	function el(name, attrs, parent) {
+		const elem = document.createElementNS(svgNS, name);
+		Object.entries(attrs || {}).forEach(([k, v]) => elem.setAttribute(k, v));
+		if (parent) {
+			parent.appendChild(elem);
+		}
+		return elem;
+	}
+
+	function halfPalette(item) {
+		if (item.isStub) {
+			return item.isStdlib ? report.palette.stdlib : report.palette.external;
+		}
+		if (item.isTest) {
+			return item.inImportCycle ? report.palette.testCycle : report.palette.test;
+		}
+		return item.inImportCycle ? report.palette.cycle : report.palette.base;
+	}
+
+	function arrowMarker(edge) {
+		if (edge.isStub) {
+			return edge.isStdlib ? "url(#arrow-stdlib)" : "url(#arrow-external)";
+		}
+		if (edge.isTest) {
+			return edge.inImportCycle ? "url(#arrow-test-cycle)" : "url(#arrow-test)";
+		}
+		return edge.inImportCycle ? "url(#arrow-cycle)" : "url(#arrow-base)";
+	}
+
+	function colors(node) {
+		const half = halfPalette(node);
+		if (node.kind === "package") {
+			return { fill: half.packageBackground, text: half.packageName };
+		}
+		return { fill: half.fileBackground, text: half.fileName };
+	}
+
+	function createMarkers() {
+		const defs = svg.querySelector("defs");
+		[
+			["arrow-base", report.palette.base.importArrow],
+			["arrow-cycle", report.palette.cycle.importArrow],
+			["arrow-test", report.palette.test.importArrow],
+			["arrow-test-cycle", report.palette.testCycle.importArrow],
+			["arrow-external", report.palette.external.importArrow],
+			["arrow-stdlib", report.palette.stdlib.importArrow],
+		]
+			.forEach(([id, color]) => {
+				const marker = el("marker", {
+					id: id,
+					viewBox: "0 0 10 10",
+					refX: "10",
+					refY: "5",
+					markerWidth: "8",
+					markerHeight: "8",
+					markerUnits: "userSpaceOnUse",
+					orient: "auto",
+				}, defs);
+				el("path", { d: "M 0 0 L 10 5 L 0 10 z", fill: color }, marker);
+			});
+	}
+
+	function render() {
+		edgeLayer.replaceChildren();
+		nodeLayer.replaceChildren();
+		state.nodeElems.clear();
+		state.edgeElems.clear();
+
+		state.edges.forEach((edge) => {
+			const half = halfPalette(edge);
+			const path = el("path", {
+				class: edge.suggestedCut ? "edge cut" : "edge",
+				stroke: half.importArrow,
+				"stroke-width": String(1 + Math.log2(1 + edge.weight)),
+				"marker-end": arrowMarker(edge),
+			}, edgeLayer);
+			el("title", {}, path).textContent =
+				labelFor(edge.from) + " → " + labelFor(edge.to) + " (weight " + edge.weight + ")";
+			path.addEventListener("click", (event) => {
+				event.stopPropagation();
+				selectEdge(edge);
+			});
+			state.edgeElems.set(edge.id, path);
+		});
+
+		state.nodes.forEach((node) => {
+			const c = colors(node);
+			const width = nodeWidth(node);
+			const g = el("g", { class: "node " + node.kind + (node.isStub ? " stub" : "") }, nodeLayer);
+			el("rect", {
+				x: String(-width / 2),
+				y: String(-nodeHeight / 2),
+				width: String(width),
+				height: String(nodeHeight),
+				rx: node.kind === "package" ? "4" : "12",
+				fill: c.fill,
+				stroke: c.text,
+			}, g);
+			el("text", { fill: c.text }, g).textContent = node.label;
+			el("title", {}, g).textContent = node.title;
+			g.addEventListener("mousedown", (event) => startNodeDrag(event, node));
+			g.addEventListener("dblclick", (event) => {
+				event.stopPropagation();
+				toggle(node);
+			});
+			state.nodeElems.set(node.id, g);
+		});
+
+		updatePositions();
+		applyClasses();
+	}
+
+	function boundaryPoint(node, toward) {
+		const p = positions.get(node.id);
+		const dx = toward.x - p.x;
+		const dy = toward.y - p.y;
+		if (dx === 0 && dy === 0) {
+			return p;
+		}
+		const hw = nodeWidth(node) / 2;
+		const hh = nodeHeight / 2;
+		const scale = 1 / Math.max(Math.abs(dx) / hw, Math.abs(dy) / hh);
+		return { x: p.x + dx * scale, y: p.y + dy * scale };
+	}
+
+	function updatePositions() {
+		const byID = new Map(state.nodes.map((node) => [node.id, node]));
+		state.nodes.forEach((node) => {
+			const p = positions.get(node.id);
+			state.nodeElems.get(node.id).setAttribute("transform", "translate(" + p.x + "," + p.y + ")");
+		});
+		state.edges.forEach((edge) => {
+			const from = byID.get(edge.from);
+			const to = byID.get(edge.to);
+			let d;
+			if (from === to) {
+				const p = positions.get(from.id);
+				const hw = nodeWidth(from) / 2;
+				d = "M " + (p.x + hw) + " " + p.y +
+					" C " + (p.x + hw + 40) + " " + (p.y - 40) + ", " +
+					(p.x + hw + 40) + " " + (p.y + 40) + ", " +
+					(p.x + hw) + " " + (p.y + nodeHeight / 4);
+			} else {
+				const a = boundaryPoint(from, positions.get(to.id));
+				const b = boundaryPoint(to, positions.get(from.id));
+				d = "M " + a.x + " " + a.y + " L " + b.x + " " + b.y;
+			}
+			state.edgeElems.get(edge.id).setAttribute("d", d);
+		});
+	}
+
+	function applyClasses() {
+		let visibleNodes = null;
+		let visibleEdges = null;
+		if (state.selectedNode) {
+			visibleNodes = new Set([state.selectedNode]);
+			visibleEdges = new Set();
+			state.edges.forEach((edge) => {
+				if (edge.from === state.selectedNode || edge.to === state.selectedNode) {
+					visibleNodes.add(edge.from);
+					visibleNodes.add(edge.to);
+					visibleEdges.add(edge.id);
+				}
+			});
+		} else if (cycles.checked) {
+			visibleNodes = new Set(state.nodes.filter((n) => n.inImportCycle).map((n) => n.id));
+			visibleEdges = new Set(state.edges.filter((e) => e.inImportCycle).map((e) => e.id));
+		}
+		state.nodeElems.forEach((g, id) => {
+			g.classList.toggle("dimmed", visibleNodes !== null && !visibleNodes.has(id));
+			g.classList.toggle("match", state.matches.has(id));
+			g.classList.toggle("selected", state.selectedNode === id);
+		});
+		state.edgeElems.forEach((path, id) => {
+			path.classList.toggle("dimmed", visibleEdges !== null && !visibleEdges.has(id));
+			path.classList.toggle("selected", state.selectedEdge === id);
+		});
+	}
+
+	function applyView() {
+		viewport.setAttribute("transform", "translate(" + view.x + "," + view.y + ") scale(" + view.scale + ")");
+	}
+
+	function fit() {
+		if (state.nodes.length === 0) {
+			return;
+		}
+		let minX = Infinity, minY = Infinity, maxX = -Infinity, maxY = -Infinity;
+		state.nodes.forEach((node) => {
+			const p = positions.get(node.id);
+			const hw = nodeWidth(node) / 2;
+			minX = Math.min(minX, p.x - hw);
+			maxX = Math.max(maxX, p.x + hw);
+			minY = Math.min(minY, p.y - nodeHeight / 2);
+			maxY = Math.max(maxY, p.y + nodeHeight / 2);
+		});
+		const rect = svg.getBoundingClientRect();
+		const margin = 40;
+		view.scale = Math.min(
+			2,
+			(rect.width - 2 * margin) / Math.max(1, maxX - minX),
+			(rect.height - 2 * margin) / Math.max(1, maxY - minY),
+		);
+		view.x = rect.width / 2 - ((minX + maxX) / 2) * view.scale;
+		view.y = rect.height / 2 - ((minY + maxY) / 2) * view.scale;
+		applyView();
+	}
+
+	function centerOn(id) {
+		const p = positions.get(id);
+		const rect = svg.getBoundingClientRect();
+		view.x = rect.width / 2 - p.x * view.scale;
+		view.y = rect.height / 2 - p.y * view.scale;
+		applyView();
+	}
+
+	function refresh() {
+		buildGraph();
+		const ids = new Set(state.nodes.map((n) => n.id));
+		if (state.selectedNode && !ids.has(state.selectedNode)) {
+			state.selectedNode = null;
+		}
+		if (state.selectedEdge && !state.edges.some((e) => e.id === state.selectedEdge)) {
+			state.selectedEdge = null;
+		}
+		layout();
+		updateMatches();
+		render();
+	}
+
+	// expanding and collapsing
+
+	function toggle(node) {
+		if (node.kind === "package") {
+			if (packages.get(node.id).files.length === 0) {
+				return;
+			}
+			expanded.add(node.id);
+		} else {
+			expanded.delete(node.pkg);
+		}
+		refresh();
+	}
+
+	// side panel
+
+	function labelFor(id) {
+		if (packages.has(id)) {
+			return packages.get(id).label;
+		}
+		const f = files.get(id);
+		return packages.get(f.package).label + "/" + f.label;
+	}
+
+	function clearPanel(title) {
+		panel.replaceChildren();
+		const h2 = document.createElement("h2");
+		h2.textContent = title;
+		panel.appendChild(h2);
+	}
+
+	function section(title, items, onClick) {
+		const h3 = document.createElement("h3");
+		h3.textContent = title + " (" + items.length + ")";
+		panel.appendChild(h3);
+		const ul = document.createElement("ul");
+		items.forEach((item) => {
+			const li = document.createElement("li");
+			if (onClick) {
+				const a = document.createElement("a");
+				a.textContent = item.label;
+				a.addEventListener("click", () => onClick(item));
+				li.appendChild(a);
+			} else {
+				li.textContent = item;
+			}
+			ul.appendChild(li);
+		});
+		panel.appendChild(ul);
+	}
+
+	function paragraph(text) {
+		const p = document.createElement("p");
+		p.textContent = text;
+		panel.appendChild(p);
+	}
+
+	function showNode(node) {
+		clearPanel(node.label);
+		paragraph(node.title);
+		if (node.isTest) {
+			paragraph(node.kind === "package" ? "External test package" : "Test file");
+		}
+		if (node.isStub) {
+			paragraph(node.isStdlib ? "Standard library" : "Outside of the module");
+		}
+		if (node.inImportCycle) {
+			paragraph("In an import cycle");
+		}
+		if (node.kind === "package") {
+			section("Files", packages.get(node.id).files.map((id) => files.get(id).label));
+		} else {
+			section("Declarations", files.get(node.id).decls);
+		}
+		const edgeItem = (edge, id) => ({ label: labelFor(id) + " (weight " + edge.weight + ")", edge: edge });
+		section(
+			"Imports",
+			state.edges.filter((e) => e.from === node.id).map((e) => edgeItem(e, e.to)),
+			(item) => selectEdge(item.edge),
+		);
+		section(
+			"Imported by",
+			state.edges.filter((e) => e.to === node.id).map((e) => edgeItem(e, e.from)),
+			(item) => selectEdge(item.edge),
+		);
+	}
+
+	function showEdge(edge) {
+		clearPanel(labelFor(edge.from) + " → " + labelFor(edge.to));
+		paragraph(
+			"Weight " + edge.weight +
+			(edge.inImportCycle ? ", in an import cycle" : "") +
+			(edge.suggestedCut ? ", suggested cut" : "")
+		);
+		section("Imports", edge.imports);
+		section("Referenced declarations", edge.decls);
+	}
+
+	function selectNode(node) {
+		state.selectedEdge = null;
+		if (state.selectedNode === node.id) {
+			state.selectedNode = null;
+		} else {
+			state.selectedNode = node.id;
+			showNode(node);
+		}
+		applyClasses();
+	}
+
+	function selectEdge(edge) {
+		state.selectedNode = null;
+		state.selectedEdge = edge.id;
+		showEdge(edge);
+		applyClasses();
+	}
+
+	// search
+
+	function updateMatches() {
+		state.matches.clear();
+		const query = search.value.trim().toLowerCase();
+		if (query === "") {
+			return;
+		}
+		state.nodes.forEach((node) => {
+			let match = node.title.toLowerCase().includes(query);
+			if (!match && node.kind === "package") {
+				match = packages.get(node.id).files.some((id) => files.get(id).label.toLowerCase().includes(query));
+			}
+			if (match) {
+				state.matches.add(node.id);
+			}
+		});
+	}
+
+	search.addEventListener("input", () => {
+		updateMatches();
+		applyClasses();
+	});
+	search.addEventListener("keydown", (event) => {
+		if (event.key !== "Enter") {
+			return;
+		}
+		// expand packages whose files match so the files themselves are shown
+		const query = search.value.trim().toLowerCase();
+		let changed = false;
+		report.files.forEach((f) => {
+			if (query !== "" && f.label.toLowerCase().includes(query) && !expanded.has(f.package)) {
+				expanded.add(f.package);
+				changed = true;
+			}
+		});
+		if (changed) {
+			refresh();
+		} else {
+			updateMatches();
+			applyClasses();
+		}
+		const first = state.nodes.find((n) => state.matches.has(n.id));
+		if (first) {
+			centerOn(first.id);
+		}
+	});
+
+	// zoom, pan and dragging
+
+	let drag = null;
+
+	function toGraph(event) {
+		const rect = svg.getBoundingClientRect();
+		return {
+			x: (event.clientX - rect.left - view.x) / view.scale,
+			y: (event.clientY - rect.top - view.y) / view.scale,
+		};
+	}
+
+	function startNodeDrag(event, node) {
+		event.stopPropagation();
+		drag = { kind: "node", node: node, startX: event.clientX, startY: event.clientY, moved: false };
+	}
+
+	svg.addEventListener("mousedown", (event) => {
+		drag = { kind: "pan", startX: event.clientX, startY: event.clientY, viewX: view.x, viewY: view.y, moved: false };
+		svg.classList.add("panning");
+	});
+
+	window.addEventListener("mousemove", (event) => {
+		if (!drag) {
+			return;
+		}
+		const dx = event.clientX - drag.startX;
+		const dy = event.clientY - drag.startY;
+		if (Math.abs(dx) + Math.abs(dy) > 3) {
+			drag.moved = true;
+		}
+		if (drag.kind === "pan") {
+			view.x = drag.viewX + dx;
+			view.y = drag.viewY + dy;
+			applyView();
+			return;
+		}
+		if (drag.moved) {
+			positions.set(drag.node.id, toGraph(event));
+			updatePositions();
+		}
+	});
+
+	window.addEventListener("mouseup", () => {
+		if (!drag) {
+			return;
+		}
+		if (!drag.moved) {
+			if (drag.kind === "node") {
+				selectNode(drag.node);
+			} else {
+				state.selectedNode = null;
+				state.selectedEdge = null;
+				applyClasses();
+			}
+		}
+		svg.classList.remove("panning");
+		drag = null;
+	});
+
+	svg.addEventListener("wheel", (event) => {
+		event.preventDefault();
+		const rect = svg.getBoundingClientRect();
+		const cx = event.clientX - rect.left;
+		const cy = event.clientY - rect.top;
+		const factor = Math.exp(-event.deltaY * 0.001);
+		const scale = Math.min(8, Math.max(0.05, view.scale * factor));
+		view.x = cx - ((cx - view.x) * scale) / view.scale;
+		view.y = cy - ((cy - view.y) * scale) / view.scale;
+		view.scale = scale;
+		applyView();
+	}, { passive: false });
+
+	// controls
+
+	document.getElementById("expand-all").addEventListener("click", () => {
+		report.packages.forEach((p) => expanded.add(p.id));
+		refresh();
+		fit();
+	});
+	document.getElementById("collapse-all").addEventListener("click", () => {
+		expanded.clear();
+		refresh();
+		fit();
+	});
+	document.getElementById("fit").addEventListener("click", fit);
+	cycles.addEventListener("change", applyClasses);
+
+	createMarkers();
+	refresh();
+	fit();
+})();
@@ -0,0 +1,269 @@
+package html
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"html/template"
+	"slices"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+//go:embed assets
+var assets embed.FS
+
+var reportTemplate = template.Must(template.ParseFS(assets, "assets/report.html.tmpl"))
+
+type report struct {
+	Module       string     `json:"module"`
+	Resolution   string     `json:"resolution"`
+	Palette      palette    `json:"palette"`
+	Packages     []*pkgNode `json:"packages"`
+	Files        []*file    `json:"files"`
+	PackageEdges []*edge    `json:"packageEdges"`
+	FileEdges    []*edge    `json:"fileEdges"`
+}
+
+type palette struct {
+	Base      halfPalette `json:"base"`
+	Cycle     halfPalette `json:"cycle"`
+	Test      halfPalette `json:"test"`
+	TestCycle halfPalette `json:"testCycle"`
+	External  halfPalette `json:"external"`
+	Stdlib    halfPalette `json:"stdlib"`
+}
+
+type halfPalette struct {
+	PackageName       string `json:"packageName"`
+	PackageBackground string `json:"packageBackground"`
+	FileName          string `json:"fileName"`
+	FileBackground    string `json:"fileBackground"`
+	ImportArrow       string `json:"importArrow"`
+}
+
+type pkgNode struct {
+	ID            string   `json:"id"`
+	Label         string   `json:"label"`
+	ImportPath    string   `json:"importPath"`
+	IsTest        bool     `json:"isTest"`
+	InImportCycle bool     `json:"inImportCycle"`
+	IsStub        bool     `json:"isStub"`
+	IsStdlib      bool     `json:"isStdlib"`
+	Files         []string `json:"files"`
+}
+
+type file struct {
+	ID            string   `json:"id"`
+	Label         string   `json:"label"`
+	Package       string   `json:"package"`
+	IsTest        bool     `json:"isTest"`
+	InImportCycle bool     `json:"inImportCycle"`
+	Decls         []string `json:"decls"`
+}
+
+type edge struct {
+	From          string   `json:"from"`
+	To            string   `json:"to"`
+	Weight        int      `json:"weight"`
+	IsTest        bool     `json:"isTest"`
+	InImportCycle bool     `json:"inImportCycle"`
+	SuggestedCut  bool     `json:"suggestedCut"`
+	Decls         []string `json:"decls"`
+	Imports       []string `json:"imports"`
+}
+
+type page struct {
+	Title  string
+	Style  template.CSS
+	Script template.JS
+	Data   template.JS
+
+	// Live and LiveScript are only set for reports served live
+	Live       template.JS
+	LiveScript template.JS
+}
+
+type live struct {
+	StatusURL  string `json:"statusURL"`
+	RebuildURL string `json:"rebuildURL"`
+	Version    int    `json:"version"`
+}
+
+// Marshal renders the dependency graph as a single self-contained HTML file
+// with an interactive viewer, no network access is required to view it
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{
+		palette:    *color.DefaultPalette,
+		resolution: internal.PackageResolution,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	data, err := json.Marshal(buildReport(modulePath, pkgs, &options))
+	if err != nil {
+		return nil, err
+	}
+	style, err := assets.ReadFile("assets/report.css")
+	if err != nil {
+		return nil, err
+	}
+	script, err := assets.ReadFile("assets/report.js")
+	if err != nil {
+		return nil, err
+	}
+
+	p := page{
+		Title:  modulePath,
+		Style:  template.CSS(style),
+		Script: template.JS(script),
+		Data:   template.JS(data),
+	}
+	if options.live != nil {
+		liveData, err := json.Marshal(live{
+			StatusURL:  options.live.StatusURL,
+			RebuildURL: options.live.RebuildURL,
+			Version:    options.live.Version,
+		})
+		if err != nil {
+			return nil, err
+		}
+		liveScript, err := assets.ReadFile("assets/live.js")
+		if err != nil {
+			return nil, err
+		}
+		p.Live = template.JS(liveData)
+		p.LiveScript = template.JS(liveScript)
+	}
+
+	buf := &bytes.Buffer{}
+	err = reportTemplate.Execute(buf, p)
+	if err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func buildReport(modulePath string, pkgs []*internal.Package, options *options) *report {
+	r := &report{
+		Module:       modulePath,
+		Resolution:   string(options.resolution),
+		Palette:      buildPalette(&options.palette),
+		Packages:     make([]*pkgNode, 0),
+		Files:        make([]*file, 0),
+		PackageEdges: make([]*edge, 0),
+		FileEdges:    make([]*edge, 0),
+	}
+
+	visibleOpts := []graph.VisibleOption{
+		graph.WithStubs(options.stubs),
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	}
+	pkgNodes := make(map[string]*pkgNode)
+	pg := graph.Visible(pkgs, internal.PackageResolution, visibleOpts...)
+	for _, n := range pg.Nodes() {
+		// collapsed stub packages are nodes of their module
+		importPath := n.Label()
+		if n.Package != nil {
+			importPath = n.Package.ImportPath()
+		}
+		node := &pkgNode{
+			ID:            n.ID,
+			Label:         n.Label(),
+			ImportPath:    importPath,
+			IsTest:        n.IsTest(),
+			InImportCycle: n.InImportCycle(),
+			IsStub:        n.IsStub(),
+			IsStdlib:      n.IsStdlib(),
+			Files:         make([]string, 0),
+		}
+		pkgNodes[n.ID] = node
+		r.Packages = append(r.Packages, node)
+	}
+	for _, e := range pg.Edges() {
+		r.PackageEdges = append(r.PackageEdges, buildEdge(e, options.highlightedEdges))
+	}
+
+	fg := graph.Visible(pkgs, internal.FileResolution, visibleOpts...)
+	for _, n := range fg.Nodes() {
+		// stub nodes are packages, or modules, without files
+		if n.IsStub() {
+			continue
+		}
+		node, ok := pkgNodes[n.Package.UID()]
+		if !ok {
+			continue
+		}
+		f := &file{
+			ID:            n.ID,
+			Label:         n.Label(),
+			Package:       node.ID,
+			IsTest:        n.IsTest(),
+			InImportCycle: n.InImportCycle(),
+			Decls:         make([]string, 0, len(n.File.Decls)),
+		}
+		for _, decl := range n.File.Decls {
+			if decl.IsBlank() {
+				continue
+			}
+			f.Decls = append(f.Decls, cycles.DeclName(decl))
+		}
+		slices.Sort(f.Decls)
+		node.Files = append(node.Files, f.ID)
+		r.Files = append(r.Files, f)
+	}
+	for _, e := range fg.Edges() {
+		r.FileEdges = append(r.FileEdges, buildEdge(e, options.highlightedEdges))
+	}
+	return r
+}
+
+func buildEdge(e *graph.Edge, highlightedEdges map[graph.EdgeID]bool) *edge {
+	ed := &edge{
+		From:          e.From.ID,
+		To:            e.To.ID,
+		Weight:        e.Weight(),
+		IsTest:        e.From.IsTest(),
+		InImportCycle: e.InImportCycle(),
+		SuggestedCut:  highlightedEdges[e.ID()],
+		Decls:         make([]string, 0, len(e.Decls)),
+		Imports:       make([]string, 0, len(e.Imports)),
+	}
+	for _, decl := range e.Decls {
+		ed.Decls = append(ed.Decls, cycles.DeclName(decl))
+	}
+	for _, imp := range e.Imports {
+		ed.Imports = append(ed.Imports, imp.Path)
+	}
+	slices.Sort(ed.Decls)
+	ed.Decls = slices.Compact(ed.Decls)
+	slices.Sort(ed.Imports)
+	ed.Imports = slices.Compact(ed.Imports)
+	return ed
+}
+
+func buildPalette(p *color.Palette) palette {
+	return palette{
+		Base:      buildHalfPalette(p.Half(false, false)),
+		Cycle:     buildHalfPalette(p.Half(false, true)),
+		Test:      buildHalfPalette(p.Half(true, false)),
+		TestCycle: buildHalfPalette(p.Half(true, true)),
+		External:  buildHalfPalette(p.Stub(false)),
+		Stdlib:    buildHalfPalette(p.Stub(true)),
+	}
+}
+
+func buildHalfPalette(p *color.HalfPalette) halfPalette {
+	return halfPalette{
+		PackageName:       p.PackageName.Hex(),
+		PackageBackground: p.PackageBackground.Hex(),
+		FileName:          p.FileName.Hex(),
+		FileBackground:    p.FileBackground.Hex(),
+		ImportArrow:       p.ImportArrow.Hex(),
+	}
+}
@@ -0,0 +1,119 @@
+package html
+
+import (
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+type options struct {
+	resolution       internal.Resolution
+	palette          color.Palette
+	highlightedEdges map[graph.EdgeID]bool
+	live             *Live
+	stubs            bool
+	collapseStubs    bool
+	stubPrefixes     []string
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type resolutionOption internal.Resolution
+
+func (opt resolutionOption) apply(opts *options) {
+	opts.resolution = internal.Resolution(opt)
+}
+
+// WithResolution sets the resolution the report opens at, packages can be
+// expanded and collapsed in the report regardless
+func WithResolution(resolution internal.Resolution) Option {
+	return resolutionOption(resolution)
+}
+
+type paletteOption color.Palette
+
+func (opt paletteOption) apply(opts *options) {
+	opts.palette = color.Palette(opt)
+}
+
+func WithPalette(palette color.Palette) Option {
+	return paletteOption(palette)
+}
+
+type highlightedEdgesOption []graph.EdgeID
+
+func (opt highlightedEdgesOption) apply(opts *options) {
+	opts.highlightedEdges = make(map[graph.EdgeID]bool, len(opt))
+	for _, id := range opt {
+		opts.highlightedEdges[id] = true
+	}
+}
+
+// WithHighlightedEdges draws the edges, identified by package or file UIDs,
+// dashed, e.g. to show suggested cuts
+func WithHighlightedEdges(ids []graph.EdgeID) Option {
+	return highlightedEdgesOption(ids)
+}
+
+type stubsOption bool
+
+func (opt stubsOption) apply(opts *options) {
+	opts.stubs = bool(opt)
+}
+
+// WithStubs shows packages of other modules and of the standard library as
+// packages which can't be expanded
+func WithStubs(stubs bool) Option {
+	return stubsOption(stubs)
+}
+
+type collapsedStubsOption bool
+
+func (opt collapsedStubsOption) apply(opts *options) {
+	opts.collapseStubs = bool(opt)
+}
+
+// WithCollapsedStubs shows a node per module rather than per package of
+// other modules and of the standard library, see WithStubs
+func WithCollapsedStubs(collapse bool) Option {
+	return collapsedStubsOption(collapse)
+}
+
+type stubPrefixesOption []string
+
+func (opt stubPrefixesOption) apply(opts *options) {
+	opts.stubPrefixes = []string(opt)
+}
+
+// WithStubPrefixes only shows the packages of other modules and of the
+// standard library whose import path starts with one of the prefixes, see
+// WithStubs
+func WithStubPrefixes(prefixes []string) Option {
+	return stubPrefixesOption(prefixes)
+}
+
+// Live points the report at the server it's served by, see WithLive
+type Live struct {
+	// StatusURL answers the version of the served build and the error of
+	// the last rebuild, if any, as JSON
+	StatusURL string
+	// RebuildURL rebuilds the served module when posted to
+	RebuildURL string
+	// Version is the version of the build the report shows
+	Version int
+}
+
+type liveOption Live
+
+func (opt liveOption) apply(opts *options) {
+	live := Live(opt)
+	opts.live = &live
+}
+
+// WithLive makes the report poll the server it's served by and reload itself
+// once the served module was rebuilt, it also adds a button to rebuild it
+func WithLive(live Live) Option {
+	return liveOption(live)
+}
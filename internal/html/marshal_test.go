@@ -0,0 +1,175 @@
+package html_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/html"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+// the report data is compared rather than the whole page, which embeds the
+// viewer's script and style
+const (
+	reportPrefix = "<script>const report = "
+	reportSuffix = ";</script>"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"transitive-circular-dependency": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "transitive-circular-dependency.json",
+			},
+		},
+		marshalReport(t),
+	)
+}
+
+func TestMarshal_WithStubs(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-third-party-modules": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.FileResolution,
+				Golden:     "with-third-party-modules.stubs.json",
+			},
+		},
+		marshalReport(t, html.WithStubs(true), html.WithStubPrefixes([]string{"github.com/fake/", "str"})),
+	)
+}
+
+// marshalReport returns the indented report data of the page marshaled with
+// the options
+func marshalReport(t *testing.T, opts ...html.Option) test.MarshalFunc {
+	return func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+		output, err := html.Marshal(modulePath, pkgs, append([]html.Option{html.WithResolution(resolution)}, opts...)...)
+		if err != nil {
+			return nil, err
+		}
+		indented := &bytes.Buffer{}
+		if err = json.Indent(indented, reportData(t, output), "", "\t"); err != nil {
+			return nil, err
+		}
+		indented.WriteString("\n")
+		return indented.Bytes(), nil
+	}
+}
+
+func TestMarshal_WithScriptEndTag(t *testing.T) {
+	modulePath := "github.com/fake/</script><script>alert(1)</script>"
+	pkg := &internal.Package{
+		DirName:    "/fake/</script>",
+		ModulePath: modulePath,
+		ModuleDir:  "/fake",
+		Name:       "</script>",
+		Files:      make(map[string]*internal.File),
+	}
+	file := &internal.File{
+		Package:  pkg,
+		FileName: "</script>.go",
+		AbsPath:  "/fake/</script>/</script>.go",
+		Imports:  make(map[string]*internal.Import),
+		Decls:    make(map[string]*internal.Decl),
+	}
+	file.Decls["Fn"] = &internal.Decl{File: file, Name: "Fn"}
+	pkg.Files[file.UID()] = file
+
+	output, err := html.Marshal(modulePath, []*internal.Package{pkg})
+	if err != nil {
+		t.Fatal("Marshal: ", err)
+	}
+
+	// only the report data and the viewer's script are closed
+	if count := strings.Count(string(output), "</script>"); count != 2 {
+		t.Errorf("expected 2 script end tags, got %d", count)
+	}
+	var report struct {
+		Module   string `json:"module"`
+		Packages []struct {
+			Label string `json:"label"`
+		} `json:"packages"`
+	}
+	if err = json.Unmarshal(reportData(t, output), &report); err != nil {
+		t.Fatal("unmarshal report: ", err)
+	}
+	if report.Module != modulePath {
+		t.Errorf("expected module %q, got %q", modulePath, report.Module)
+	}
+	if len(report.Packages) != 1 || report.Packages[0].Label != pkg.Name {
+		t.Errorf("expected a single package labeled %q, got %+v", pkg.Name, report.Packages)
+	}
+}
+
+func TestMarshal_WithLive(t *testing.T) {
+	const (
+		livePrefix = "<script>const live = "
+		liveSuffix = ";</script>"
+	)
+	pkg := &internal.Package{
+		DirName:    "/fake/a",
+		ModulePath: "github.com/fake/fake",
+		ModuleDir:  "/fake",
+		Name:       "a",
+		Files:      make(map[string]*internal.File),
+	}
+
+	output, err := html.Marshal(pkg.ModulePath, []*internal.Package{pkg})
+	if err != nil {
+		t.Fatal("Marshal: ", err)
+	}
+	if bytes.Contains(output, []byte(livePrefix)) {
+		t.Error("expected no live data without WithLive")
+	}
+
+	output, err = html.Marshal(
+		pkg.ModulePath,
+		[]*internal.Package{pkg},
+		html.WithLive(html.Live{StatusURL: "/api/status", RebuildURL: "/api/rebuild", Version: 3}),
+	)
+	if err != nil {
+		t.Fatal("Marshal: ", err)
+	}
+	_, data, ok := bytes.Cut(output, []byte(livePrefix))
+	if !ok {
+		t.Fatal("live data not found")
+	}
+	data, _, ok = bytes.Cut(data, []byte(liveSuffix))
+	if !ok {
+		t.Fatal("end of live data not found")
+	}
+	var live struct {
+		StatusURL  string `json:"statusURL"`
+		RebuildURL string `json:"rebuildURL"`
+		Version    int    `json:"version"`
+	}
+	if err = json.Unmarshal(data, &live); err != nil {
+		t.Fatal("unmarshal live data: ", err)
+	}
+	if live.StatusURL != "/api/status" || live.RebuildURL != "/api/rebuild" || live.Version != 3 {
+		t.Errorf("unexpected live data: %+v", live)
+	}
+	if !bytes.Contains(output, []byte(`id="rebuild"`)) {
+		t.Error("expected a rebuild button")
+	}
+}
+
+func reportData(t *testing.T, output []byte) []byte {
+	t.Helper()
+	_, data, ok := bytes.Cut(output, []byte(reportPrefix))
+	if !ok {
+		t.Fatal("report data not found")
+	}
+	data, _, ok = bytes.Cut(data, []byte(reportSuffix))
+	if !ok {
+		t.Fatal("end of report data not found")
+	}
+	return data
+}
@@ -0,0 +1,290 @@
+package json
+
+import (
+	"cmp"
+	"encoding/json"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+)
+
+type graph struct {
+	Module   string        `json:"module"`
+	Modules  []*moduleNode `json:"modules"`
+	Packages []*pkgNode    `json:"packages"`
+	Files    []*fileNode   `json:"files"`
+}
+
+type moduleNode struct {
+	ID            string   `json:"id"`
+	Version       string   `json:"version,omitempty"`
+	Packages      []string `json:"packages"`
+	IsStub        bool     `json:"isStub"`
+	IsStdlib      bool     `json:"isStdlib"`
+	InImportCycle bool     `json:"inImportCycle"`
+	SCC           int      `json:"scc"`
+}
+
+type pkgNode struct {
+	ID              string   `json:"id"`
+	Name            string   `json:"name"`
+	ImportPath      string   `json:"importPath"`
+	ModulePath      string   `json:"modulePath"`
+	Dir             string   `json:"dir"`
+	Files           []string `json:"files"`
+	BlankImportFile string   `json:"blankImportFile,omitempty"`
+	IsStub          bool     `json:"isStub"`
+	IsTest          bool     `json:"isTest"`
+	InImportCycle   bool     `json:"inImportCycle"`
+	SCC             int      `json:"scc"`
+}
+
+type fileNode struct {
+	ID            string        `json:"id"`
+	Package       string        `json:"package"`
+	Name          string        `json:"name"`
+	Path          string        `json:"path"`
+	Decls         []*declNode   `json:"decls"`
+	Imports       []*importEdge `json:"imports"`
+	IsStub        bool          `json:"isStub"`
+	IsBlankImport bool          `json:"isBlankImport"`
+	IsTest        bool          `json:"isTest"`
+	InImportCycle bool          `json:"inImportCycle"`
+	SCC           int           `json:"scc"`
+}
+
+type declNode struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	QualifiedName string `json:"qualifiedName"`
+	FuncName      string `json:"funcName,omitempty"`
+	IsMethod      bool   `json:"isMethod"`
+	IsBlank       bool   `json:"isBlank"`
+}
+
+type importEdge struct {
+	Package                string       `json:"package"`
+	Name                   string       `json:"name"`
+	Alias                  string       `json:"alias,omitempty"`
+	Path                   string       `json:"path"`
+	ReferencedTypes        []*reference `json:"referencedTypes"`
+	ReferencedTypesByDecl  []*declRefs  `json:"referencedTypesByDecl,omitempty"`
+	ReferencedFilesInCycle []string     `json:"referencedFilesInCycle"`
+	IsAliased              bool         `json:"isAliased"`
+	IsBlank                bool         `json:"isBlank"`
+	IsDot                  bool         `json:"isDot"`
+	IsImplicit             bool         `json:"isImplicit"`
+	InImportCycle          bool         `json:"inImportCycle"`
+	SCC                    int          `json:"scc"`
+}
+
+type reference struct {
+	Decl string `json:"decl"`
+	File string `json:"file"`
+}
+
+// declRefs are the declarations referenced by a top level declaration of the
+// importing file, identified like other declarations, see internal.Import
+type declRefs struct {
+	Decl            string       `json:"decl"`
+	ReferencedTypes []*reference `json:"referencedTypes"`
+}
+
+// Marshal serializes the dependency graph as JSON. Packages and files are
+// identified by their UIDs and declarations by their file's UID and their
+// own UID separated by a "#". Paths within a module are qualified by the
+// module path rather than absolute, e.g. "github.com/fake/fake/a/a.go", so
+// the output doesn't depend on where the module is checked out.
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	g := &graph{
+		Module:   modulePath,
+		Modules:  make([]*moduleNode, 0),
+		Packages: make([]*pkgNode, 0, len(pkgs)),
+		Files:    make([]*fileNode, 0),
+	}
+	seenModules := make(map[*internal.Module]bool)
+	for _, pkg := range pkgs {
+		g.Packages = append(g.Packages, buildPkgNode(pkg))
+		for _, file := range pkg.Files {
+			g.Files = append(g.Files, buildFileNode(file))
+		}
+		if pkg.Module != nil && !seenModules[pkg.Module] {
+			seenModules[pkg.Module] = true
+			g.Modules = append(g.Modules, buildModuleNode(pkg.Module))
+		}
+	}
+	slices.SortFunc(g.Modules, func(a, b *moduleNode) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	slices.SortFunc(g.Packages, func(a, b *pkgNode) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	slices.SortFunc(g.Files, func(a, b *fileNode) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+
+	if options.indent != "" {
+		return json.MarshalIndent(g, "", options.indent)
+	}
+	return json.Marshal(g)
+}
+
+func buildModuleNode(mod *internal.Module) *moduleNode {
+	node := &moduleNode{
+		ID:            mod.UID(),
+		Version:       mod.Version,
+		Packages:      make([]string, 0, len(mod.Packages)),
+		IsStub:        mod.IsStub,
+		IsStdlib:      mod.IsStdlib,
+		InImportCycle: mod.InImportCycle,
+		SCC:           mod.SCC,
+	}
+	for _, pkg := range mod.Packages {
+		node.Packages = append(node.Packages, pkgID(pkg))
+	}
+	slices.Sort(node.Packages)
+	return node
+}
+
+func buildPkgNode(pkg *internal.Package) *pkgNode {
+	node := &pkgNode{
+		ID:            pkgID(pkg),
+		Name:          pkg.Name,
+		ImportPath:    pkg.ImportPath(),
+		ModulePath:    pkg.ModulePath,
+		Dir:           moduleRelative(pkg, pkg.DirName),
+		Files:         fileIDs(pkg.Files),
+		IsStub:        pkg.IsStub,
+		IsTest:        pkg.IsTest,
+		InImportCycle: pkg.InImportCycle,
+		SCC:           pkg.SCC,
+	}
+	if pkg.HasBlankImports() {
+		node.BlankImportFile = fileID(pkg.BlankImportFile)
+	}
+	return node
+}
+
+func buildFileNode(file *internal.File) *fileNode {
+	node := &fileNode{
+		ID:            fileID(file),
+		Name:          file.FileName,
+		Path:          moduleRelative(file.Package, file.AbsPath),
+		Decls:         make([]*declNode, 0, len(file.Decls)),
+		Imports:       make([]*importEdge, 0, len(file.Imports)),
+		IsStub:        file.IsStub,
+		IsBlankImport: file.IsBlankImport,
+		IsTest:        file.IsTest,
+		InImportCycle: file.InImportCycle,
+		SCC:           file.SCC,
+	}
+	if file.Package != nil {
+		node.Package = pkgID(file.Package)
+	}
+	for _, declUID := range slices.Sorted(maps.Keys(file.Decls)) {
+		decl := file.Decls[declUID]
+		node.Decls = append(node.Decls, &declNode{
+			ID:            declID(decl),
+			Name:          decl.Name,
+			QualifiedName: decl.QualifiedName(),
+			FuncName:      decl.FuncName,
+			IsMethod:      decl.IsMethod,
+			IsBlank:       decl.IsBlank(),
+		})
+	}
+	for _, impUID := range slices.Sorted(maps.Keys(file.Imports)) {
+		node.Imports = append(node.Imports, buildImportEdge(file, file.Imports[impUID]))
+	}
+	return node
+}
+
+func buildImportEdge(file *internal.File, imp *internal.Import) *importEdge {
+	edge := &importEdge{
+		Name:                   imp.Name,
+		Alias:                  imp.Alias,
+		Path:                   imp.Path,
+		ReferencedFilesInCycle: fileIDs(imp.ReferencedFilesInCycle),
+		IsAliased:              imp.IsAliased,
+		IsBlank:                imp.IsBlank,
+		IsDot:                  imp.IsDot,
+		IsImplicit:             imp.IsImplicit,
+		InImportCycle:          imp.InImportCycle,
+		SCC:                    imp.SCC,
+	}
+	if imp.Package != nil {
+		edge.Package = pkgID(imp.Package)
+	}
+	edge.ReferencedTypes = references(imp.ReferencedTypes)
+	for _, name := range slices.Sorted(maps.Keys(imp.ReferencedTypesByDecl)) {
+		edge.ReferencedTypesByDecl = append(edge.ReferencedTypesByDecl, &declRefs{
+			Decl:            fileID(file) + "#" + name,
+			ReferencedTypes: references(imp.ReferencedTypesByDecl[name]),
+		})
+	}
+	return edge
+}
+
+// references are the references to the declarations sorted by their keys
+func references(decls map[string]*internal.Decl) []*reference {
+	refs := make([]*reference, 0, len(decls))
+	for _, name := range slices.Sorted(maps.Keys(decls)) {
+		decl := decls[name]
+		ref := &reference{
+			Decl: declID(decl),
+		}
+		if decl.File != nil {
+			ref.File = fileID(decl.File)
+		}
+		refs = append(refs, ref)
+	}
+	return refs
+}
+
+func declID(decl *internal.Decl) string {
+	if decl.File == nil {
+		return decl.UID()
+	}
+	return fileID(decl.File) + "#" + decl.UID()
+}
+
+func pkgID(pkg *internal.Package) string {
+	return moduleRelative(pkg, pkg.UID())
+}
+
+func fileID(file *internal.File) string {
+	return moduleRelative(file.Package, file.UID())
+}
+
+// fileIDs are the sorted IDs of the files
+func fileIDs(files map[string]*internal.File) []string {
+	ids := make([]string, 0, len(files))
+	for _, file := range files {
+		ids = append(ids, fileID(file))
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// moduleRelative qualifies a path within the package's module by the module
+// path instead of the module directory, other paths are kept as is
+func moduleRelative(pkg *internal.Package, path string) string {
+	if pkg == nil || pkg.ModuleDir == "" || !filepath.IsAbs(path) {
+		return path
+	}
+	rel, err := filepath.Rel(pkg.ModuleDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	if rel == "." {
+		return pkg.ModulePath
+	}
+	return pkg.ModulePath + "/" + filepath.ToSlash(rel)
+}
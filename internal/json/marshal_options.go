@@ -0,0 +1,20 @@
+package json
+
+type options struct {
+	indent string
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type indentOption string
+
+func (opt indentOption) apply(opts *options) {
+	opts.indent = string(opt)
+}
+
+// WithIndent pretty prints the output, indenting each level with indent
+func WithIndent(indent string) Option {
+	return indentOption(indent)
+}
@@ -0,0 +1,24 @@
+package json_test
+
+import (
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/json"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"transitive-circular-dependency": {
+				Dir:    "transitive-circular-dependency",
+				Golden: "transitive-circular-dependency.json",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, _ internal.Resolution) ([]byte, error) {
+			return json.Marshal(modulePath, pkgs, json.WithIndent("\t"))
+		},
+	)
+}
@@ -0,0 +1,516 @@
+package layout
+
+import (
+	"cmp"
+	"math"
+	"slices"
+)
+
+const (
+	orderingIterations  = 24
+	placementIterations = 8
+	selfLoopExtent      = 16
+)
+
+// Size is the width and height of a node
+type Size struct {
+	Width  float64
+	Height float64
+}
+
+// Point is a position where y grows downwards
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Edge connects two nodes by their indexes
+type Edge struct {
+	From int
+	To   int
+}
+
+// Spacing is the minimum horizontal distance between the nodes of a layer
+// and the vertical distance between layers
+type Spacing struct {
+	Node  float64
+	Layer float64
+}
+
+// Layout is the center of every node and the bend points of every edge,
+// ordered from its source to its target, in the order they were given. The
+// top left corner of the layout is the origin.
+type Layout struct {
+	Nodes  []Point
+	Edges  [][]Point
+	Width  float64
+	Height float64
+}
+
+// Layered lays the graph out top to bottom in the manner of Sugiyama et al.
+// Edges closing a cycle are reversed, nodes are assigned to layers by their
+// longest path from a source, edges spanning several layers are split by
+// dummy nodes, the nodes of each layer are ordered by the barycenter
+// heuristic to reduce crossings and finally pulled towards their neighbors
+// without overlapping.
+func Layered(sizes []Size, edges []Edge, spacing Spacing) *Layout {
+	l := &layered{spacing: spacing}
+	for _, size := range sizes {
+		l.addVertex(size)
+	}
+
+	reversed := acyclic(len(sizes), edges)
+	l.assignLayers(len(sizes), edges, reversed)
+	chains := l.split(edges, reversed)
+	l.order()
+	l.place()
+
+	layout := &Layout{
+		Nodes: make([]Point, len(sizes)),
+		Edges: make([][]Point, len(edges)),
+	}
+	for v := range sizes {
+		layout.Nodes[v] = Point{X: l.x[v], Y: l.y[v]}
+	}
+	for i, e := range edges {
+		if e.From == e.To {
+			layout.Edges[i] = l.selfLoop(e.From)
+			continue
+		}
+		bends := make([]Point, 0, len(chains[i]))
+		for _, d := range chains[i] {
+			bends = append(bends, Point{X: l.x[d], Y: l.y[d]})
+		}
+		if reversed[i] {
+			slices.Reverse(bends)
+		}
+		layout.Edges[i] = bends
+	}
+	l.separateParallel(layout, edges, chains)
+	layout.normalize(sizes)
+	return layout
+}
+
+// layered holds the vertices, the nodes followed by the dummy nodes, of a
+// layout in progress
+type layered struct {
+	spacing Spacing
+
+	sizes []Size
+	layer []int
+	// up and down are the neighbors of each vertex in the layer above and
+	// below it
+	up   [][]int
+	down [][]int
+
+	layers [][]int
+	pos    []int
+
+	x []float64
+	y []float64
+}
+
+func (l *layered) addVertex(size Size) int {
+	l.sizes = append(l.sizes, size)
+	l.layer = append(l.layer, 0)
+	l.up = append(l.up, nil)
+	l.down = append(l.down, nil)
+	l.pos = append(l.pos, 0)
+	l.x = append(l.x, 0)
+	l.y = append(l.y, 0)
+	return len(l.sizes) - 1
+}
+
+// acyclic reports the edges to reverse to leave the graph acyclic, the back
+// edges of a depth first search started from the sources
+func acyclic(n int, edges []Edge) []bool {
+	succ := make([][]int, n)
+	hasPred := make([]bool, n)
+	for i, e := range edges {
+		if e.From == e.To {
+			continue
+		}
+		succ[e.From] = append(succ[e.From], i)
+		hasPred[e.To] = true
+	}
+
+	const (
+		unvisited = iota
+		active
+		done
+	)
+	state := make([]int, n)
+	reversed := make([]bool, len(edges))
+	var visit func(v int)
+	visit = func(v int) {
+		state[v] = active
+		for _, i := range succ[v] {
+			w := edges[i].To
+			switch state[w] {
+			case active:
+				reversed[i] = true
+			case unvisited:
+				visit(w)
+			}
+		}
+		state[v] = done
+	}
+	for v := range n {
+		if !hasPred[v] && state[v] == unvisited {
+			visit(v)
+		}
+	}
+	for v := range n {
+		if state[v] == unvisited {
+			visit(v)
+		}
+	}
+	return reversed
+}
+
+// assignLayers places every node one layer below its lowest predecessor,
+// sources are then moved down to just above their highest successor
+func (l *layered) assignLayers(n int, edges []Edge, reversed []bool) {
+	succ := make([][]int, n)
+	inDeg := make([]int, n)
+	for i, e := range edges {
+		if e.From == e.To {
+			continue
+		}
+		from, to := e.From, e.To
+		if reversed[i] {
+			from, to = to, from
+		}
+		succ[from] = append(succ[from], to)
+		inDeg[to]++
+	}
+
+	var sources, order []int
+	remaining := slices.Clone(inDeg)
+	for v := range n {
+		if inDeg[v] == 0 {
+			sources = append(sources, v)
+			order = append(order, v)
+		}
+	}
+	for i := 0; i < len(order); i++ {
+		v := order[i]
+		for _, w := range succ[v] {
+			l.layer[w] = max(l.layer[w], l.layer[v]+1)
+			remaining[w]--
+			if remaining[w] == 0 {
+				order = append(order, w)
+			}
+		}
+	}
+
+	for _, v := range sources {
+		if len(succ[v]) == 0 {
+			continue
+		}
+		highest := math.MaxInt
+		for _, w := range succ[v] {
+			highest = min(highest, l.layer[w])
+		}
+		l.layer[v] = highest - 1
+	}
+}
+
+// split replaces every edge spanning several layers by a chain of dummy
+// nodes, one per layer crossed, and returns the chain of each edge
+func (l *layered) split(edges []Edge, reversed []bool) [][]int {
+	chains := make([][]int, len(edges))
+	for i, e := range edges {
+		if e.From == e.To {
+			continue
+		}
+		from, to := e.From, e.To
+		if reversed[i] {
+			from, to = to, from
+		}
+		prev := from
+		for layer := l.layer[from] + 1; layer < l.layer[to]; layer++ {
+			d := l.addVertex(Size{})
+			l.layer[d] = layer
+			l.link(prev, d)
+			chains[i] = append(chains[i], d)
+			prev = d
+		}
+		l.link(prev, to)
+	}
+
+	layerCount := 0
+	for _, layer := range l.layer {
+		layerCount = max(layerCount, layer+1)
+	}
+	l.layers = make([][]int, layerCount)
+	for v, layer := range l.layer {
+		l.pos[v] = len(l.layers[layer])
+		l.layers[layer] = append(l.layers[layer], v)
+	}
+	return chains
+}
+
+func (l *layered) link(from, to int) {
+	l.down[from] = append(l.down[from], to)
+	l.up[to] = append(l.up[to], from)
+}
+
+// order sweeps the layers down and up alternately, sorting each layer by
+// the barycenter of its neighbors in the previous layer, and keeps the
+// order with the fewest crossings
+func (l *layered) order() {
+	best := l.cloneLayers()
+	bestCrossings := l.crossings()
+	for i := range orderingIterations {
+		if bestCrossings == 0 {
+			break
+		}
+		if i%2 == 0 {
+			for layer := 1; layer < len(l.layers); layer++ {
+				l.sortLayer(layer, l.up)
+			}
+		} else {
+			for layer := len(l.layers) - 2; layer >= 0; layer-- {
+				l.sortLayer(layer, l.down)
+			}
+		}
+		if crossings := l.crossings(); crossings < bestCrossings {
+			best = l.cloneLayers()
+			bestCrossings = crossings
+		}
+	}
+	l.layers = best
+	for _, layer := range l.layers {
+		for i, v := range layer {
+			l.pos[v] = i
+		}
+	}
+}
+
+func (l *layered) cloneLayers() [][]int {
+	layers := make([][]int, len(l.layers))
+	for i, layer := range l.layers {
+		layers[i] = slices.Clone(layer)
+	}
+	return layers
+}
+
+// sortLayer orders the layer by the mean position of each vertex's
+// neighbors, vertices without neighbors keep their position
+func (l *layered) sortLayer(layer int, neighbors [][]int) {
+	vertices := l.layers[layer]
+	barycenters := make(map[int]float64, len(vertices))
+	for _, v := range vertices {
+		if len(neighbors[v]) == 0 {
+			barycenters[v] = float64(l.pos[v])
+			continue
+		}
+		var sum float64
+		for _, w := range neighbors[v] {
+			sum += float64(l.pos[w])
+		}
+		barycenters[v] = sum / float64(len(neighbors[v]))
+	}
+	slices.SortStableFunc(vertices, func(a, b int) int {
+		return cmp.Compare(barycenters[a], barycenters[b])
+	})
+	for i, v := range vertices {
+		l.pos[v] = i
+	}
+}
+
+func (l *layered) crossings() int {
+	var crossings int
+	for layer := 0; layer+1 < len(l.layers); layer++ {
+		var segments [][2]int
+		for _, v := range l.layers[layer] {
+			for _, w := range l.down[v] {
+				segments = append(segments, [2]int{l.pos[v], l.pos[w]})
+			}
+		}
+		for i, a := range segments {
+			for _, b := range segments[i+1:] {
+				if (a[0] < b[0] && a[1] > b[1]) || (a[0] > b[0] && a[1] < b[1]) {
+					crossings++
+				}
+			}
+		}
+	}
+	return crossings
+}
+
+// place packs every layer to the left, pulls the vertices towards their
+// neighbors in the layers above and below alternately, then stacks the
+// layers
+func (l *layered) place() {
+	for _, layer := range l.layers {
+		for i, v := range layer {
+			if i == 0 {
+				continue
+			}
+			l.x[v] = l.x[layer[i-1]] + l.separation(layer[i-1], v)
+		}
+	}
+	for i := range placementIterations {
+		if i%2 == 0 {
+			for layer := 1; layer < len(l.layers); layer++ {
+				l.align(l.layers[layer], l.up)
+			}
+		} else {
+			for layer := len(l.layers) - 2; layer >= 0; layer-- {
+				l.align(l.layers[layer], l.down)
+			}
+		}
+	}
+
+	var top float64
+	for _, layer := range l.layers {
+		var height float64
+		for _, v := range layer {
+			height = max(height, l.sizes[v].Height)
+		}
+		for _, v := range layer {
+			l.y[v] = top + height/2
+		}
+		top += height + l.spacing.Layer
+	}
+}
+
+func (l *layered) separation(a, b int) float64 {
+	return (l.sizes[a].Width+l.sizes[b].Width)/2 + l.spacing.Node
+}
+
+// align moves the vertices of the layer as close to the mean position of
+// their neighbors as possible while keeping them in order and apart
+func (l *layered) align(layer []int, neighbors [][]int) {
+	desired := make([]float64, len(layer))
+	for i, v := range layer {
+		desired[i] = l.x[v]
+		if len(neighbors[v]) == 0 {
+			continue
+		}
+		var sum float64
+		for _, w := range neighbors[v] {
+			sum += l.x[w]
+		}
+		desired[i] = sum / float64(len(neighbors[v]))
+	}
+	for i, x := range l.separate(layer, desired) {
+		l.x[layer[i]] = x
+	}
+}
+
+// separate returns the positions closest to the desired ones, in the least
+// squares sense, which keep the vertices in order and apart. Offsetting each
+// position by the separation required from the first vertex turns this into
+// an isotonic regression, solved by pooling adjacent violators.
+func (l *layered) separate(layer []int, desired []float64) []float64 {
+	offsets := make([]float64, len(layer))
+	for i := 1; i < len(layer); i++ {
+		offsets[i] = offsets[i-1] + l.separation(layer[i-1], layer[i])
+	}
+
+	type block struct {
+		sum   float64
+		count int
+	}
+	mean := func(b block) float64 {
+		return b.sum / float64(b.count)
+	}
+	var blocks []block
+	for i := range layer {
+		blocks = append(blocks, block{sum: desired[i] - offsets[i], count: 1})
+		for len(blocks) > 1 {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if mean(prev) <= mean(last) {
+				break
+			}
+			blocks = append(blocks[:len(blocks)-2], block{sum: prev.sum + last.sum, count: prev.count + last.count})
+		}
+	}
+
+	positions := make([]float64, 0, len(layer))
+	for _, b := range blocks {
+		for range b.count {
+			positions = append(positions, mean(b)+offsets[len(positions)])
+		}
+	}
+	return positions
+}
+
+// separateParallel bends the edges running directly between the same two
+// nodes, in either direction, apart at their midpoint so they don't overlap
+func (l *layered) separateParallel(layout *Layout, edges []Edge, chains [][]int) {
+	parallel := make(map[Edge][]int)
+	var pairs []Edge
+	for i, e := range edges {
+		if e.From == e.To || len(chains[i]) > 0 {
+			continue
+		}
+		pair := Edge{From: min(e.From, e.To), To: max(e.From, e.To)}
+		if _, ok := parallel[pair]; !ok {
+			pairs = append(pairs, pair)
+		}
+		parallel[pair] = append(parallel[pair], i)
+	}
+	for _, pair := range pairs {
+		group := parallel[pair]
+		if len(group) < 2 {
+			continue
+		}
+		for j, i := range group {
+			offset := (float64(j) - float64(len(group)-1)/2) * l.spacing.Node / 2
+			layout.Edges[i] = []Point{{
+				X: (l.x[pair.From]+l.x[pair.To])/2 + offset,
+				Y: (l.y[pair.From] + l.y[pair.To]) / 2,
+			}}
+		}
+	}
+}
+
+// selfLoop bends the edge out of the right side of the node and back in
+func (l *layered) selfLoop(v int) []Point {
+	right := l.x[v] + l.sizes[v].Width/2 + selfLoopExtent
+	return []Point{
+		{X: right, Y: l.y[v] - l.sizes[v].Height/4},
+		{X: right, Y: l.y[v] + l.sizes[v].Height/4},
+	}
+}
+
+// normalize moves the layout so its bounding box, including the edges'
+// bend points, starts at the origin
+func (layout *Layout) normalize(sizes []Size) {
+	if len(sizes) == 0 {
+		return
+	}
+	left, top := math.Inf(1), math.Inf(1)
+	right, bottom := math.Inf(-1), math.Inf(-1)
+	include := func(p Point, size Size) {
+		left = min(left, p.X-size.Width/2)
+		right = max(right, p.X+size.Width/2)
+		top = min(top, p.Y-size.Height/2)
+		bottom = max(bottom, p.Y+size.Height/2)
+	}
+	for v, p := range layout.Nodes {
+		include(p, sizes[v])
+	}
+	for _, bends := range layout.Edges {
+		for _, p := range bends {
+			include(p, Size{})
+		}
+	}
+
+	for v := range layout.Nodes {
+		layout.Nodes[v].X -= left
+		layout.Nodes[v].Y -= top
+	}
+	for _, bends := range layout.Edges {
+		for i := range bends {
+			bends[i].X -= left
+			bends[i].Y -= top
+		}
+	}
+	layout.Width = right - left
+	layout.Height = bottom - top
+}
@@ -0,0 +1,82 @@
+package layout_test
+
+import (
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal/layout"
+)
+
+func TestLayered(t *testing.T) {
+	spacing := layout.Spacing{Node: 10, Layer: 20}
+	testCases := map[string]struct {
+		sizes []layout.Size
+		edges []layout.Edge
+		// downwards are the edges expected to point down, the others close a
+		// cycle and point up
+		downwards []bool
+		bends     []int
+	}{
+		"chain": {
+			sizes:     []layout.Size{{10, 10}, {20, 10}, {30, 10}},
+			edges:     []layout.Edge{{0, 1}, {1, 2}},
+			downwards: []bool{true, true},
+			bends:     []int{0, 0},
+		},
+		"long edge is split": {
+			sizes:     []layout.Size{{10, 10}, {10, 10}, {10, 10}},
+			edges:     []layout.Edge{{0, 1}, {1, 2}, {0, 2}},
+			downwards: []bool{true, true, true},
+			bends:     []int{0, 0, 1},
+		},
+		"direct cycle": {
+			sizes:     []layout.Size{{10, 10}, {10, 10}, {10, 10}},
+			edges:     []layout.Edge{{0, 1}, {1, 2}, {2, 1}},
+			downwards: []bool{true, true, false},
+			bends:     []int{0, 1, 1},
+		},
+		"transitive cycle": {
+			sizes:     []layout.Size{{10, 10}, {10, 10}, {10, 10}, {10, 10}},
+			edges:     []layout.Edge{{0, 1}, {1, 2}, {2, 3}, {3, 1}},
+			downwards: []bool{true, true, true, false},
+			bends:     []int{0, 0, 0, 1},
+		},
+		"self loop": {
+			sizes:     []layout.Size{{10, 10}},
+			edges:     []layout.Edge{{0, 0}},
+			downwards: []bool{false},
+			bends:     []int{2},
+		},
+		"disconnected": {
+			sizes: []layout.Size{{10, 10}, {40, 10}, {10, 30}},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		l := layout.Layered(testCase.sizes, testCase.edges, spacing)
+
+		for i, e := range testCase.edges {
+			from, to := l.Nodes[e.From], l.Nodes[e.To]
+			if e.From != e.To && (from.Y < to.Y) != testCase.downwards[i] {
+				t.Errorf("%s: edge %d: expected downwards %t", desc, i, testCase.downwards[i])
+			}
+			if len(l.Edges[i]) != testCase.bends[i] {
+				t.Errorf("%s: edge %d: expected %d bends, got %d", desc, i, testCase.bends[i], len(l.Edges[i]))
+			}
+		}
+
+		for a, p := range l.Nodes {
+			sizeA := testCase.sizes[a]
+			if p.X-sizeA.Width/2 < 0 || p.Y-sizeA.Height/2 < 0 || p.X+sizeA.Width/2 > l.Width || p.Y+sizeA.Height/2 > l.Height {
+				t.Errorf("%s: node %d: outside of the layout", desc, a)
+			}
+			for b, q := range l.Nodes[a+1:] {
+				sizeB := testCase.sizes[a+1+b]
+				overlapX := p.X-q.X < (sizeA.Width+sizeB.Width)/2 && q.X-p.X < (sizeA.Width+sizeB.Width)/2
+				overlapY := p.Y-q.Y < (sizeA.Height+sizeB.Height)/2 && q.Y-p.Y < (sizeA.Height+sizeB.Height)/2
+				if overlapX && overlapY {
+					t.Errorf("%s: nodes %d and %d overlap", desc, a, a+1+b)
+				}
+			}
+		}
+	}
+}
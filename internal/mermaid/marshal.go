@@ -0,0 +1,464 @@
+package mermaid
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+const (
+	packageClass      = "package"
+	packageCycleClass = "packageCycle"
+	fileClass         = "file"
+	fileCycleClass    = "fileCycle"
+
+	packageTestClass      = "packageTest"
+	packageTestCycleClass = "packageTestCycle"
+	fileTestClass         = "fileTest"
+	fileTestCycleClass    = "fileTestCycle"
+
+	externalClass = "external"
+	stdlibClass   = "stdlib"
+)
+
+// Marshal serializes the dependency graph as a Mermaid flowchart
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{
+		palette:    *color.DefaultPalette,
+		resolution: internal.FileResolution,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	g := graph.Visible(
+		pkgs,
+		options.resolution,
+		graph.WithStdlib(options.stdlib),
+		graph.WithStubs(options.stubs),
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	)
+	nodeIDs := make(map[string]string)
+	for i, n := range g.Nodes() {
+		nodeIDs[n.ID] = "n" + strconv.Itoa(i)
+	}
+
+	// stub packages, or their modules, are drawn outside of the subgraphs
+	var nodes, stubs []*graph.Node
+	for _, n := range g.Nodes() {
+		if n.IsStub() && options.resolution != internal.ModuleResolution {
+			stubs = append(stubs, n)
+			continue
+		}
+		nodes = append(nodes, n)
+	}
+
+	buf := &bytes.Buffer{}
+	writeHeader(buf, modulePath, &options.palette, hasTests(g), hasStubs(g))
+	switch options.resolution {
+	case internal.DeclResolution:
+		writeNodeDefsByModule(buf, nodes, func(buf *bytes.Buffer, nodes []*graph.Node, subgraphPrefix string) {
+			writeNodeDefsForDeclResolution(buf, nodes, nodeIDs, subgraphPrefix)
+		})
+	case internal.FileResolution:
+		writeNodeDefsByModule(buf, nodes, func(buf *bytes.Buffer, nodes []*graph.Node, subgraphPrefix string) {
+			writeNodeDefsForFileResolution(buf, nodes, nodeIDs, subgraphPrefix)
+		})
+	case internal.PackageResolution:
+		writeNodeDefsByModule(buf, nodes, func(buf *bytes.Buffer, nodes []*graph.Node, _ string) {
+			writeNodeDefsForPackageResolution(buf, nodes, nodeIDs)
+		})
+	case internal.ModuleResolution:
+		writeNodeDefsForPackageResolution(buf, nodes, nodeIDs)
+	}
+	writeNodeDefsForPackageResolution(buf, stubs, nodeIDs)
+	writeRelationships(buf, &options.palette, options.highlightedEdges, options.resolution == internal.ModuleResolution, g, nodeIDs)
+
+	return buf.Bytes(), nil
+}
+
+// hasTests reports whether the graph contains test files or external test
+// packages, their classes are only defined if it does
+func hasTests(g *graph.Graph) bool {
+	for _, n := range g.Nodes() {
+		if n.IsTest() || (n.Package != nil && n.Package.IsTest) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasStubs reports whether the graph contains stub packages or modules,
+// their classes are only defined if it does
+func hasStubs(g *graph.Graph) bool {
+	for _, n := range g.Nodes() {
+		if n.IsStub() {
+			return true
+		}
+	}
+	return false
+}
+
+func writeHeader(buf *bytes.Buffer, modulePath string, palette *color.Palette, withTests, withStubs bool) {
+	_, err := fmt.Fprintf(
+		buf,
+		`---
+title: %s
+---
+flowchart TB
+	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+`,
+		modulePath,
+		packageClass, palette.Base.PackageBackground.Hex(), palette.Base.PackageName.Hex(),
+		packageCycleClass, palette.Cycle.PackageBackground.Hex(), palette.Cycle.PackageName.Hex(),
+		fileClass, palette.Base.FileBackground.Hex(), palette.Base.FileName.Hex(),
+		fileCycleClass, palette.Cycle.FileBackground.Hex(), palette.Cycle.FileName.Hex(),
+	)
+	if err != nil {
+		panic(err)
+	}
+	if withStubs {
+		external := palette.Stub(false)
+		stdlib := palette.Stub(true)
+		_, err = fmt.Fprintf(
+			buf,
+			`	classDef %s fill:%s,color:%s,stroke-dasharray:5 5
+	classDef %s fill:%s,color:%s,stroke-dasharray:5 5
+`,
+			externalClass, external.PackageBackground.Hex(), external.PackageName.Hex(),
+			stdlibClass, stdlib.PackageBackground.Hex(), stdlib.PackageName.Hex(),
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+	if !withTests {
+		return
+	}
+	test := palette.Half(true, false)
+	testCycle := palette.Half(true, true)
+	_, err = fmt.Fprintf(
+		buf,
+		`	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+	classDef %s fill:%s,color:%s
+`,
+		packageTestClass, test.PackageBackground.Hex(), test.PackageName.Hex(),
+		packageTestCycleClass, testCycle.PackageBackground.Hex(), testCycle.PackageName.Hex(),
+		fileTestClass, test.FileBackground.Hex(), test.FileName.Hex(),
+		fileTestCycleClass, testCycle.FileBackground.Hex(), testCycle.FileName.Hex(),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// nodeClassOf is the class of a package or module node
+func nodeClassOf(n *graph.Node) string {
+	switch {
+	case n.IsStdlib():
+		return stdlibClass
+	case n.IsStub():
+		return externalClass
+	}
+	return packageClassOf(n.IsTest(), n.InImportCycle())
+}
+
+func packageClassOf(isTest, inImportCycle bool) string {
+	switch {
+	case isTest && inImportCycle:
+		return packageTestCycleClass
+	case isTest:
+		return packageTestClass
+	case inImportCycle:
+		return packageCycleClass
+	}
+	return packageClass
+}
+
+func fileClassOf(isTest, inImportCycle bool) string {
+	switch {
+	case isTest && inImportCycle:
+		return fileTestCycleClass
+	case isTest:
+		return fileTestClass
+	case inImportCycle:
+		return fileCycleClass
+	}
+	return fileClass
+}
+
+// writeNodeDefsByModule wraps the node definitions of each module in a
+// subgraph when the packages of more than one module, e.g. of a workspace,
+// are rendered
+func writeNodeDefsByModule(
+	buf *bytes.Buffer,
+	nodes []*graph.Node,
+	writeNodeDefs func(buf *bytes.Buffer, nodes []*graph.Node, subgraphPrefix string),
+) {
+	var mods []*internal.Module
+	nodesByModule := make(map[*internal.Module][]*graph.Node)
+	for _, n := range nodes {
+		mod := n.Package.Module
+		if _, ok := nodesByModule[mod]; !ok {
+			mods = append(mods, mod)
+		}
+		nodesByModule[mod] = append(nodesByModule[mod], n)
+	}
+	if len(mods) < 2 {
+		writeNodeDefs(buf, nodes, "")
+		return
+	}
+
+	var err error
+	subgraphHeader := `
+	subgraph %s["%s"]
+`
+	subgraphFooter := `	end
+`
+	for i, mod := range mods {
+		subgraphID := "m" + strconv.Itoa(i)
+		label := ""
+		if mod != nil {
+			label = mod.Path
+		}
+		_, err = fmt.Fprintf(buf, subgraphHeader, subgraphID, escape(label))
+		if err != nil {
+			panic(err)
+		}
+		writeNodeDefs(buf, nodesByModule[mod], subgraphID)
+		buf.WriteString(subgraphFooter)
+	}
+}
+
+func writeNodeDefsForFileResolution(buf *bytes.Buffer, nodes []*graph.Node, nodeIDs map[string]string, subgraphPrefix string) {
+	var err error
+	subgraphHeader := `
+	subgraph %s["%s"]
+`
+	subgraphFooter := `	end
+	class %s %s
+`
+	nodeDef := `		%s["%s"]
+		class %s %s
+`
+
+	var pkgs []*internal.Package
+	filesByPkg := make(map[*internal.Package][]*graph.Node)
+	for _, n := range nodes {
+		if _, ok := filesByPkg[n.Package]; !ok {
+			pkgs = append(pkgs, n.Package)
+		}
+		filesByPkg[n.Package] = append(filesByPkg[n.Package], n)
+	}
+
+	for i, pkg := range pkgs {
+		subgraphID := subgraphPrefix + "p" + strconv.Itoa(i)
+		_, err = fmt.Fprintf(
+			buf,
+			subgraphHeader,
+			subgraphID,
+			escape(pkg.ModuleRelativePath()),
+		)
+		if err != nil {
+			panic(err)
+		}
+		for _, n := range filesByPkg[pkg] {
+			class := fileClassOf(n.IsTest(), n.InImportCycle())
+			_, err = fmt.Fprintf(
+				buf,
+				nodeDef,
+				nodeIDs[n.ID],
+				escape(n.Label()),
+				nodeIDs[n.ID],
+				class,
+			)
+			if err != nil {
+				panic(err)
+			}
+		}
+		class := packageClassOf(pkg.IsTest, pkg.InImportCycle)
+		_, err = fmt.Fprintf(buf, subgraphFooter, subgraphID, class)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// writeNodeDefsForDeclResolution wraps the declarations of each file in a
+// subgraph within the subgraph of its package
+func writeNodeDefsForDeclResolution(buf *bytes.Buffer, nodes []*graph.Node, nodeIDs map[string]string, subgraphPrefix string) {
+	var err error
+	pkgSubgraphHeader := `
+	subgraph %s["%s"]
+`
+	pkgSubgraphFooter := `	end
+	class %s %s
+`
+	fileSubgraphHeader := `		subgraph %s["%s"]
+`
+	fileSubgraphFooter := `		end
+		class %s %s
+`
+	nodeDef := `			%s["%s"]
+			class %s %s
+`
+
+	var pkgs []*internal.Package
+	filesByPkg := make(map[*internal.Package][]*internal.File)
+	declsByFile := make(map[*internal.File][]*graph.Node)
+	for _, n := range nodes {
+		if _, ok := filesByPkg[n.Package]; !ok {
+			pkgs = append(pkgs, n.Package)
+		}
+		if _, ok := declsByFile[n.File]; !ok {
+			filesByPkg[n.Package] = append(filesByPkg[n.Package], n.File)
+		}
+		declsByFile[n.File] = append(declsByFile[n.File], n)
+	}
+
+	for i, pkg := range pkgs {
+		pkgSubgraphID := subgraphPrefix + "p" + strconv.Itoa(i)
+		_, err = fmt.Fprintf(
+			buf,
+			pkgSubgraphHeader,
+			pkgSubgraphID,
+			escape(pkg.ModuleRelativePath()),
+		)
+		if err != nil {
+			panic(err)
+		}
+		for j, file := range filesByPkg[pkg] {
+			fileSubgraphID := pkgSubgraphID + "f" + strconv.Itoa(j)
+			_, err = fmt.Fprintf(buf, fileSubgraphHeader, fileSubgraphID, escape(file.FileName))
+			if err != nil {
+				panic(err)
+			}
+			class := fileClassOf(file.IsTest, file.InImportCycle)
+			for _, n := range declsByFile[file] {
+				_, err = fmt.Fprintf(
+					buf,
+					nodeDef,
+					nodeIDs[n.ID],
+					escape(n.Label()),
+					nodeIDs[n.ID],
+					class,
+				)
+				if err != nil {
+					panic(err)
+				}
+			}
+			_, err = fmt.Fprintf(buf, fileSubgraphFooter, fileSubgraphID, class)
+			if err != nil {
+				panic(err)
+			}
+		}
+		class := packageClassOf(pkg.IsTest, pkg.InImportCycle)
+		_, err = fmt.Fprintf(buf, pkgSubgraphFooter, pkgSubgraphID, class)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+func writeNodeDefsForPackageResolution(buf *bytes.Buffer, nodes []*graph.Node, nodeIDs map[string]string) {
+	nodeDef := `	%s["%s"]
+	class %s %s
+`
+	for _, n := range nodes {
+		class := nodeClassOf(n)
+		_, err := fmt.Fprintf(
+			buf,
+			nodeDef,
+			nodeIDs[n.ID],
+			escape(n.Label()),
+			nodeIDs[n.ID],
+			class,
+		)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// writeRelationships draws highlighted edges as dotted links, weighted links
+// are labeled with their weight
+func writeRelationships(buf *bytes.Buffer, palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, weighted bool, g *graph.Graph, nodeIDs map[string]string) {
+	var err error
+	var cycleLinks, testLinks, testCycleLinks, externalLinks, stdlibLinks []string
+	edges := g.Edges()
+	buf.WriteString("\n")
+	for i, e := range edges {
+		link := "-->"
+		if highlightedEdges[e.ID()] {
+			link = "-.->"
+		}
+		if weighted {
+			link += "|" + strconv.Itoa(e.Weight()) + "|"
+		}
+		_, err = fmt.Fprintf(
+			buf,
+			"\t%s %s %s\n",
+			nodeIDs[e.From.ID],
+			link,
+			nodeIDs[e.To.ID],
+		)
+		if err != nil {
+			panic(err)
+		}
+		switch {
+		case e.To.IsStdlib():
+			stdlibLinks = append(stdlibLinks, strconv.Itoa(i))
+		case e.To.IsStub():
+			externalLinks = append(externalLinks, strconv.Itoa(i))
+		case e.From.IsTest() && e.InImportCycle():
+			testCycleLinks = append(testCycleLinks, strconv.Itoa(i))
+		case e.From.IsTest():
+			testLinks = append(testLinks, strconv.Itoa(i))
+		case e.InImportCycle():
+			cycleLinks = append(cycleLinks, strconv.Itoa(i))
+		}
+	}
+	if len(edges) == 0 {
+		return
+	}
+	_, err = fmt.Fprintf(buf, "\tlinkStyle default stroke:%s\n", palette.Base.ImportArrow.Hex())
+	if err != nil {
+		panic(err)
+	}
+	writeLinkStyle(buf, cycleLinks, palette.Cycle.ImportArrow)
+	writeLinkStyle(buf, testLinks, palette.Half(true, false).ImportArrow)
+	writeLinkStyle(buf, testCycleLinks, palette.Half(true, true).ImportArrow)
+	writeLinkStyle(buf, externalLinks, palette.Stub(false).ImportArrow)
+	writeLinkStyle(buf, stdlibLinks, palette.Stub(true).ImportArrow)
+}
+
+func writeLinkStyle(buf *bytes.Buffer, links []string, stroke color.Color) {
+	if len(links) == 0 {
+		return
+	}
+	_, err := fmt.Fprintf(
+		buf,
+		"\tlinkStyle %s stroke:%s\n",
+		strings.Join(links, ","),
+		stroke.Hex(),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// escape replaces characters which would end a quoted Mermaid label
+func escape(label string) string {
+	return strings.ReplaceAll(label, `"`, "#quot;")
+}
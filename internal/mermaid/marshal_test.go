@@ -0,0 +1,106 @@
+package mermaid_test
+
+import (
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/mermaid"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"transitive-circular-dependency at the file resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "transitive-circular-dependency.file.mmd",
+			},
+			"with-decl-references at the decl resolution": {
+				Dir:        "with-decl-references",
+				Resolution: internal.DeclResolution,
+				Golden:     "with-decl-references.decl.mmd",
+			},
+			"transitive-circular-dependency at the package resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "transitive-circular-dependency.package.mmd",
+			},
+			"with-test-cycles at the file resolution": {
+				Dir:          "with-test-cycles",
+				Resolution:   internal.FileResolution,
+				Golden:       "with-test-cycles.file.mmd",
+				BuildOptions: []primitives.Option{primitives.WithTests()},
+			},
+			"with-test-cycles at the package resolution": {
+				Dir:          "with-test-cycles",
+				Resolution:   internal.PackageResolution,
+				Golden:       "with-test-cycles.package.mmd",
+				BuildOptions: []primitives.Option{primitives.WithTests()},
+			},
+			"with-workspace at the file resolution": {
+				Dir:        "with-workspace",
+				Resolution: internal.FileResolution,
+				Golden:     "with-workspace.file.mmd",
+			},
+			"with-workspace at the module resolution": {
+				Dir:        "with-workspace",
+				Resolution: internal.ModuleResolution,
+				Golden:     "with-workspace.module.mmd",
+			},
+			"with-third-party-modules at the module resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.ModuleResolution,
+				Golden:     "with-third-party-modules.module.mmd",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return mermaid.Marshal(modulePath, pkgs, mermaid.WithResolution(resolution))
+		},
+	)
+}
+
+func TestMarshal_WithStdlib(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-third-party-modules at the module resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.ModuleResolution,
+				Golden:     "with-third-party-modules.module.stdlib.mmd",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return mermaid.Marshal(modulePath, pkgs, mermaid.WithResolution(resolution), mermaid.WithStdlib(true))
+		},
+	)
+}
+
+func TestMarshal_WithStubs(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-third-party-modules at the package resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.PackageResolution,
+				Golden:     "with-third-party-modules.package.stubs.mmd",
+			},
+			"with-third-party-modules at the file resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.FileResolution,
+				Golden:     "with-third-party-modules.file.stubs.mmd",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return mermaid.Marshal(
+				modulePath,
+				pkgs,
+				mermaid.WithResolution(resolution),
+				mermaid.WithStubs(true),
+				mermaid.WithCollapsedStubs(resolution == internal.FileResolution),
+			)
+		},
+	)
+}
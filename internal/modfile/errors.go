@@ -15,3 +15,9 @@ type ModulePathNotFoundError struct{
 func (err *ModulePathNotFoundError) Error() string {
 	return "go module path not found"
 }
+
+type WorkspaceModulesNotFoundError struct{}
+
+func (err *WorkspaceModulesNotFoundError) Error() string {
+	return "go workspace modules not found"
+}
@@ -0,0 +1,36 @@
+package modfile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// FindGoWorkFile finds the go.work file of the workspace containing path the
+// way the go command does, GOWORK names the file or disables workspaces when
+// set to off
+func FindGoWorkFile(path string) (string, error) {
+	switch gowork := os.Getenv("GOWORK"); gowork {
+	case "":
+	case "off":
+		return "", &FileNotFoundError{Name: "go.work"}
+	default:
+		return gowork, nil
+	}
+	return findGoWorkFile(path)
+}
+
+func findGoWorkFile(path string) (string, error) {
+	_, err := os.Stat(filepath.Join(path, "go.work"))
+	if err == nil {
+		return filepath.Join(path, "go.work"), nil
+	}
+	if !errors.Is(err, os.ErrNotExist) {
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return "", &FileNotFoundError{Name: "go.work"}
+	}
+	return findGoWorkFile(parent)
+}
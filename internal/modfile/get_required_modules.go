@@ -0,0 +1,35 @@
+package modfile
+
+import (
+	"os"
+
+	"golang.org/x/mod/modfile"
+)
+
+// RequiredModule is a module required by a go.mod file
+type RequiredModule struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// GetRequiredModules returns the modules required by the go.mod file
+func GetRequiredModules(goModFile string) ([]RequiredModule, error) {
+	goMod, err := os.ReadFile(goModFile)
+	if err != nil {
+		return nil, err
+	}
+	file, err := modfile.ParseLax(goModFile, goMod, nil)
+	if err != nil {
+		return nil, err
+	}
+	required := make([]RequiredModule, 0, len(file.Require))
+	for _, require := range file.Require {
+		required = append(required, RequiredModule{
+			Path:     require.Mod.Path,
+			Version:  require.Mod.Version,
+			Indirect: require.Indirect,
+		})
+	}
+	return required, nil
+}
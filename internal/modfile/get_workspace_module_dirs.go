@@ -0,0 +1,34 @@
+package modfile
+
+import (
+	"os"
+	"path/filepath"
+
+	"golang.org/x/mod/modfile"
+)
+
+// GetWorkspaceModuleDirs returns the absolute directories of the modules
+// used by the workspace
+func GetWorkspaceModuleDirs(goWorkFile string) ([]string, error) {
+	goWork, err := os.ReadFile(goWorkFile)
+	if err != nil {
+		return nil, err
+	}
+	work, err := modfile.ParseWork(goWorkFile, goWork, nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(work.Use) == 0 {
+		return nil, &WorkspaceModulesNotFoundError{}
+	}
+	workDir := filepath.Dir(goWorkFile)
+	dirs := make([]string, 0, len(work.Use))
+	for _, use := range work.Use {
+		dir := filepath.FromSlash(use.Path)
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(workDir, dir)
+		}
+		dirs = append(dirs, filepath.Clean(dir))
+	}
+	return dirs, nil
+}
@@ -9,11 +9,43 @@
 
 const (
 	BlankIdentifier = "_"
+	// DotIdentifier is the name of imports whose exported declarations are
+	// used without a selector, e.g. import . "fmt"
+	DotIdentifier = "."
+	// StdlibModulePath is the path of the module the standard library's
+	// packages belong to
+	StdlibModulePath = "std"
 )
 
+// Module is a module analyzed on its own or as one of the modules of a
+// workspace. Modules required by them, and the standard library, are stub
+// modules holding the stub packages imported from them.
+type Module struct {
+	Path string
+	Dir  string
+	// Version is the required version of a stub module
+	Version string
+
+	Packages map[string]*Package
+
+	IsStub        bool
+	IsStdlib      bool
+	InImportCycle bool
+	// SCC is the 1-based strongly connected component of the module graph
+	// the module belongs to, 0 if import cycles have not been marked up
+	SCC int
+}
+
+func (mod Module) UID() string {
+	return mod.Path
+}
+
 type Package struct {
 	DirName string
 
+	// Module is the module declaring the package, for stub packages the
+	// required module or the standard library providing it, if any
+	Module     *Module
 	ModulePath string
 	ModuleDir  string
 	Name       string
@@ -21,14 +53,32 @@ type Package struct {
 	BlankImportFile *File
 	Files           map[string]*File
 
-	IsStub        bool
+	IsStub bool
+	// IsTest is set for external test packages, e.g. foo_test, which are
+	// distinct from the package under test in the same directory
+	IsTest        bool
 	InImportCycle bool
+	// SCC is the 1-based strongly connected component of the package graph
+	// the package belongs to, 0 if import cycles have not been marked up
+	SCC int
 }
 
 func (pkg Package) ImportPath() string {
+	path := pkg.importPath()
+	if pkg.IsTest && path != "" {
+		return path + "_test"
+	}
+	return path
+}
+
+func (pkg Package) importPath() string {
 	if pkg.Name == "main" {
 		return ""
 	}
+	// stub packages are named after their import path
+	if pkg.IsStub {
+		return pkg.DirName
+	}
 	moduleRoot := pkg.ModuleDir
 	if strings.LastIndex(moduleRoot, string(os.PathSeparator)) != len(pkg.ModuleDir)-1 {
 		moduleRoot += string(os.PathSeparator)
@@ -51,6 +101,14 @@ func (pkg Package) ImportPath() string {
 }
 
 func (pkg Package) ModuleRelativePath() string {
+	path := pkg.moduleRelativePath()
+	if pkg.IsTest {
+		return path + "_test"
+	}
+	return path
+}
+
+func (pkg Package) moduleRelativePath() string {
 	if strings.HasPrefix(pkg.DirName, pkg.ModuleDir) {
 		path := strings.TrimPrefix(
 			pkg.DirName,
@@ -109,7 +167,13 @@ type File struct {
 
 	IsStub        bool
 	IsBlankImport bool
+	// IsTest is set for _test.go files, of the package under test or of an
+	// external test package
+	IsTest        bool
 	InImportCycle bool
+	// SCC is the 1-based strongly connected component of the file graph
+	// the file belongs to, 0 if import cycles have not been marked up
+	SCC int
 }
 
 func (f File) HasDecl(decl *Decl) bool {
@@ -148,9 +212,18 @@ type Decl struct {
 
 	Name     string
 	FuncName string
+	// IsMethod is set for methods, FuncName is then the name of the
+	// receiver's type rather than of the function declaring the declaration
+	IsMethod bool
 }
 
+// UID is the qualified name, methods are marked by their receiver's type
+// name in parentheses, e.g. (T).M, as the declarations of a function M of
+// the same file are qualified as M.x
 func (decl Decl) UID() string {
+	if decl.IsMethod {
+		return "(" + decl.FuncName + ")." + decl.Name
+	}
 	return decl.QualifiedName()
 }
 
@@ -174,15 +247,30 @@ type Import struct {
 
 	IsAliased bool
 	IsBlank   bool
+	IsDot     bool
+	// IsImplicit is set when the file uses declarations of the package, e.g.
+	// methods of a value returned by another package, without importing it
+	IsImplicit bool
 
 	ReferencedTypes map[string]*Decl
+	// ReferencedTypesByDecl are the referenced types keyed by the qualified
+	// name of the top level declaration of the file referencing them, e.g.
+	// Fn or (T).Method for methods, see Decl.UID
+	ReferencedTypesByDecl map[string]map[string]*Decl
 
 	InImportCycle          bool
 	ReferencedFilesInCycle map[string]*File
+	// SCC is the 1-based strongly connected component of the package graph
+	// containing both the importing and the imported package, 0 if the
+	// import is not part of an import cycle
+	SCC int
 }
 
 func (i Import) UID() string {
-	if i.IsBlank {
+	if i.IsImplicit {
+		return i.Path
+	}
+	if i.IsBlank || i.IsDot {
 		return i.Alias + i.Name
 	}
 	if i.IsAliased {
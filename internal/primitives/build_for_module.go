@@ -1,24 +1,166 @@
 package primitives
 
 import (
+	"cmp"
+	"errors"
 	"fmt"
 	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/modfile"
 	"go/ast"
+	"go/build"
 	"go/parser"
 	"go/token"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// WorkspaceModule is one of the modules used by a workspace
+type WorkspaceModule struct {
+	Path string
+	Dir  string
+}
+
+// BuildForModule parses the files of the module built for the target, see
+// WithGOOS, WithGOARCH and WithBuildTags, and their tests, see WithTests
 func BuildForModule(
 	modulePath,
 	moduleDir string,
+	opts ...Option,
+) ([]*internal.Package, error) {
+	return BuildForWorkspace(
+		[]WorkspaceModule{{Path: modulePath, Dir: moduleDir}},
+		opts...,
+	)
+}
+
+// BuildForWorkspace parses the files of every module of the workspace like
+// BuildForModule, imports between the modules resolve to their packages
+// rather than to stubs
+func BuildForWorkspace(
+	modules []WorkspaceModule,
+	opts ...Option,
 ) ([]*internal.Package, error) {
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no modules to build")
+	}
+	options := buildOptions(opts)
+	ctx := options.buildContext()
+	fset := token.NewFileSet()
+
+	parsedModules := make([]*parsedModule, 0, len(modules))
+	var files []*parsedFile
+	for _, module := range modules {
+		parsed, err := listModule(ctx, module, options)
+		if err != nil {
+			return nil, err
+		}
+		parsedModules = append(parsedModules, parsed)
+		files = append(files, parsed.files()...)
+	}
+
+	// files are parsed and walked concurrently, the builder is fed their
+	// nodes in the order of the files afterward so stubs are fixed up the
+	// same way every run
+	cache := newFileCache(options.cacheDir)
+	err := parseFiles(fset, options.cache, cache, files, options.jobCount())
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range parsedModules {
+		module.sortFiles()
+	}
+	err = walkFiles(fset, options.cache, cache, files, importNamesForModules(parsedModules, options, cache), options.jobCount())
+	if err != nil {
+		return nil, err
+	}
+	cache.trim(time.Now())
+
+	builder := NewPrimitiveBuilder(modules[0].Path, modules[0].Dir)
+	err = addRequires(builder, modules)
+	if err != nil {
+		return nil, err
+	}
+	for _, module := range parsedModules {
+		builder.UseModule(module.Path, module.Dir)
+		for _, dirToParse := range module.dirs {
+			pkgsSeen := map[string]bool{}
+			for _, file := range module.filesByDir[dirToParse] {
+				filename, name := file.filename, file.packageName
+				if _, seen := pkgsSeen[name]; !seen {
+					err := builder.AddNode(&Package{
+						Package: &ast.Package{
+							Name: name,
+						},
+						DirName: dirToParse,
+						IsTest:  file.isExternalTest(),
+					})
+					if err != nil {
+						return nil, fmt.Errorf("add package: %s: %w", filename, err)
+					}
+					pkgsSeen[name] = true
+				}
+
+				err := builder.AddNode(&File{
+					File:    &ast.File{},
+					AbsPath: filename,
+					DirName: dirToParse,
+					IsTest:  file.isTest,
+				})
+				if err != nil {
+					return nil, fmt.Errorf("add file: %s: %w", filename, err)
+				}
+				for _, node := range file.nodes {
+					err = builder.AddNode(node)
+					if err != nil {
+						return nil, fmt.Errorf("add node: %s: %w", filename, err)
+					}
+				}
+			}
+		}
+	}
+	err = builder.MarkupImportCycles()
+	if err != nil {
+		return nil, err
+	}
+	return builder.Packages(), nil
+}
+
+// addRequires adds the modules required by the go.mod files of the modules
+func addRequires(builder *PrimitiveBuilder, modules []WorkspaceModule) error {
+	for _, module := range modules {
+		required, err := modfile.GetRequiredModules(filepath.Join(module.Dir, "go.mod"))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get required modules: %s: %w", module.Path, err)
+		}
+		for _, require := range required {
+			builder.AddRequire(require.Path, require.Version)
+		}
+	}
+	return nil
+}
+
+type parsedModule struct {
+	WorkspaceModule
+
+	dirs       []string
+	filesByDir map[string][]*parsedFile
+}
+
+// listModule lists the files of every directory of the module built for the
+// target, nested modules are left to the workspace
+func listModule(ctx *build.Context, module WorkspaceModule, options *options) (*parsedModule, error) {
 	var dirsToParse []string
 	err := filepath.WalkDir(
-		moduleDir,
+		module.Dir,
 		func(path string, d fs.DirEntry, err error) error {
 			if err != nil {
 				return err
@@ -32,6 +174,11 @@ func(path string, d fs.DirEntry, err error) error {
 			if strings.HasPrefix(d.Name(), "_") {
 				return fs.SkipDir
 			}
+			if path != module.Dir {
+				if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+					return fs.SkipDir
+				}
+			}
 			path, err = filepath.Abs(path)
 			if err != nil {
 				return err
@@ -44,9 +191,7 @@ func(path string, d fs.DirEntry, err error) error {
 		return nil, fmt.Errorf("get packages: %w", err)
 	}
 
-	depVis := NewDependencyVisitor()
-	builder := NewPrimitiveBuilder(modulePath, moduleDir)
-	fset := token.NewFileSet()
+	filesByDir := make(map[string][]*parsedFile, len(dirsToParse))
 	// copied parser.ParseDir because we want to handle files and packages manually
 	for _, dirToParse := range dirsToParse {
 		list, err := os.ReadDir(dirToParse)
@@ -54,55 +199,230 @@ func(path string, d fs.DirEntry, err error) error {
 			return nil, err
 		}
 
-		pkgsSeen := map[string]bool{}
+		var files []*parsedFile
 		for _, d := range list {
 			if d.IsDir() ||
 				strings.HasPrefix(d.Name(), ".") ||
-				!strings.HasSuffix(d.Name(), ".go") ||
-				strings.HasSuffix(d.Name(), "_test.go") {
+				!strings.HasSuffix(d.Name(), ".go") {
 				continue
 			}
-
-			filename := filepath.Join(dirToParse, d.Name())
-			src, err := parser.ParseFile(fset, filename, nil, 0)
+			isTest := strings.HasSuffix(d.Name(), "_test.go")
+			if isTest && !options.tests {
+				continue
+			}
+			// build constraints and GOOS/GOARCH file name suffixes
+			match, err := ctx.MatchFile(dirToParse, d.Name())
 			if err != nil {
-				return nil, fmt.Errorf("parse error: %s: %w", filename, err)
+				return nil, fmt.Errorf("match file: %s: %w", filepath.Join(dirToParse, d.Name()), err)
 			}
-			name := src.Name.Name
-			if _, seen := pkgsSeen[name]; !seen {
-				err = builder.AddNode(&Package{
-					Package: &ast.Package{
-						Name: name,
-					},
-					DirName: dirToParse,
-				})
-				if err != nil {
-					return nil, fmt.Errorf("add package: %s: %w", filename, err)
-				}
-				pkgsSeen[name] = true
+			if !match {
+				continue
 			}
 
-			err = builder.AddNode(&File{
-				File:    &ast.File{},
-				AbsPath: filename,
-				DirName: dirToParse,
+			files = append(files, &parsedFile{
+				modulePath: module.Path,
+				filename:   filepath.Join(dirToParse, d.Name()),
+				isTest:     isTest,
 			})
-			if err != nil {
-				return nil, fmt.Errorf("add file: %s: %w", filename, err)
+		}
+		filesByDir[dirToParse] = files
+	}
+	return &parsedModule{
+		WorkspaceModule: module,
+		dirs:            dirsToParse,
+		filesByDir:      filesByDir,
+	}, nil
+}
+
+// files are the files of the module in the order they are added to the
+// builder
+func (module *parsedModule) files() []*parsedFile {
+	var files []*parsedFile
+	for _, dir := range module.dirs {
+		files = append(files, module.filesByDir[dir]...)
+	}
+	return files
+}
+
+// sortFiles orders the parsed files of each directory for the builder, which
+// adds files to the last package added, the external test package follows
+// the package under test
+func (module *parsedModule) sortFiles() {
+	for _, files := range module.filesByDir {
+		slices.SortStableFunc(files, func(a, b *parsedFile) int {
+			return cmp.Compare(a.externalTestOrder(), b.externalTestOrder())
+		})
+	}
+}
+
+// parseFiles parses up to jobs files at once, files found in the memory
+// cache are left unread and files found in the cache are left unparsed.
+// Object resolution is left out, the visitor doesn't use it. The error of
+// the first file failing to parse is returned.
+func parseFiles(fset *token.FileSet, memory *Cache, cache *fileCache, files []*parsedFile, jobs int) error {
+	errs := make([]error, len(files))
+	forEachFile(files, jobs, func(i int, file *parsedFile) {
+		if entry, ok := memory.load(file.filename); ok {
+			file.packageName = entry.PackageName
+			file.importPaths = entry.ImportPaths
+			file.cached = entry
+			return
+		}
+		src, err := os.ReadFile(file.filename)
+		if err != nil {
+			errs[i] = err
+			return
+		}
+		if cache != nil {
+			file.cacheKey = cache.key(file.modulePath, src)
+			entry := &cacheEntry{}
+			if cache.load(file.cacheKey, entry) {
+				file.packageName = entry.PackageName
+				file.importPaths = entry.ImportPaths
+				file.cached = entry
+				return
 			}
-			depVis.Reset()
-			ast.Walk(depVis, src)
-			for _, node := range depVis.InOrderNodes() {
-				err = builder.AddNode(node)
-				if err != nil {
-					return nil, fmt.Errorf("add node: %s: %w", filename, err)
+		}
+		errs[i] = file.parse(fset, src)
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// walkFiles collects the nodes of up to jobs files at once. The nodes of
+// cached files are reused unless an import name changed since, the nodes
+// of every other file are cached.
+func walkFiles(fset *token.FileSet, memory *Cache, cache *fileCache, files []*parsedFile, importNames ImportNames, jobs int) error {
+	errs := make([]error, len(files))
+	forEachFile(files, jobs, func(i int, file *parsedFile) {
+		if file.cached != nil && file.cached.matches(importNames) {
+			file.nodes = decodeNodes(file.cached.Nodes)
+			memory.store(file.filename, file.cached)
+			return
+		}
+		if file.src == nil {
+			if err := file.parse(fset, nil); err != nil {
+				errs[i] = err
+				return
+			}
+		}
+		depVis := NewDependencyVisitorWithImportNames(importNames)
+		ast.Walk(depVis, file.src)
+		file.nodes = depVis.InOrderNodes()
+		if memory == nil && cache == nil {
+			return
+		}
+		entry, ok := newCacheEntry(file, importNames)
+		if !ok {
+			return
+		}
+		memory.store(file.filename, entry)
+		// files found in the memory cache weren't read, so they have no key
+		if cache != nil && file.cacheKey != "" {
+			cache.store(file.cacheKey, entry)
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachFile calls fn for every file from up to jobs goroutines
+func forEachFile(files []*parsedFile, jobs int, fn func(int, *parsedFile)) {
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for range min(jobs, len(files)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i, files[i])
+			}
+		}()
+	}
+	for i := range files {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+}
+
+// importNamesForModules reads the package clauses of the modules' packages
+// and lists the packages imported from outside of the modules
+func importNamesForModules(modules []*parsedModule, options *options, cache *fileCache) ImportNames {
+	names := make(ImportNames)
+	var importPaths []string
+	for _, module := range modules {
+		for _, dir := range module.dirs {
+			for _, file := range module.filesByDir[dir] {
+				importPaths = append(importPaths, file.importPaths...)
+				if file.isExternalTest() || file.packageName == "main" {
+					continue
 				}
+				pkg := buildPackage(module.Path, module.Dir, dir, file.packageName, 0)
+				names[pkg.ImportPath()] = pkg.Name
 			}
 		}
 	}
-	err = builder.MarkupImportCycles()
+	names.resolveExternal(modules, importPaths, options, cache)
+	return names
+}
+
+type parsedFile struct {
+	modulePath string
+	filename   string
+	src        *ast.File
+	isTest     bool
+
+	// packageName and importPaths are read from the source, or the cache,
+	// before the file is walked
+	packageName string
+	importPaths []string
+
+	// cacheKey and cached are set when the file is cached, see fileCache and
+	// Cache
+	cacheKey string
+	cached   *cacheEntry
+
+	// nodes are the nodes found by the visitor in order
+	nodes []ast.Node
+}
+
+// parse parses the source, read from the file when nil
+func (file *parsedFile) parse(fset *token.FileSet, src []byte) error {
+	parsed, err := parser.ParseFile(fset, file.filename, src, parser.SkipObjectResolution)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("parse error: %s: %w", file.filename, err)
 	}
-	return builder.Packages(), nil
+	file.src = parsed
+	file.packageName = parsed.Name.Name
+	file.importPaths = nil
+	for _, spec := range parsed.Imports {
+		importPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		file.importPaths = append(file.importPaths, importPath)
+	}
+	return nil
+}
+
+// isExternalTest reports whether the file belongs to an external test
+// package, e.g. foo_test
+func (file *parsedFile) isExternalTest() bool {
+	return file.isTest && strings.HasSuffix(file.packageName, "_test")
+}
+
+func (file *parsedFile) externalTestOrder() int {
+	if file.isExternalTest() {
+		return 1
+	}
+	return 0
 }
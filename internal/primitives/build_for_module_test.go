@@ -3,14 +3,18 @@
 import (
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/json"
 	"github.com/samlitowitz/godepvis/internal/modfile"
 	"github.com/samlitowitz/godepvis/internal/primitives"
 	"github.com/samlitowitz/godepvis/internal/test"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildForModule(t *testing.T) {
@@ -315,12 +319,19 @@ func(x, y string) bool {
 		"direct-circular-dependency-with-fn-receivers": {
 			dir: "direct-circular-dependency-with-fn-receivers",
 			expectedDecls: map[string][]string{
-				"github.com/fake/fake/a": {"A"},
-				"github.com/fake/fake/b": {"B"},
+				"github.com/fake/fake/a": {"A", "(A).Fn"},
+				"github.com/fake/fake/b": {"B", "(B).Fn"},
 				"log":                    {"Println"},
 				"main":                   {"main"},
 			},
 		},
+		"with-method-and-func-scope-names": {
+			dir: "with-method-and-func-scope-names",
+			expectedDecls: map[string][]string{
+				"github.com/fake/fake/a": {"T", "M", "(T).M", "M.x", "(M).x"},
+				"main":                   {"main"},
+			},
+		},
 		"multiple-independent-direct-circular-dependencies": {
 			dir: "multiple-independent-direct-circular-dependencies",
 			expectedDecls: map[string][]string{
@@ -412,7 +423,7 @@ func(x, y string) bool {
 		"with-generics": {
 			dir: "with-generics",
 			expectedDecls: map[string][]string{
-				"github.com/fake/fake/a": {"IsGreater", "Popper", "Stack", "Number", "Slice", "Clip"},
+				"github.com/fake/fake/a": {"IsGreater", "Popper", "Stack", "(Stack).Push", "(Stack).Pop", "Number", "Slice", "(Slice).Map", "Clip"},
 				"github.com/fake/fake/b": {"Fn", "gtFn", "gtV", "st", "sl", "c", "Sum", "Sum.s", "Product", "Product.s"},
 				"github.com/fake/fake/c": {"Fn1", "Fn2", "Fn3"},
 				"log":                    {"Println"},
@@ -505,3 +516,879 @@ func() {
 		}()
 	}
 }
+
+func TestBuildForModule_WithCorrectImportCycles(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	testCases := map[string]struct {
+		dir                     string
+		expectedCyclesByPackage []string
+	}{
+		"direct-circular-dependency": {
+			dir:                     "direct-circular-dependency",
+			expectedCyclesByPackage: []string{"a,b"},
+		},
+		"direct-circular-dependency-blank-identifiers": {
+			dir:                     "direct-circular-dependency-blank-identifiers",
+			expectedCyclesByPackage: []string{"a,b"},
+		},
+		"multiple-independent-direct-circular-dependencies": {
+			dir:                     "multiple-independent-direct-circular-dependencies",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+		"multiple-interlinked-direct-circular-dependencies": {
+			dir:                     "multiple-interlinked-direct-circular-dependencies",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+		"no-circular-dependencies": {
+			dir: "no-circular-dependencies",
+		},
+		"no-circular-dependencies-with-blank-identifier": {
+			dir: "no-circular-dependencies-with-blank-identifier",
+		},
+		"transitive-circular-dependency": {
+			dir:                     "transitive-circular-dependency",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			// REFURL: https://github.com/golang/go/blob/988b718f4130ab5b3ce5a5774e1a58e83c92a163/src/path/filepath/path_test.go#L600
+			// -- START -- //
+			if runtime.GOOS == "ios" {
+				restore := test.Chtmpdir(t)
+				defer restore()
+			}
+
+			tmpDir := t.TempDir()
+
+			origDir, err := os.Getwd()
+			if err != nil {
+				t.Fatal("finding working dir:", err)
+			}
+			if err = os.Chdir(tmpDir); err != nil {
+				t.Fatal("entering temp dir:", err)
+			}
+			defer os.Chdir(origDir)
+			// -- END -- //
+
+			err = os.CopyFS(tmpDir, os.DirFS(filepath.Join(origDir, "testdata", "build-for-module", testCase.dir)))
+			if err != nil {
+				t.Fatal("copy test data:", err)
+			}
+
+			moduleDir := tmpDir
+
+			goModFile, err := modfile.FindGoModFile(moduleDir)
+			if err != nil {
+				t.Fatal(desc, ": failed to find go.mod: ", err)
+			}
+			modulePath, err := modfile.GetModulePath(goModFile)
+			if err != nil {
+				t.Fatal(desc, ": failed to get module path: ", err)
+			}
+
+			actualPkgs, err := primitives.BuildForModule(modulePath, moduleDir)
+			if err != nil {
+				t.Fatal(desc, ": BuildForModule: ", err)
+			}
+
+			pkgsBySCC := make(map[int][]string)
+			for _, pkg := range actualPkgs {
+				if pkg.SCC == 0 {
+					t.Error(desc, ": package not assigned a strongly connected component: ", pkg.Name)
+				}
+				if !pkg.InImportCycle {
+					continue
+				}
+				pkgsBySCC[pkg.SCC] = append(pkgsBySCC[pkg.SCC], pkg.Name)
+			}
+			var actualCyclesByPackage []string
+			for _, names := range pkgsBySCC {
+				slices.Sort(names)
+				actualCyclesByPackage = append(actualCyclesByPackage, strings.Join(names, ","))
+			}
+
+			if diff := cmp.Diff(testCase.expectedCyclesByPackage, actualCyclesByPackage, opts); diff != "" {
+				t.Error(desc, test.Mismatch(": expected cycles by package: ", diff))
+			}
+		}()
+	}
+}
+
+func TestBuildForModule_WithBuildConstraints(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir             string
+		opts            []primitives.Option
+		expectedImports []string
+	}{
+		"linux": {
+			dir:             "with-build-constraints",
+			opts:            []primitives.Option{primitives.WithGOOS("linux"), primitives.WithGOARCH("amd64")},
+			expectedImports: []string{"a/a_linux.go: github.com/fake/fake/b", "main.go: github.com/fake/fake/a", "main.go: log"},
+		},
+		"windows": {
+			dir:             "with-build-constraints",
+			opts:            []primitives.Option{primitives.WithGOOS("windows"), primitives.WithGOARCH("amd64")},
+			expectedImports: []string{"a/a_windows.go: github.com/fake/fake/c", "main.go: github.com/fake/fake/a", "main.go: log"},
+		},
+		"linux with build tags": {
+			dir: "with-build-constraints",
+			opts: []primitives.Option{
+				primitives.WithGOOS("linux"),
+				primitives.WithGOARCH("amd64"),
+				primitives.WithBuildTags("extra"),
+			},
+			expectedImports: []string{
+				"a/a_linux.go: github.com/fake/fake/b",
+				"a/extra.go: github.com/fake/fake/d",
+				"main.go: github.com/fake/fake/a",
+				"main.go: log",
+			},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir, testCase.opts...)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				var actualImports []string
+				for _, pkg := range actualPkgs {
+					for _, file := range pkg.Files {
+						if file.IsStub {
+							continue
+						}
+						relPath, err := filepath.Rel(moduleDir, file.AbsPath)
+						if err != nil {
+							t.Fatal(desc, ": ", err)
+						}
+						for _, imp := range file.Imports {
+							actualImports = append(actualImports, filepath.ToSlash(relPath)+": "+imp.Path)
+						}
+					}
+				}
+
+				if diff := cmp.Diff(testCase.expectedImports, actualImports, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected imports: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithTests(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir                     string
+		opts                    []primitives.Option
+		expectedTestFiles       []string
+		expectedCyclesByPackage []string
+	}{
+		"without tests": {
+			dir: "with-test-cycles",
+		},
+		"with tests": {
+			dir:                     "with-test-cycles",
+			opts:                    []primitives.Option{primitives.WithTests()},
+			expectedTestFiles:       []string{"foo: foo_internal_test.go", "foo_test: foo_test.go"},
+			expectedCyclesByPackage: []string{"foo,helpers"},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir, testCase.opts...)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				var actualTestFiles []string
+				pkgsBySCC := make(map[int][]string)
+				for _, pkg := range actualPkgs {
+					if pkg.IsTest != strings.HasSuffix(pkg.Name, "_test") {
+						t.Error(desc, ": package test flag mismatch: ", pkg.Name)
+					}
+					for _, file := range pkg.Files {
+						if file.IsTest {
+							actualTestFiles = append(actualTestFiles, pkg.ModuleRelativePath()+": "+file.FileName)
+						}
+					}
+					if pkg.InImportCycle {
+						pkgsBySCC[pkg.SCC] = append(pkgsBySCC[pkg.SCC], pkg.Name)
+					}
+				}
+				var actualCyclesByPackage []string
+				for _, names := range pkgsBySCC {
+					slices.Sort(names)
+					actualCyclesByPackage = append(actualCyclesByPackage, strings.Join(names, ","))
+				}
+
+				if diff := cmp.Diff(testCase.expectedTestFiles, actualTestFiles, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected test files: ", diff))
+				}
+				if diff := cmp.Diff(testCase.expectedCyclesByPackage, actualCyclesByPackage, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected cycles by package: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithCorrectImportNames(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir                string
+		expectedReferences map[string][]string
+	}{
+		"with-import-names": {
+			dir: "with-import-names",
+			expectedReferences: map[string][]string{
+				"main.go: extension": {"Fn"},
+				"main.go: helpers":   {"Fn"},
+				"main.go: lib":       {"Fn"},
+				"main.go: rand":      {"Int"},
+			},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				actualReferences := make(map[string][]string)
+				for _, pkg := range actualPkgs {
+					for _, file := range pkg.Files {
+						for _, imp := range file.Imports {
+							key := file.FileName + ": " + imp.Name
+							for declUID := range imp.ReferencedTypes {
+								actualReferences[key] = append(actualReferences[key], declUID)
+							}
+						}
+					}
+				}
+
+				if diff := cmp.Diff(testCase.expectedReferences, actualReferences, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected references: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_ListsImportNamesOnce(t *testing.T) {
+	modulePath, moduleDir := copyModule(t, "with-import-names", "with-import-names")
+
+	// without the go command the name of the external import can only be
+	// known from the first build
+	for _, path := range []string{os.Getenv("PATH"), ""} {
+		t.Setenv("PATH", path)
+		pkgs, err := primitives.BuildForModule(modulePath, moduleDir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+		for _, pkg := range pkgs {
+			for _, file := range pkg.Files {
+				for _, imp := range file.Imports {
+					if imp.Package.DirName == "github.com/fake/ext/v2" {
+						names = append(names, imp.Name)
+					}
+				}
+			}
+		}
+		if diff := cmp.Diff([]string{"extension"}, names); diff != "" {
+			t.Error(test.Mismatch("PATH="+path+": expected import names: ", diff))
+		}
+	}
+}
+
+func TestBuildForModule_WithRequiredModules(t *testing.T) {
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir             string
+		expectedModules map[string]string
+	}{
+		"with-third-party-modules": {
+			dir: "with-third-party-modules",
+			expectedModules: map[string]string{
+				"fmt":                       "std",
+				"strings":                   "std",
+				"github.com/fake/ext":       "github.com/fake/ext@v1.0.0 stub",
+				"github.com/fake/fake/a":    "github.com/fake/fake",
+				"github.com/fake/fake/b":    "github.com/fake/fake",
+				"github.com/fake/other/sub": "github.com/fake/other@v1.2.0 stub",
+			},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				actualModules := make(map[string]string)
+				for _, pkg := range actualPkgs {
+					if pkg.Module == nil {
+						t.Error(desc, ": ", pkg.UID(), ": expected a module")
+						continue
+					}
+					mod := pkg.Module.Path
+					switch {
+					case pkg.Module.IsStdlib:
+					case pkg.Module.IsStub:
+						mod += "@" + pkg.Module.Version + " stub"
+					}
+					actualModules[pkg.UID()] = mod
+				}
+
+				if diff := cmp.Diff(testCase.expectedModules, actualModules); diff != "" {
+					t.Error(desc, test.Mismatch(": expected modules: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithReferencesByDecl(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir                string
+		expectedReferences map[string][]string
+	}{
+		"with-decl-references": {
+			dir: "with-decl-references",
+			expectedReferences: map[string][]string{
+				"a/a.go: V":     {"G"},
+				"a/a.go: T":     {"B"},
+				"a/a.go: (T).M": {"H"},
+				"a/a.go: Fn":    {"G"},
+				"b/b.go: K":     {"Fn"},
+				"main.go: main": {"Fn", "T"},
+			},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				actualReferences := make(map[string][]string)
+				for _, pkg := range actualPkgs {
+					for _, file := range pkg.Files {
+						if file.IsStub {
+							continue
+						}
+						relPath, err := filepath.Rel(moduleDir, file.AbsPath)
+						if err != nil {
+							t.Fatal(desc, ": ", err)
+						}
+						for _, imp := range file.Imports {
+							for enclosing, decls := range imp.ReferencedTypesByDecl {
+								key := filepath.ToSlash(relPath) + ": " + enclosing
+								for _, decl := range decls {
+									actualReferences[key] = append(actualReferences[key], decl.Name)
+								}
+							}
+						}
+					}
+				}
+
+				if diff := cmp.Diff(testCase.expectedReferences, actualReferences, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected references: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithMethodReferences(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir string
+		// expectedReferences are the referenced declarations, prefixed with
+		// the file declaring them, by file and enclosing declaration
+		expectedReferences map[string][]string
+	}{
+		"with-method-references": {
+			dir: "with-method-references",
+			expectedReferences: map[string][]string{
+				"main.go: main": {
+					"methods.go: (Thing).Method",
+					"thing.go: Base",
+					"thing.go: Default",
+					"thing.go: NewThing",
+					"thing.go: Thing",
+				},
+			},
+		},
+		// both types declare String and Name, they are told apart by the type
+		// of the composite literal or of the variable
+		"with-shared-member-names": {
+			dir: "with-shared-member-names",
+			expectedReferences: map[string][]string{
+				"main.go: circle":       {"circle.go: Circle"},
+				"main.go: circleString": {"circle.go: (Circle).String"},
+				"main.go: literal":      {"square.go: (Square).String", "square.go: Square"},
+				"main.go: local":        {"circle.go: (Circle).String", "circle.go: Circle"},
+				"main.go: parameter":    {"square.go: (Square).String", "square.go: Square"},
+				"main.go: square":       {"square.go: Square"},
+				"main.go: squareName":   {"square.go: Square"},
+			},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				actualReferences := make(map[string][]string)
+				for _, pkg := range actualPkgs {
+					for _, file := range pkg.Files {
+						if file.IsStub {
+							continue
+						}
+						for _, imp := range file.Imports {
+							for enclosing, decls := range imp.ReferencedTypesByDecl {
+								key := file.FileName + ": " + enclosing
+								for _, decl := range decls {
+									actualReferences[key] = append(actualReferences[key], decl.File.FileName+": "+decl.UID())
+								}
+							}
+						}
+					}
+				}
+
+				if diff := cmp.Diff(testCase.expectedReferences, actualReferences, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected references: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithDotImports(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func(string, string, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForModule":          primitives.BuildForModule,
+		"BuildForModuleWithTypes": primitives.BuildForModuleWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir string
+		// expectedReferences are the referenced declarations, prefixed with
+		// the file declaring them, by file and enclosing declaration
+		expectedReferences map[string][]string
+		// expectedFilesInCycle are the files in an import cycle
+		expectedFilesInCycle []string
+	}{
+		"with-dot-imports": {
+			dir: "with-dot-imports",
+			expectedReferences: map[string][]string{
+				"description.go: Description": {"design.go: Name"},
+				"design.go: Attr":             {"dsl.go: Attribute", "dsl.go: String"},
+				"design.go: Design":           {"description.go: Description"},
+				"main.go: main":               {"design.go: Design"},
+			},
+			expectedFilesInCycle: []string{"description.go", "design.go"},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modulePath, moduleDir)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				actualReferences := make(map[string][]string)
+				var actualFilesInCycle []string
+				for _, pkg := range actualPkgs {
+					for _, file := range pkg.Files {
+						if file.IsStub {
+							continue
+						}
+						if file.InImportCycle {
+							actualFilesInCycle = append(actualFilesInCycle, file.FileName)
+						}
+						for _, imp := range file.Imports {
+							for enclosing, decls := range imp.ReferencedTypesByDecl {
+								key := file.FileName + ": " + enclosing
+								for _, decl := range decls {
+									actualReferences[key] = append(actualReferences[key], decl.File.FileName+": "+decl.UID())
+								}
+							}
+						}
+					}
+				}
+
+				if diff := cmp.Diff(testCase.expectedReferences, actualReferences, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected references: ", diff))
+				}
+				if diff := cmp.Diff(testCase.expectedFilesInCycle, actualFilesInCycle, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected files in cycle: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+func TestBuildForModule_WithJobs(t *testing.T) {
+	testCases := map[string]struct {
+		dir string
+	}{
+		"multiple-interlinked-direct-circular-dependencies": {
+			dir: "multiple-interlinked-direct-circular-dependencies",
+		},
+		"with-dot-imports": {
+			dir: "with-dot-imports",
+		},
+		"with-method-references": {
+			dir: "with-method-references",
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+			var outputs []string
+			for _, jobs := range []int{1, 8} {
+				pkgs, err := primitives.BuildForModule(modulePath, moduleDir, primitives.WithJobs(jobs))
+				if err != nil {
+					t.Fatal(desc, ": BuildForModule: ", err)
+				}
+				output, err := json.Marshal(modulePath, pkgs)
+				if err != nil {
+					t.Fatal(desc, ": Marshal: ", err)
+				}
+				outputs = append(outputs, string(output))
+			}
+
+			if diff := cmp.Diff(outputs[0], outputs[1]); diff != "" {
+				t.Error(desc, test.Mismatch(": expected the same output for every job count: ", diff))
+			}
+		}()
+	}
+}
+
+func TestBuildForModule_WithCacheDir(t *testing.T) {
+	testCases := map[string]struct {
+		dir string
+		// edit is a file rewritten between runs, relative to the module
+		edit    string
+		content string
+	}{
+		"with-dot-imports": {
+			dir:  "with-dot-imports",
+			edit: "design/design.go",
+			content: `package design
+
+const Name = "design"
+
+func Design() {}
+`,
+		},
+		"with-method-references": {
+			dir:  "with-method-references",
+			edit: "main.go",
+			content: `package main
+
+import "github.com/fake/fake/thing"
+
+func main() {
+	_ = thing.NewThing()
+}
+`,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+			cacheDir := t.TempDir()
+
+			marshal := func(opts ...primitives.Option) string {
+				t.Helper()
+				pkgs, err := primitives.BuildForModule(modulePath, moduleDir, opts...)
+				if err != nil {
+					t.Fatal(desc, ": BuildForModule: ", err)
+				}
+				output, err := json.Marshal(modulePath, pkgs)
+				if err != nil {
+					t.Fatal(desc, ": Marshal: ", err)
+				}
+				return string(output)
+			}
+
+			// the second run reads every file from the cache
+			for range 2 {
+				if diff := cmp.Diff(marshal(), marshal(primitives.WithCacheDir(cacheDir))); diff != "" {
+					t.Error(desc, test.Mismatch(": expected the same output with the cache: ", diff))
+				}
+			}
+
+			err := os.WriteFile(filepath.Join(moduleDir, testCase.edit), []byte(testCase.content), 0o644)
+			if err != nil {
+				t.Fatal(desc, ": edit: ", err)
+			}
+			if diff := cmp.Diff(marshal(), marshal(primitives.WithCacheDir(cacheDir))); diff != "" {
+				t.Error(desc, test.Mismatch(": expected the same output with the cache after an edit: ", diff))
+			}
+		}()
+	}
+}
+
+func TestBuildForModule_WithCache(t *testing.T) {
+	modulePath, moduleDir := copyModule(t, "with cache", "with-method-references")
+	cache := primitives.NewCache()
+
+	marshal := func(opts ...primitives.Option) string {
+		t.Helper()
+		pkgs, err := primitives.BuildForModule(modulePath, moduleDir, opts...)
+		if err != nil {
+			t.Fatal("BuildForModule: ", err)
+		}
+		output, err := json.Marshal(modulePath, pkgs)
+		if err != nil {
+			t.Fatal("Marshal: ", err)
+		}
+		return string(output)
+	}
+
+	before := marshal(primitives.WithCache(cache))
+	mainFile := filepath.Join(moduleDir, "main.go")
+	err := os.WriteFile(mainFile, []byte(`package main
+
+import "github.com/fake/fake/thing"
+
+func main() {
+	_ = thing.NewThing()
+}
+`), 0o644)
+	if err != nil {
+		t.Fatal("edit: ", err)
+	}
+
+	// the edit isn't read until the file is forgotten
+	if diff := cmp.Diff(before, marshal(primitives.WithCache(cache))); diff != "" {
+		t.Error(test.Mismatch("expected the cached output before forgetting the edit: ", diff))
+	}
+	cache.Forget(mainFile)
+	after := marshal()
+	if before == after {
+		t.Fatal("expected the edit to change the output")
+	}
+	if diff := cmp.Diff(after, marshal(primitives.WithCache(cache))); diff != "" {
+		t.Error(test.Mismatch("expected the edit after forgetting it: ", diff))
+	}
+
+	// a go.mod file changing forgets every file
+	err = os.WriteFile(filepath.Join(moduleDir, "thing", "methods.go"), []byte("package thing\n"), 0o644)
+	if err != nil {
+		t.Fatal("edit: ", err)
+	}
+	cache.Forget(filepath.Join(moduleDir, "go.mod"))
+	edited := marshal()
+	if edited == after {
+		t.Fatal("expected the edit to change the output")
+	}
+	if diff := cmp.Diff(edited, marshal(primitives.WithCache(cache))); diff != "" {
+		t.Error(test.Mismatch("expected every file to be read again: ", diff))
+	}
+}
+
+func TestBuildForModule_TrimsCacheDir(t *testing.T) {
+	modulePath, moduleDir := copyModule(t, "trims cache dir", "direct-circular-dependency")
+
+	build := func(cacheDir string) {
+		t.Helper()
+		_, err := primitives.BuildForModule(modulePath, moduleDir, primitives.WithCacheDir(cacheDir))
+		if err != nil {
+			t.Fatal("BuildForModule: ", err)
+		}
+	}
+	entries := func(cacheDir string) []string {
+		t.Helper()
+		paths, err := filepath.Glob(filepath.Join(cacheDir, "*", "*"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i, path := range paths {
+			paths[i], _ = filepath.Rel(cacheDir, path)
+		}
+		slices.Sort(paths)
+		return paths
+	}
+	// writeEntries writes the entries, last used days ago
+	writeEntries := func(cacheDir string, srcDir string, paths ...string) {
+		t.Helper()
+		modTime := time.Now().Add(-30 * 24 * time.Hour)
+		for _, path := range paths {
+			var src []byte
+			if srcDir != "" {
+				var err error
+				src, err = os.ReadFile(filepath.Join(srcDir, path))
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			dst := filepath.Join(cacheDir, path)
+			if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(dst, src, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.Chtimes(dst, modTime, modTime); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	filledDir := t.TempDir()
+	build(filledDir)
+	used := entries(filledDir)
+	if len(used) == 0 {
+		t.Fatal("expected the files to be cached")
+	}
+
+	// entries read by a build are kept, the others are removed once they
+	// haven't been used for days
+	cacheDir := t.TempDir()
+	writeEntries(cacheDir, filledDir, used...)
+	unused := filepath.Join("00", "00unused")
+	writeEntries(cacheDir, "", unused)
+	build(cacheDir)
+	if diff := cmp.Diff(used, entries(cacheDir)); diff != "" {
+		t.Error(test.Mismatch("expected the unused entries to be trimmed: ", diff))
+	}
+
+	// but not more than once a day
+	writeEntries(cacheDir, "", unused)
+	build(cacheDir)
+	if diff := cmp.Diff(append(slices.Clone(used), unused), entries(cacheDir), cmpopts.SortSlices(func(a, b string) bool { return a < b })); diff != "" {
+		t.Error(test.Mismatch("expected the entries to be kept until the next day: ", diff))
+	}
+}
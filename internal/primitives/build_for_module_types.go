@@ -0,0 +1,453 @@
+package primitives
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"golang.org/x/tools/go/packages"
+)
+
+const loadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedTypes |
+	packages.NeedSyntax |
+	packages.NeedTypesInfo |
+	packages.NeedDeps
+
+// Reference is a use, in a file, of a declaration of another package
+// resolved by the type checker
+type Reference struct {
+	FileUID string
+
+	// ImportUID is the UID of the file's import of the declaration's
+	// package, ImportPath and ImportName describe the implicit import added
+	// when the file doesn't import the package
+	ImportUID  string
+	ImportPath string
+	ImportName string
+
+	// DeclFileUID is the UID of the file declaring the declaration, empty
+	// for declarations outside the module
+	DeclFileUID string
+	Decl        internal.Decl
+
+	// EnclosingDecl is the UID of the top level declaration of
+	// the referencing file the reference is part of, empty outside of
+	// declarations
+	EnclosingDecl string
+
+	// pos is the position of the referenced object
+	pos token.Pos
+}
+
+// BuildForModuleWithTypes loads the module with go/packages and resolves
+// every referenced object to the file declaring it with go/types. Unlike
+// BuildForModule, identifiers shadowing imports, methods and fields
+// promoted through embedded types and values whose type is only inferred
+// are resolved exactly. Options select the target like BuildForModule.
+func BuildForModuleWithTypes(
+	modulePath,
+	moduleDir string,
+	opts ...Option,
+) ([]*internal.Package, error) {
+	return BuildForWorkspaceWithTypes(
+		[]WorkspaceModule{{Path: modulePath, Dir: moduleDir}},
+		opts...,
+	)
+}
+
+// BuildForWorkspaceWithTypes loads every module of the workspace like
+// BuildForModuleWithTypes, the workspace is found by the go command from the
+// directory of the first module
+func BuildForWorkspaceWithTypes(
+	modules []WorkspaceModule,
+	opts ...Option,
+) ([]*internal.Package, error) {
+	if len(modules) == 0 {
+		return nil, fmt.Errorf("no modules to build")
+	}
+	options := buildOptions(opts)
+	fset := token.NewFileSet()
+	patterns := []string{"./..."}
+	if len(modules) > 1 {
+		patterns = patterns[:0]
+		for _, module := range modules {
+			patterns = append(patterns, module.Path+"/...")
+		}
+	}
+	loaded, err := packages.Load(
+		&packages.Config{
+			Mode:       loadMode,
+			Dir:        modules[0].Dir,
+			Env:        options.env(),
+			BuildFlags: options.buildFlags(),
+			Fset:       fset,
+			Tests:      options.tests,
+		},
+		patterns...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("load packages: %w", err)
+	}
+	if options.tests {
+		loaded = testVariants(loaded)
+	}
+	slices.SortFunc(loaded, func(a, b *packages.Package) int {
+		return cmp.Compare(a.PkgPath, b.PkgPath)
+	})
+
+	// go/packages leaves out the import closing an import cycle, type check
+	// the packages of a cycle again against the packages loaded on their own
+	loadedByPath := make(map[string]*packages.Package)
+	packages.Visit(loaded, nil, func(pkg *packages.Package) {
+		loadedByPath[pkg.PkgPath] = pkg
+	})
+	for _, pkg := range loaded {
+		if len(pkg.Errors) == 0 {
+			continue
+		}
+		recheck(fset, pkg, loadedByPath)
+	}
+
+	importNames := make(ImportNames, len(loadedByPath))
+	for importPath, pkg := range loadedByPath {
+		importNames[importPath] = pkg.Name
+	}
+	depVis := NewDependencyVisitorWithImportNames(importNames)
+	builder := NewPrimitiveBuilder(modules[0].Path, modules[0].Dir)
+	err = addRequires(builder, modules)
+	if err != nil {
+		return nil, err
+	}
+	index := &declIndex{fset: fset}
+	var refs []*Reference
+	for _, pkg := range loaded {
+		if len(pkg.Syntax) == 0 {
+			continue
+		}
+		dirName := filepath.Dir(fset.File(pkg.Syntax[0].Pos()).Name())
+		module := moduleOfDir(modules, dirName)
+		builder.UseModule(module.Path, module.Dir)
+		err = builder.AddNode(&Package{
+			Package: &ast.Package{
+				Name: pkg.Name,
+			},
+			DirName: dirName,
+			IsTest:  isExternalTest(pkg),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("add package: %s: %w", pkg.PkgPath, err)
+		}
+
+		for _, src := range pkg.Syntax {
+			filename := fset.File(src.Pos()).Name()
+			err = builder.AddNode(&File{
+				File:    &ast.File{},
+				AbsPath: filename,
+				DirName: dirName,
+				IsTest:  strings.HasSuffix(filename, "_test.go"),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("add file: %s: %w", filename, err)
+			}
+
+			// the visitor rewrites the import specs, collect references first
+			refs = append(refs, collectReferences(pkg, filename, src)...)
+			index.addFile(filename, src)
+
+			depVis.Reset()
+			ast.Walk(depVis, src)
+			for _, node := range depVis.InOrderNodes() {
+				// references are resolved by the type checker instead
+				switch node.(type) {
+				case *SelectorExpr, *Ident:
+					continue
+				}
+				err = builder.AddNode(node)
+				if err != nil {
+					return nil, fmt.Errorf("add node: %s: %w", filename, err)
+				}
+			}
+		}
+	}
+
+	for _, ref := range refs {
+		if fileUID, decl, ok := index.lookup(ref.pos); ok {
+			ref.DeclFileUID = fileUID
+			ref.Decl = decl
+		}
+		// e.g. a field of a struct declared outside of the module
+		if ref.Decl.Name == "" {
+			continue
+		}
+		err = builder.AddReference(ref)
+		if err != nil {
+			return nil, fmt.Errorf("add reference: %s: %w", ref.FileUID, err)
+		}
+	}
+
+	builder.removeEmptyStubFiles()
+	err = builder.MarkupImportCycles()
+	if err != nil {
+		return nil, err
+	}
+	return builder.Packages(), nil
+}
+
+// moduleOfDir returns the innermost module containing the directory
+func moduleOfDir(modules []WorkspaceModule, dir string) WorkspaceModule {
+	found := modules[0]
+	foundLen := -1
+	for _, module := range modules {
+		if dir != module.Dir && !strings.HasPrefix(dir, module.Dir+string(filepath.Separator)) {
+			continue
+		}
+		if len(module.Dir) > foundLen {
+			found = module
+			foundLen = len(module.Dir)
+		}
+	}
+	return found
+}
+
+// testVariants replaces each package with its variant compiled with its
+// _test.go files and drops the generated test mains, e.g. foo.test
+func testVariants(loaded []*packages.Package) []*packages.Package {
+	variants := make([]*packages.Package, 0, len(loaded))
+	byPath := make(map[string]int, len(loaded))
+	for _, pkg := range loaded {
+		if strings.HasSuffix(pkg.PkgPath, ".test") && pkg.Name == "main" {
+			continue
+		}
+		i, ok := byPath[pkg.PkgPath]
+		if !ok {
+			byPath[pkg.PkgPath] = len(variants)
+			variants = append(variants, pkg)
+			continue
+		}
+		if pkg.ID == pkg.PkgPath+" ["+pkg.PkgPath+".test]" {
+			variants[i] = pkg
+		}
+	}
+	return variants
+}
+
+// isExternalTest reports whether the package is an external test package,
+// e.g. foo_test, rather than a package whose import path ends in _test
+func isExternalTest(pkg *packages.Package) bool {
+	return strings.HasSuffix(pkg.Name, "_test") && strings.HasSuffix(pkg.ID, ".test]")
+}
+
+// recheck type checks the package again, importing the packages loaded on
+// their own. Objects of the packages in an import cycle are then declared
+// twice, which is fine as references are only resolved by position.
+func recheck(fset *token.FileSet, pkg *packages.Package, loadedByPath map[string]*packages.Package) {
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Implicits:  make(map[ast.Node]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+		Scopes:     make(map[ast.Node]*types.Scope),
+		Instances:  make(map[*ast.Ident]types.Instance),
+	}
+	conf := &types.Config{
+		Importer: importerFunc(func(path string) (*types.Package, error) {
+			imported, ok := loadedByPath[path]
+			if !ok || imported.Types == nil {
+				return nil, fmt.Errorf("package not loaded: %s", path)
+			}
+			return imported.Types, nil
+		}),
+		// the errors were reported by go/packages already
+		Error: func(error) {},
+	}
+	checked, _ := conf.Check(pkg.PkgPath, fset, pkg.Syntax, info)
+	pkg.Types = checked
+	pkg.TypesInfo = info
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (fn importerFunc) Import(path string) (*types.Package, error) {
+	return fn(path)
+}
+
+// collectReferences finds the objects of other packages used by the file.
+// Objects declared in the module are named after the top level declaration
+// enclosing them once every file has been indexed, see declIndex.
+func collectReferences(pkg *packages.Package, filename string, src *ast.File) []*Reference {
+	imports := make(map[string]string, len(src.Imports))
+	importsByPkgName := make(map[*types.PkgName]string, len(src.Imports))
+	for _, spec := range src.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		name := ImportPathToName(path)
+		if imported, ok := pkg.Imports[path]; ok && imported.Name != "" {
+			name = imported.Name
+		}
+		uid := importUID(spec, name)
+		if _, ok := imports[path]; !ok {
+			imports[path] = uid
+		}
+		if pkgName := pkg.TypesInfo.PkgNameOf(spec); pkgName != nil {
+			importsByPkgName[pkgName] = uid
+		}
+	}
+
+	type refKey struct {
+		importUID     string
+		pos           token.Pos
+		enclosingDecl string
+	}
+	spans := newDeclSpans(src)
+	var refs []*Reference
+	seen := make(map[refKey]bool)
+	addRef := func(importUID string, obj types.Object, use token.Pos) {
+		var enclosingDecl string
+		if decl, ok := spans.enclosing(use); ok {
+			enclosingDecl = decl.UID()
+		}
+		path := obj.Pkg().Path()
+		if importUID == "" {
+			importUID = imports[path]
+		}
+		if importUID == "" {
+			importUID = path
+		}
+		key := refKey{importUID: importUID, pos: obj.Pos(), enclosingDecl: enclosingDecl}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		refs = append(refs, &Reference{
+			FileUID:       filename,
+			ImportUID:     importUID,
+			ImportPath:    path,
+			ImportName:    obj.Pkg().Name(),
+			Decl:          objectDecl(obj),
+			EnclosingDecl: enclosingDecl,
+			pos:           obj.Pos(),
+		})
+	}
+
+	ast.Inspect(src, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.SelectorExpr:
+			x, ok := node.X.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			pkgName, ok := pkg.TypesInfo.Uses[x].(*types.PkgName)
+			if !ok {
+				return true
+			}
+			obj := pkg.TypesInfo.Uses[node.Sel]
+			if obj != nil && obj.Pkg() != nil {
+				addRef(importsByPkgName[pkgName], obj, node.Pos())
+			}
+			return false
+
+		case *ast.Ident:
+			obj := pkg.TypesInfo.Uses[node]
+			if obj == nil || obj.Pkg() == nil || obj.Pkg().Path() == pkg.PkgPath {
+				return true
+			}
+			switch obj.(type) {
+			case *types.PkgName, *types.Label, *types.Nil:
+				return true
+			}
+			addRef("", obj, node.Pos())
+		}
+		return true
+	})
+	return refs
+}
+
+// importUID matches the UID of the import added by the builder, see
+// internal.Import
+func importUID(spec *ast.ImportSpec, name string) string {
+	if spec.Name == nil {
+		return name
+	}
+	if spec.Name.Name == internal.BlankIdentifier || spec.Name.Name == internal.DotIdentifier {
+		return spec.Name.Name + name
+	}
+	return spec.Name.Name
+}
+
+// objectDecl names the declaration of an object outside of the module,
+// methods are qualified by their receiver's type name. The name is left
+// empty for objects which aren't declarations, e.g. fields.
+func objectDecl(obj types.Object) internal.Decl {
+	if fn, ok := obj.(*types.Func); ok {
+		if recv := fn.Signature().Recv(); recv != nil {
+			return internal.Decl{
+				Name:     obj.Name(),
+				FuncName: receiverTypeName(recv.Type()),
+				IsMethod: true,
+			}
+		}
+	}
+	if obj.Parent() == obj.Pkg().Scope() {
+		return internal.Decl{Name: obj.Name()}
+	}
+	return internal.Decl{}
+}
+
+func receiverTypeName(typ types.Type) string {
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	if named, ok := typ.(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// declIndex finds the top level declaration enclosing a position within the
+// files of the module
+type declIndex struct {
+	fset  *token.FileSet
+	files map[string]declSpans
+}
+
+func (index *declIndex) addFile(filename string, src *ast.File) {
+	if index.files == nil {
+		index.files = make(map[string]declSpans)
+	}
+	index.files[filename] = newDeclSpans(src)
+}
+
+// lookup returns the file and the innermost top level declaration enclosing
+// the position
+func (index *declIndex) lookup(pos token.Pos) (string, internal.Decl, bool) {
+	if !pos.IsValid() {
+		return "", internal.Decl{}, false
+	}
+	file := index.fset.File(pos)
+	if file == nil {
+		return "", internal.Decl{}, false
+	}
+	spans, ok := index.files[file.Name()]
+	if !ok {
+		return "", internal.Decl{}, false
+	}
+	decl, ok := spans.enclosing(pos)
+	if !ok {
+		return "", internal.Decl{}, false
+	}
+	return file.Name(), decl, true
+}
@@ -0,0 +1,196 @@
+package primitives_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samlitowitz/godepvis/internal/modfile"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/test"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildForModuleWithTypes_WithCorrectImportCycles(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	testCases := map[string]struct {
+		dir                     string
+		expectedCyclesByPackage []string
+	}{
+		"direct-circular-dependency": {
+			dir:                     "direct-circular-dependency",
+			expectedCyclesByPackage: []string{"a,b"},
+		},
+		"direct-circular-dependency-blank-identifiers": {
+			dir:                     "direct-circular-dependency-blank-identifiers",
+			expectedCyclesByPackage: []string{"a,b"},
+		},
+		"multiple-independent-direct-circular-dependencies": {
+			dir:                     "multiple-independent-direct-circular-dependencies",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+		"multiple-interlinked-direct-circular-dependencies": {
+			dir:                     "multiple-interlinked-direct-circular-dependencies",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+		"no-circular-dependencies": {
+			dir: "no-circular-dependencies",
+		},
+		"no-circular-dependencies-with-blank-identifier": {
+			dir: "no-circular-dependencies-with-blank-identifier",
+		},
+		"transitive-circular-dependency": {
+			dir:                     "transitive-circular-dependency",
+			expectedCyclesByPackage: []string{"a,b,c"},
+		},
+		"with-type-checked-references": {
+			dir: "with-type-checked-references",
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+			actualPkgs, err := primitives.BuildForModuleWithTypes(modulePath, moduleDir)
+			if err != nil {
+				t.Fatal(desc, ": BuildForModuleWithTypes: ", err)
+			}
+
+			pkgsBySCC := make(map[int][]string)
+			for _, pkg := range actualPkgs {
+				if pkg.SCC == 0 {
+					t.Error(desc, ": package not assigned a strongly connected component: ", pkg.Name)
+				}
+				if !pkg.InImportCycle {
+					continue
+				}
+				pkgsBySCC[pkg.SCC] = append(pkgsBySCC[pkg.SCC], pkg.Name)
+			}
+			var actualCyclesByPackage []string
+			for _, names := range pkgsBySCC {
+				slices.Sort(names)
+				actualCyclesByPackage = append(actualCyclesByPackage, strings.Join(names, ","))
+			}
+
+			if diff := cmp.Diff(testCase.expectedCyclesByPackage, actualCyclesByPackage, opts); diff != "" {
+				t.Error(desc, test.Mismatch(": expected cycles by package: ", diff))
+			}
+		}()
+	}
+}
+
+func TestBuildForModuleWithTypes_WithCorrectReferences(t *testing.T) {
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	testCases := map[string]struct {
+		dir string
+		// expectedReferences are the referenced declarations by file and
+		// import, implicit imports are prefixed with an asterisk
+		expectedReferences map[string][]string
+	}{
+		"direct-circular-dependency-with-fn-receivers": {
+			dir: "direct-circular-dependency-with-fn-receivers",
+			expectedReferences: map[string][]string{
+				"a.go: b":    {"B", "(B).Fn"},
+				"a.go: log":  {"Println"},
+				"b.go: a":    {"A", "(A).Fn"},
+				"b.go: log":  {"Println"},
+				"main.go: a": {"A", "(A).Fn"},
+			},
+		},
+		"with-type-checked-references": {
+			dir: "with-type-checked-references",
+			expectedReferences: map[string][]string{
+				"a.go: b":        {"B"},
+				"b.go: log":      {"Println"},
+				"c.go: a":        {"New"},
+				"c.go: *b":       {"(B).Method"},
+				"shadowed.go: b": {"B"},
+				"main.go: c":     {"Fn"},
+			},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			modulePath, moduleDir := copyModule(t, desc, testCase.dir)
+
+			actualPkgs, err := primitives.BuildForModuleWithTypes(modulePath, moduleDir)
+			if err != nil {
+				t.Fatal(desc, ": BuildForModuleWithTypes: ", err)
+			}
+
+			actualReferences := make(map[string][]string)
+			for _, pkg := range actualPkgs {
+				for _, file := range pkg.Files {
+					for _, imp := range file.Imports {
+						key := file.FileName + ": " + imp.Name
+						if imp.IsImplicit {
+							key = file.FileName + ": *" + imp.Name
+						}
+						for declUID := range imp.ReferencedTypes {
+							actualReferences[key] = append(actualReferences[key], declUID)
+						}
+					}
+				}
+			}
+
+			if diff := cmp.Diff(testCase.expectedReferences, actualReferences, opts); diff != "" {
+				t.Error(desc, test.Mismatch(": expected references: ", diff))
+			}
+		}()
+	}
+}
+
+// copyModule copies the test module to a temporary directory and returns its
+// module path and directory
+func copyModule(t *testing.T, desc, dir string) (string, string) {
+	t.Helper()
+
+	// REFURL: https://github.com/golang/go/blob/988b718f4130ab5b3ce5a5774e1a58e83c92a163/src/path/filepath/path_test.go#L600
+	// -- START -- //
+	if runtime.GOOS == "ios" {
+		restore := test.Chtmpdir(t)
+		t.Cleanup(restore)
+	}
+
+	tmpDir := t.TempDir()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal("finding working dir:", err)
+	}
+	// -- END -- //
+
+	err = os.CopyFS(tmpDir, os.DirFS(filepath.Join(origDir, "testdata", "build-for-module", dir)))
+	if err != nil {
+		t.Fatal("copy test data:", err)
+	}
+
+	goModFile, err := modfile.FindGoModFile(tmpDir)
+	if err != nil {
+		t.Fatal(desc, ": failed to find go.mod: ", err)
+	}
+	modulePath, err := modfile.GetModulePath(goModFile)
+	if err != nil {
+		t.Fatal(desc, ": failed to get module path: ", err)
+	}
+	return modulePath, tmpDir
+}
@@ -0,0 +1,149 @@
+package primitives_test
+
+import (
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/modfile"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/test"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestBuildForWorkspace_WithCorrectImportCycles(t *testing.T) {
+	// the go command rejects -mod=mod in workspace mode
+	t.Setenv("GOFLAGS", "")
+
+	opts := cmp.Options{
+		cmpopts.SortSlices(
+			func(x, y string) bool {
+				return strings.Compare(x, y) <= 0
+			},
+		),
+	}
+
+	builders := map[string]func([]primitives.WorkspaceModule, ...primitives.Option) ([]*internal.Package, error){
+		"BuildForWorkspace":          primitives.BuildForWorkspace,
+		"BuildForWorkspaceWithTypes": primitives.BuildForWorkspaceWithTypes,
+	}
+
+	testCases := map[string]struct {
+		dir                      string
+		expectedPackagesByModule []string
+		expectedCyclesByPackage  []string
+		expectedCyclesByModule   []string
+		expectedStubImportPaths  []string
+	}{
+		"with-workspace": {
+			dir: "with-workspace",
+			expectedPackagesByModule: []string{
+				"github.com/fake/app: main",
+				"github.com/fake/lib: w,x",
+				"github.com/fake/util: y,z",
+			},
+			expectedCyclesByModule: []string{"github.com/fake/lib,github.com/fake/util"},
+		},
+	}
+
+	for builderDesc, build := range builders {
+		for desc, testCase := range testCases {
+			func() {
+				desc := builderDesc + ": " + desc
+				modules := copyWorkspace(t, desc, testCase.dir)
+
+				actualPkgs, err := build(modules)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+
+				pkgsByModule := make(map[string][]string)
+				pkgsBySCC := make(map[int][]string)
+				modulesBySCC := make(map[int][]string)
+				seenModules := make(map[*internal.Module]bool)
+				var actualStubImportPaths []string
+				for _, pkg := range actualPkgs {
+					if pkg.IsStub {
+						actualStubImportPaths = append(actualStubImportPaths, pkg.ImportPath())
+						continue
+					}
+					pkgsByModule[pkg.Module.Path] = append(pkgsByModule[pkg.Module.Path], pkg.Name)
+					if pkg.InImportCycle {
+						pkgsBySCC[pkg.SCC] = append(pkgsBySCC[pkg.SCC], pkg.Name)
+					}
+					if seenModules[pkg.Module] {
+						continue
+					}
+					seenModules[pkg.Module] = true
+					if pkg.Module.InImportCycle {
+						modulesBySCC[pkg.Module.SCC] = append(modulesBySCC[pkg.Module.SCC], pkg.Module.Path)
+					}
+				}
+
+				var actualPackagesByModule []string
+				for modulePath, names := range pkgsByModule {
+					slices.Sort(names)
+					actualPackagesByModule = append(actualPackagesByModule, modulePath+": "+strings.Join(names, ","))
+				}
+				var actualCyclesByPackage []string
+				for _, names := range pkgsBySCC {
+					slices.Sort(names)
+					actualCyclesByPackage = append(actualCyclesByPackage, strings.Join(names, ","))
+				}
+				var actualCyclesByModule []string
+				for _, paths := range modulesBySCC {
+					slices.Sort(paths)
+					actualCyclesByModule = append(actualCyclesByModule, strings.Join(paths, ","))
+				}
+
+				if diff := cmp.Diff(testCase.expectedPackagesByModule, actualPackagesByModule, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected packages by module: ", diff))
+				}
+				if diff := cmp.Diff(testCase.expectedCyclesByPackage, actualCyclesByPackage, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected cycles by package: ", diff))
+				}
+				if diff := cmp.Diff(testCase.expectedCyclesByModule, actualCyclesByModule, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected cycles by module: ", diff))
+				}
+				if diff := cmp.Diff(testCase.expectedStubImportPaths, actualStubImportPaths, opts); diff != "" {
+					t.Error(desc, test.Mismatch(": expected stub import paths: ", diff))
+				}
+			}()
+		}
+	}
+}
+
+// copyWorkspace copies the test workspace to a temporary directory and
+// returns its modules
+func copyWorkspace(t *testing.T, desc, dir string) []primitives.WorkspaceModule {
+	t.Helper()
+
+	tmpDir := t.TempDir()
+
+	origDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal("finding working dir:", err)
+	}
+
+	err = os.CopyFS(tmpDir, os.DirFS(filepath.Join(origDir, "testdata", "build-for-module", dir)))
+	if err != nil {
+		t.Fatal("copy test data:", err)
+	}
+
+	moduleDirs, err := modfile.GetWorkspaceModuleDirs(filepath.Join(tmpDir, "go.work"))
+	if err != nil {
+		t.Fatal(desc, ": failed to get workspace modules: ", err)
+	}
+	modules := make([]primitives.WorkspaceModule, 0, len(moduleDirs))
+	for _, moduleDir := range moduleDirs {
+		modulePath, err := modfile.GetModulePath(filepath.Join(moduleDir, "go.mod"))
+		if err != nil {
+			t.Fatal(desc, ": failed to get module path: ", err)
+		}
+		modules = append(modules, primitives.WorkspaceModule{Path: modulePath, Dir: moduleDir})
+	}
+	return modules
+}
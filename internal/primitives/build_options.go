@@ -0,0 +1,163 @@
+package primitives
+
+import (
+	"go/build"
+	"os"
+	"runtime"
+	"strings"
+)
+
+type options struct {
+	goos   string
+	goarch string
+	tags   []string
+	tests  bool
+	jobs   int
+
+	cacheDir string
+	cache    *Cache
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type goosOption string
+
+func (opt goosOption) apply(opts *options) {
+	opts.goos = string(opt)
+}
+
+// WithGOOS selects the files built for the operating system, the host's by
+// default
+func WithGOOS(goos string) Option {
+	return goosOption(goos)
+}
+
+type goarchOption string
+
+func (opt goarchOption) apply(opts *options) {
+	opts.goarch = string(opt)
+}
+
+// WithGOARCH selects the files built for the architecture, the host's by
+// default
+func WithGOARCH(goarch string) Option {
+	return goarchOption(goarch)
+}
+
+type tagsOption []string
+
+func (opt tagsOption) apply(opts *options) {
+	opts.tags = append(opts.tags, opt...)
+}
+
+// WithBuildTags selects the files built with the additional build tags
+func WithBuildTags(tags ...string) Option {
+	return tagsOption(tags)
+}
+
+type testsOption bool
+
+func (opt testsOption) apply(opts *options) {
+	opts.tests = bool(opt)
+}
+
+// WithTests includes _test.go files, external test packages are distinct
+// from the package under test
+func WithTests() Option {
+	return testsOption(true)
+}
+
+type jobsOption int
+
+func (opt jobsOption) apply(opts *options) {
+	opts.jobs = int(opt)
+}
+
+// WithJobs parses and walks up to jobs files at once, GOMAXPROCS by default
+func WithJobs(jobs int) Option {
+	return jobsOption(jobs)
+}
+
+type cacheDirOption string
+
+func (opt cacheDirOption) apply(opts *options) {
+	opts.cacheDir = string(opt)
+}
+
+// WithCacheDir keeps what's found in each file under dir, files unchanged
+// since are neither parsed nor walked again. BuildForModuleWithTypes doesn't
+// use the cache.
+func WithCacheDir(dir string) Option {
+	return cacheDirOption(dir)
+}
+
+type cacheOption struct {
+	cache *Cache
+}
+
+func (opt cacheOption) apply(opts *options) {
+	opts.cache = opt.cache
+}
+
+// WithCache keeps what's found in each file in the cache, files which
+// weren't forgotten since are neither read nor parsed again. It's used
+// along with WithCacheDir, the files it has to parse are looked up there.
+// BuildForModuleWithTypes doesn't use the cache.
+func WithCache(cache *Cache) Option {
+	return cacheOption{cache: cache}
+}
+
+func buildOptions(opts []Option) *options {
+	options := &options{}
+	for _, opt := range opts {
+		opt.apply(options)
+	}
+	return options
+}
+
+// jobCount is the number of files parsed and walked at once
+func (opts *options) jobCount() int {
+	if opts.jobs < 1 {
+		return runtime.GOMAXPROCS(0)
+	}
+	return opts.jobs
+}
+
+// buildContext matches files the way the go command does for the target
+func (opts *options) buildContext() *build.Context {
+	ctx := build.Default
+	if opts.goos != "" {
+		ctx.GOOS = opts.goos
+	}
+	if opts.goarch != "" {
+		ctx.GOARCH = opts.goarch
+	}
+	// the go command disables cgo when cross compiling
+	if _, ok := os.LookupEnv("CGO_ENABLED"); !ok && (ctx.GOOS != runtime.GOOS || ctx.GOARCH != runtime.GOARCH) {
+		ctx.CgoEnabled = false
+	}
+	ctx.BuildTags = append(ctx.BuildTags, opts.tags...)
+	return &ctx
+}
+
+// env and buildFlags configure the go command run by go/packages for the
+// target
+func (opts *options) env() []string {
+	env := os.Environ()
+	if opts.goos != "" {
+		env = append(env, "GOOS="+opts.goos)
+	}
+	if opts.goarch != "" {
+		env = append(env, "GOARCH="+opts.goarch)
+	}
+	return env
+}
+
+func (opts *options) buildFlags() []string {
+	if len(opts.tags) == 0 {
+		return nil
+	}
+	return []string{"-tags=" + strings.Join(opts.tags, ",")}
+}
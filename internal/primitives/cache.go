@@ -0,0 +1,443 @@
+package primitives
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"go/ast"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cacheVersion is part of every cache key, bump it whenever the nodes found
+// by the visitor or their encoding change
+const cacheVersion = "1"
+
+// entries unused for cacheTrimLimit are removed at most once every
+// cacheTrimInterval, the modification time of an entry marks its last use and
+// is only updated once it's cacheMtimeInterval old, like the go build cache
+const (
+	cacheTrimLimit     = 5 * 24 * time.Hour
+	cacheTrimInterval  = 24 * time.Hour
+	cacheMtimeInterval = time.Hour
+
+	// cacheTrimFile holds the Unix time of the last trim
+	cacheTrimFile = "trim.txt"
+)
+
+// fileCache keeps the nodes found by the visitor in each file under dir, keyed
+// by the module path and the file's content, so unchanged files are neither
+// parsed nor walked again, along with the names listed by the go command, see
+// resolveExternal. Failing to read or write the cache only costs the
+// parse.
+type fileCache struct {
+	dir string
+}
+
+func newFileCache(dir string) *fileCache {
+	if dir == "" {
+		return nil
+	}
+	return &fileCache{dir: dir}
+}
+
+// Cache keeps what's found in each file in memory across builds, files are
+// neither read nor parsed again until they are forgotten, see Forget. It's
+// meant for processes rebuilding the module on changes, e.g. watch. A Cache
+// is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+func NewCache() *Cache {
+	return &Cache{entries: make(map[string]*cacheEntry)}
+}
+
+// Forget drops the entries of the files, every entry is dropped when any
+// of them isn't a .go file, e.g. a go.mod file changing the module path
+func (cache *Cache) Forget(paths ...string) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	for _, path := range paths {
+		if filepath.Ext(path) != ".go" {
+			clear(cache.entries)
+			return
+		}
+		delete(cache.entries, filepath.Clean(path))
+	}
+}
+
+func (cache *Cache) load(filename string) (*cacheEntry, bool) {
+	if cache == nil {
+		return nil, false
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	entry, ok := cache.entries[filename]
+	return entry, ok
+}
+
+func (cache *Cache) store(filename string, entry *cacheEntry) {
+	if cache == nil {
+		return
+	}
+	cache.mu.Lock()
+	defer cache.mu.Unlock()
+	cache.entries[filename] = entry
+}
+
+// key is the hash of the module path and the file's content
+func (cache *fileCache) key(modulePath string, src []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(cacheVersion))
+	hash.Write([]byte{0})
+	hash.Write([]byte(modulePath))
+	hash.Write([]byte{0})
+	hash.Write(src)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// path shards the entries by the first byte of their key like the go build
+// cache
+func (cache *fileCache) path(key string) string {
+	return filepath.Join(cache.dir, key[:2], key)
+}
+
+// load decodes the entry into v, false if it isn't cached
+func (cache *fileCache) load(key string, v any) bool {
+	if cache == nil {
+		return false
+	}
+	src, err := os.ReadFile(cache.path(key))
+	if err != nil {
+		return false
+	}
+	if err := gob.NewDecoder(bytes.NewReader(src)).Decode(v); err != nil {
+		return false
+	}
+	cache.used(cache.path(key))
+	return true
+}
+
+// used marks the entry as used so it isn't trimmed
+func (cache *fileCache) used(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	now := time.Now()
+	if now.Sub(info.ModTime()) < cacheMtimeInterval {
+		return
+	}
+	_ = os.Chtimes(path, now, now)
+}
+
+// store writes the entry to a temporary file renamed into place, concurrent
+// runs never read a partial entry
+func (cache *fileCache) store(key string, v any) {
+	if cache == nil {
+		return
+	}
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(v); err != nil {
+		return
+	}
+	path := cache.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), key+".*")
+	if err != nil {
+		return
+	}
+	_, err = tmp.Write(buf.Bytes())
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), path)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+}
+
+// trim removes the entries unused for cacheTrimLimit unless the cache was
+// trimmed within cacheTrimInterval
+func (cache *fileCache) trim(now time.Time) {
+	if cache == nil {
+		return
+	}
+	trimFile := filepath.Join(cache.dir, cacheTrimFile)
+	if src, err := os.ReadFile(trimFile); err == nil {
+		lastTrim, err := strconv.ParseInt(strings.TrimSpace(string(src)), 10, 64)
+		if err == nil && now.Sub(time.Unix(lastTrim, 0)) < cacheTrimInterval {
+			return
+		}
+	}
+
+	cutoff := now.Add(-cacheTrimLimit)
+	shards, err := os.ReadDir(cache.dir)
+	if err != nil {
+		return
+	}
+	for _, shard := range shards {
+		// entries are kept in a directory per first byte of their key, see path
+		if !shard.IsDir() || len(shard.Name()) != 2 {
+			continue
+		}
+		shardDir := filepath.Join(cache.dir, shard.Name())
+		entries, err := os.ReadDir(shardDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			info, err := entry.Info()
+			if err != nil || entry.IsDir() || !info.ModTime().Before(cutoff) {
+				continue
+			}
+			_ = os.Remove(filepath.Join(shardDir, entry.Name()))
+		}
+	}
+	_ = os.WriteFile(trimFile, []byte(strconv.FormatInt(now.Unix(), 10)+"\n"), 0o644)
+}
+
+// cacheEntry is what's needed of a file to build without parsing it
+type cacheEntry struct {
+	PackageName string
+	ImportPaths []string
+	// ImportNames are the names of the imported packages the nodes were
+	// found with, the nodes are stale when any of them changes
+	ImportNames map[string]string
+	Nodes       []cachedNode
+}
+
+func newCacheEntry(file *parsedFile, importNames ImportNames) (*cacheEntry, bool) {
+	nodes, ok := encodeNodes(file.nodes)
+	if !ok {
+		return nil, false
+	}
+	entry := &cacheEntry{
+		PackageName: file.packageName,
+		ImportPaths: file.importPaths,
+		ImportNames: make(map[string]string, len(file.importPaths)),
+		Nodes:       nodes,
+	}
+	for _, importPath := range file.importPaths {
+		entry.ImportNames[importPath] = importNames.Name(importPath)
+	}
+	return entry, true
+}
+
+// matches reports whether the nodes were found with the import names
+func (entry *cacheEntry) matches(importNames ImportNames) bool {
+	for importPath, name := range entry.ImportNames {
+		if importNames.Name(importPath) != name {
+			return false
+		}
+	}
+	return true
+}
+
+type cachedNodeKind int
+
+const (
+	cachedImportSpec cachedNodeKind = iota
+	cachedFuncDecl
+	cachedGenDecl
+	cachedSelectorExpr
+	cachedIdent
+)
+
+// cachedNode keeps the parts of a node read by the builder
+type cachedNode struct {
+	Kind cachedNodeKind
+	Name string
+
+	// ImportSpec
+	Path      string
+	Alias     string
+	IsAliased bool
+
+	// FuncDecl
+	ReceiverName  string
+	QualifiedName string
+	IsReceiver    bool
+
+	// GenDecl
+	Tok           token.Token
+	FuncScopeName string
+	Specs         []cachedSpec
+
+	// SelectorExpr and Ident
+	ImportName    string
+	EnclosingDecl string
+	IsMember      bool
+	ReceiverType  string
+}
+
+// cachedSpec is a type spec, with the field names of struct types, or a
+// value spec
+type cachedSpec struct {
+	Names      []string
+	IsType     bool
+	IsStruct   bool
+	FieldNames []string
+}
+
+// encodeNodes keeps the parts of the nodes read by the builder, false if any
+// node can't be kept
+func encodeNodes(nodes []ast.Node) ([]cachedNode, bool) {
+	cached := make([]cachedNode, 0, len(nodes))
+	for _, node := range nodes {
+		switch node := node.(type) {
+		case *ImportSpec:
+			cached = append(cached, cachedNode{
+				Kind:      cachedImportSpec,
+				Name:      node.Name.String(),
+				Path:      node.Path.Value,
+				Alias:     node.Alias,
+				IsAliased: node.IsAliased,
+			})
+		case *FuncDecl:
+			cached = append(cached, cachedNode{
+				Kind:          cachedFuncDecl,
+				Name:          node.Name.String(),
+				ReceiverName:  node.ReceiverName,
+				QualifiedName: node.QualifiedName,
+				IsReceiver:    node.IsReceiver(),
+			})
+		case *GenDecl:
+			specs := make([]cachedSpec, 0, len(node.Specs))
+			for _, spec := range node.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					cachedSpec := cachedSpec{
+						Names:  []string{spec.Name.String()},
+						IsType: true,
+					}
+					if structType, ok := spec.Type.(*ast.StructType); ok {
+						cachedSpec.IsStruct = true
+						cachedSpec.FieldNames = fieldNames(structType)
+					}
+					specs = append(specs, cachedSpec)
+				case *ast.ValueSpec:
+					names := make([]string, 0, len(spec.Names))
+					for _, name := range spec.Names {
+						names = append(names, name.String())
+					}
+					specs = append(specs, cachedSpec{Names: names})
+				default:
+					return nil, false
+				}
+			}
+			cached = append(cached, cachedNode{
+				Kind:          cachedGenDecl,
+				Tok:           node.Tok,
+				FuncScopeName: node.FuncScopeName,
+				Specs:         specs,
+			})
+		case *SelectorExpr:
+			cached = append(cached, cachedNode{
+				Kind:          cachedSelectorExpr,
+				Name:          node.Sel.String(),
+				ImportName:    node.ImportName,
+				EnclosingDecl: node.EnclosingDecl,
+				IsMember:      node.IsMember,
+				ReceiverType:  node.ReceiverType,
+			})
+		case *Ident:
+			cached = append(cached, cachedNode{
+				Kind:          cachedIdent,
+				Name:          node.String(),
+				EnclosingDecl: node.EnclosingDecl,
+			})
+		default:
+			return nil, false
+		}
+	}
+	return cached, true
+}
+
+// decodeNodes rebuilds the nodes from the parts read by the builder
+func decodeNodes(cached []cachedNode) []ast.Node {
+	nodes := make([]ast.Node, 0, len(cached))
+	for _, node := range cached {
+		switch node.Kind {
+		case cachedImportSpec:
+			nodes = append(nodes, &ImportSpec{
+				ImportSpec: &ast.ImportSpec{
+					Name: ast.NewIdent(node.Name),
+					Path: &ast.BasicLit{Kind: token.STRING, Value: node.Path},
+				},
+				IsAliased: node.IsAliased,
+				Alias:     node.Alias,
+			})
+		case cachedFuncDecl:
+			funcDecl := &ast.FuncDecl{Name: ast.NewIdent(node.Name)}
+			if node.IsReceiver {
+				funcDecl.Recv = &ast.FieldList{}
+			}
+			nodes = append(nodes, &FuncDecl{
+				FuncDecl:      funcDecl,
+				ReceiverName:  node.ReceiverName,
+				QualifiedName: node.QualifiedName,
+			})
+		case cachedGenDecl:
+			genDecl := &ast.GenDecl{Tok: node.Tok}
+			for _, spec := range node.Specs {
+				genDecl.Specs = append(genDecl.Specs, spec.decode())
+			}
+			nodes = append(nodes, &GenDecl{
+				GenDecl:       genDecl,
+				FuncScopeName: node.FuncScopeName,
+			})
+		case cachedSelectorExpr:
+			nodes = append(nodes, &SelectorExpr{
+				SelectorExpr: &ast.SelectorExpr{
+					X:   ast.NewIdent(node.ImportName),
+					Sel: ast.NewIdent(node.Name),
+				},
+				ImportName:    node.ImportName,
+				EnclosingDecl: node.EnclosingDecl,
+				IsMember:      node.IsMember,
+				ReceiverType:  node.ReceiverType,
+			})
+		case cachedIdent:
+			nodes = append(nodes, &Ident{
+				Ident:         ast.NewIdent(node.Name),
+				EnclosingDecl: node.EnclosingDecl,
+			})
+		}
+	}
+	return nodes
+}
+
+func (spec cachedSpec) decode() ast.Spec {
+	names := make([]*ast.Ident, 0, len(spec.Names))
+	for _, name := range spec.Names {
+		names = append(names, ast.NewIdent(name))
+	}
+	if !spec.IsType {
+		return &ast.ValueSpec{Names: names}
+	}
+	typeSpec := &ast.TypeSpec{Name: names[0], Type: &ast.Ident{}}
+	if spec.IsStruct {
+		fields := &ast.FieldList{}
+		for _, name := range spec.FieldNames {
+			fields.List = append(fields.List, &ast.Field{Names: []*ast.Ident{ast.NewIdent(name)}})
+		}
+		typeSpec.Type = &ast.StructType{Fields: fields}
+	}
+	return typeSpec
+}
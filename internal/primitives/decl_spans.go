@@ -0,0 +1,93 @@
+package primitives
+
+import (
+	"go/ast"
+	"go/token"
+
+	"github.com/samlitowitz/godepvis/internal"
+)
+
+// declSpan is the extent of a top level declaration
+type declSpan struct {
+	pos, end token.Pos
+	decl     internal.Decl
+}
+
+// declSpans are the extents of the top level declarations of a file, methods
+// are qualified by their receiver's type name
+type declSpans []declSpan
+
+func newDeclSpans(src *ast.File) declSpans {
+	var spans declSpans
+	add := func(node ast.Node, name, receiverName string) {
+		if name == internal.BlankIdentifier {
+			return
+		}
+		spans = append(spans, declSpan{
+			pos:  node.Pos(),
+			end:  node.End(),
+			decl: internal.Decl{Name: name, FuncName: receiverName, IsMethod: receiverName != ""},
+		})
+	}
+	for _, decl := range src.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if decl.Recv == nil || len(decl.Recv.List) == 0 {
+				add(decl, decl.Name.Name, "")
+				continue
+			}
+			add(decl, decl.Name.Name, receiverExprName(decl.Recv.List[0].Type))
+
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					add(spec, spec.Name.Name, "")
+				case *ast.ValueSpec:
+					for _, name := range spec.Names {
+						add(name, name.Name, "")
+					}
+					// e.g. the fields of an anonymous struct type
+					if len(spec.Names) > 0 {
+						add(spec, spec.Names[0].Name, "")
+					}
+				}
+			}
+		}
+	}
+	return spans
+}
+
+// enclosing returns the innermost top level declaration enclosing the
+// position
+func (spans declSpans) enclosing(pos token.Pos) (internal.Decl, bool) {
+	var found *declSpan
+	for i, span := range spans {
+		if pos < span.pos || pos >= span.end {
+			continue
+		}
+		if found == nil || span.end-span.pos < found.end-found.pos {
+			found = &spans[i]
+		}
+	}
+	if found == nil {
+		return internal.Decl{}, false
+	}
+	return found.decl, true
+}
+
+func receiverExprName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		return expr.Name
+	case *ast.StarExpr:
+		return receiverExprName(expr.X)
+	case *ast.ParenExpr:
+		return receiverExprName(expr.X)
+	case *ast.IndexExpr:
+		return receiverExprName(expr.X)
+	case *ast.IndexListExpr:
+		return receiverExprName(expr.X)
+	}
+	return ""
+}
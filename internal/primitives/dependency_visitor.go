@@ -6,12 +6,15 @@
 	"go/token"
 	"strconv"
 	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
 )
 
 type Package struct {
 	*ast.Package
 
 	DirName string
+	IsTest  bool
 }
 
 type File struct {
@@ -19,13 +22,15 @@ type File struct {
 
 	AbsPath string
 	DirName string
+	IsTest  bool
 }
 
 type ImportSpec struct {
 	*ast.ImportSpec
 
-	IsAliased bool
-	Alias     string
+	IsAliased  bool
+	Alias      string
+	IsImplicit bool
 }
 
 type FuncDecl struct {
@@ -49,6 +54,28 @@ type SelectorExpr struct {
 	*ast.SelectorExpr
 
 	ImportName string
+	// EnclosingDecl is the UID of the top level declaration the
+	// expression is part of, empty outside of declarations
+	EnclosingDecl string
+	// IsMember is set when a method or field is selected from a value of
+	// the imported package, e.g. b.NewThing().Method or b.Var.Field, rather
+	// than a declaration of the package itself
+	IsMember bool
+	// ReceiverType is the name of the type of the imported package the
+	// member is selected from when it's known from the syntax, e.g. T for
+	// b.T{}.Method or for x.Method where x is declared as a b.T
+	ReceiverType string
+}
+
+// Ident is an exported identifier used without a selector in a file with dot
+// imports, a reference to a declaration of one of the dot imported packages
+// if any of them declares it
+type Ident struct {
+	*ast.Ident
+
+	// EnclosingDecl is the UID of the top level declaration the
+	// identifier is part of, empty outside of declarations
+	EnclosingDecl string
 }
 
 // DependencyVisitor is only for use against individual files
@@ -56,6 +83,19 @@ type DependencyVisitor struct {
 	inOrderNodes []ast.Node
 
 	fileImports map[string]struct{}
+	importNames ImportNames
+	declSpans   declSpans
+
+	// hasDotImports is set once a dot import of the file has been visited,
+	// declaredIdents are identifiers which aren't references, e.g. the names
+	// of declarations, fields and selectors
+	hasDotImports  bool
+	declaredIdents map[*ast.Ident]struct{}
+
+	// varTypes are the types of the file's variables and parameters which
+	// are declared as a type of an import, e.g. b.T or *b.T. Names aren't
+	// scoped, the last declaration of a name wins.
+	varTypes map[string]memberType
 
 	curFuncScope             *funcScope
 	funcScopeStack           funcScopeStack
@@ -74,9 +114,21 @@ func NewDependencyVisitor() *DependencyVisitor {
 	return v
 }
 
+// NewDependencyVisitorWithImportNames resolves the package names of imports
+// with names rather than assuming them from the import paths
+func NewDependencyVisitorWithImportNames(names ImportNames) *DependencyVisitor {
+	v := NewDependencyVisitor()
+	v.importNames = names
+	return v
+}
+
 func (v *DependencyVisitor) Reset() {
 	v.inOrderNodes = nil
 	v.fileImports = nil
+	v.declSpans = nil
+	v.hasDotImports = false
+	v.declaredIdents = nil
+	v.varTypes = nil
 	v.funcScopeStack = nil
 
 	v.tmp = nil
@@ -101,14 +153,88 @@ func (v *DependencyVisitor) Visit(node ast.Node) ast.Visitor {
 
 	case *ast.File:
 		v.fileImports = make(map[string]struct{})
+		v.declSpans = newDeclSpans(node)
+		v.hasDotImports = false
+		v.declaredIdents = map[*ast.Ident]struct{}{node.Name: {}}
+		// package level variables may be used before they are declared
+		v.varTypes = make(map[string]memberType)
+		for _, decl := range node.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.VAR {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				v.addVarTypes(spec.(*ast.ValueSpec))
+			}
+		}
 
 	case *ast.ImportSpec:
 		v.addImportSpec(node)
 
 	case *ast.FuncDecl:
+		v.declaredIdents[node.Name] = struct{}{}
 		v.addFuncDecl(node)
 		v.enterFuncDecl(node)
 
+	case *ast.TypeSpec:
+		v.declaredIdents[node.Name] = struct{}{}
+
+	case *ast.ValueSpec:
+		v.addDeclaredIdents(node.Names...)
+		v.addVarTypes(node)
+
+	case *ast.AssignStmt:
+		if node.Tok != token.DEFINE || len(node.Lhs) != len(node.Rhs) {
+			break
+		}
+		for i, lhs := range node.Lhs {
+			name, ok := lhs.(*ast.Ident)
+			if !ok {
+				continue
+			}
+			if typ, ok := valueType(node.Rhs[i]); ok {
+				v.varTypes[name.String()] = typ
+			}
+		}
+
+	case *ast.FuncType:
+		for _, fields := range []*ast.FieldList{node.Params, node.Results} {
+			if fields == nil {
+				continue
+			}
+			for _, field := range fields.List {
+				typ, ok := importedType(field.Type)
+				if !ok {
+					continue
+				}
+				for _, name := range field.Names {
+					v.varTypes[name.String()] = typ
+				}
+			}
+		}
+
+	case *ast.Field:
+		v.addDeclaredIdents(node.Names...)
+
+	case *ast.CompositeLit:
+		// keys of struct literals are field names, keys of map literals may
+		// be references
+		if _, ok := node.Type.(*ast.MapType); ok {
+			break
+		}
+		for _, elt := range node.Elts {
+			kv, ok := elt.(*ast.KeyValueExpr)
+			if !ok {
+				continue
+			}
+			if key, ok := kv.Key.(*ast.Ident); ok {
+				v.declaredIdents[key] = struct{}{}
+			}
+		}
+
+	case *ast.Ident:
+		v.addIdent(node)
+
 	case *ast.FuncLit:
 		v.enterFuncLit(node)
 
@@ -132,11 +258,28 @@ func (v *DependencyVisitor) Visit(node ast.Node) ast.Visitor {
 		if node.X == nil {
 			return v
 		}
+		v.declaredIdents[node.Sel] = struct{}{}
 
 		impName := ""
+		isMember := false
+		receiverType := ""
 		switch x := node.X.(type) {
 		case *ast.Ident:
 			impName = x.String()
+			if _, ok := v.fileImports[impName]; ok {
+				break
+			}
+			if typ, ok := v.varTypes[impName]; ok {
+				impName = typ.importName
+				isMember = true
+				receiverType = typ.typeName
+			}
+		default:
+			impName = memberImportName(x)
+			isMember = true
+			if typ, ok := valueType(x); ok {
+				receiverType = typ.typeName
+			}
 		}
 
 		// if the "import name" is actually a variable and not a package, skip it
@@ -144,21 +287,23 @@ func (v *DependencyVisitor) Visit(node ast.Node) ast.Visitor {
 			return v
 		}
 
-		v.inOrderNodes = append(
-			v.inOrderNodes,
-			&SelectorExpr{
-				SelectorExpr: node,
-				ImportName:   impName,
-			},
-		)
+		selectorExpr := &SelectorExpr{
+			SelectorExpr: node,
+			ImportName:   impName,
+			IsMember:     isMember,
+			ReceiverType: receiverType,
+		}
+		if decl, ok := v.declSpans.enclosing(node.Pos()); ok {
+			selectorExpr.EnclosingDecl = decl.UID()
+		}
+		v.inOrderNodes = append(v.inOrderNodes, selectorExpr)
 	}
 	return v
 }
 
 func (v *DependencyVisitor) addImportSpec(node *ast.ImportSpec) {
 	node.Path.Value = strings.Trim(node.Path.Value, "\"")
-	pieces := strings.Split(node.Path.Value, "/")
-	name := pieces[len(pieces)-1]
+	name := v.importNames.Name(node.Path.Value)
 
 	isAliased := node.Name != nil
 	alias := ""
@@ -167,6 +312,7 @@ func (v *DependencyVisitor) addImportSpec(node *ast.ImportSpec) {
 		alias = node.Name.String()
 		node.Name.Name = name
 		v.fileImports[alias] = struct{}{}
+		v.hasDotImports = v.hasDotImports || alias == internal.DotIdentifier
 	}
 
 	if !isAliased {
@@ -186,12 +332,65 @@ func (v *DependencyVisitor) addImportSpec(node *ast.ImportSpec) {
 	)
 }
 
+// addVarTypes records the type of the variables if they are declared, or
+// initialized with a composite literal, as a type of an import
+func (v *DependencyVisitor) addVarTypes(spec *ast.ValueSpec) {
+	if spec.Type != nil {
+		typ, ok := importedType(spec.Type)
+		if !ok {
+			return
+		}
+		for _, name := range spec.Names {
+			v.varTypes[name.String()] = typ
+		}
+		return
+	}
+	for i, value := range spec.Values {
+		if i >= len(spec.Names) {
+			break
+		}
+		if typ, ok := valueType(value); ok {
+			v.varTypes[spec.Names[i].String()] = typ
+		}
+	}
+}
+
+func (v *DependencyVisitor) addDeclaredIdents(idents ...*ast.Ident) {
+	for _, ident := range idents {
+		v.declaredIdents[ident] = struct{}{}
+	}
+}
+
+// addIdent records exported identifiers used without a selector, only dot
+// imports bring them into the scope of a file
+func (v *DependencyVisitor) addIdent(node *ast.Ident) {
+	if !v.hasDotImports || !node.IsExported() {
+		return
+	}
+	if _, ok := v.declaredIdents[node]; ok {
+		return
+	}
+	ident := &Ident{Ident: node}
+	if decl, ok := v.declSpans.enclosing(node.Pos()); ok {
+		ident.EnclosingDecl = decl.UID()
+	}
+	v.inOrderNodes = append(v.inOrderNodes, ident)
+}
+
 func (v *DependencyVisitor) addFuncDecl(node *ast.FuncDecl) {
 	receiverName := ""
 	qualifiedName := node.Name.String()
 
+	// methods are qualified by their receiver's type name
 	if node.Recv != nil {
-		return
+		if len(node.Recv.List) == 0 {
+			return
+		}
+		receiverName = receiverExprName(node.Recv.List[0].Type)
+		if receiverName == "" {
+			return
+		}
+		qualifiedName = receiverName + "." + qualifiedName
 	}
 
 	v.inOrderNodes = append(
@@ -260,6 +459,85 @@ func (v *DependencyVisitor) exitNode() {
 	v.curFuncScope.CurrentCount--
 }
 
+// memberImportName returns the name of the import a selector chain is rooted
+// at, e.g. b for b.NewThing().Method, b.Var.Field or b.Thing{}.Method
+func memberImportName(expr ast.Expr) string {
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		if x, ok := expr.X.(*ast.Ident); ok {
+			return x.String()
+		}
+		return memberImportName(expr.X)
+	case *ast.CallExpr:
+		return memberImportName(expr.Fun)
+	case *ast.CompositeLit:
+		if expr.Type == nil {
+			return ""
+		}
+		return memberImportName(expr.Type)
+	case *ast.IndexExpr:
+		return memberImportName(expr.X)
+	case *ast.IndexListExpr:
+		return memberImportName(expr.X)
+	case *ast.ParenExpr:
+		return memberImportName(expr.X)
+	case *ast.StarExpr:
+		return memberImportName(expr.X)
+	case *ast.UnaryExpr:
+		return memberImportName(expr.X)
+	}
+	return ""
+}
+
+// memberType is a type declared by an import, the import may not exist as
+// the types of variables are recorded before the imports are known
+type memberType struct {
+	importName string
+	typeName   string
+}
+
+// importedType returns the type of an import a type expression names, e.g.
+// b.T, *b.T or b.T[int]
+func importedType(expr ast.Expr) (memberType, bool) {
+	switch expr := expr.(type) {
+	case *ast.SelectorExpr:
+		x, ok := expr.X.(*ast.Ident)
+		if !ok {
+			return memberType{}, false
+		}
+		return memberType{importName: x.String(), typeName: expr.Sel.String()}, true
+	case *ast.StarExpr:
+		return importedType(expr.X)
+	case *ast.IndexExpr:
+		return importedType(expr.X)
+	case *ast.IndexListExpr:
+		return importedType(expr.X)
+	case *ast.ParenExpr:
+		return importedType(expr.X)
+	}
+	return memberType{}, false
+}
+
+// valueType returns the type of an import a value is a composite literal of,
+// e.g. b.T{} or &b.T{}
+func valueType(expr ast.Expr) (memberType, bool) {
+	switch expr := expr.(type) {
+	case *ast.CompositeLit:
+		if expr.Type == nil {
+			return memberType{}, false
+		}
+		return importedType(expr.Type)
+	case *ast.UnaryExpr:
+		if expr.Op != token.AND {
+			return memberType{}, false
+		}
+		return valueType(expr.X)
+	case *ast.ParenExpr:
+		return valueType(expr.X)
+	}
+	return memberType{}, false
+}
+
 func getTypeName(typ ast.Expr) []string {
 	switch expr := typ.(type) {
 	case *ast.Ident:
@@ -232,6 +232,9 @@ func TestDependencyVisitor_Visit_Functions(t *testing.T) {
 	}
 
 	expectedConstants := []string{
+		"FTA1",
+		"FTA2",
+		"FC",
 		"F1",
 	}
 
@@ -279,3 +282,42 @@ func TestDependencyVisitor_Visit_SelectorExprs(t *testing.T) {
 		t.Error(test.Mismatch("", diff))
 	}
 }
+
+func TestDependencyVisitor_Visit_Idents(t *testing.T) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal("finding working dir:", err)
+	}
+
+	absFilepath := filepath.Join(cwd, depVisTestdataDir, "idents.go")
+
+	fset := token.NewFileSet()
+	astFile, err := parser.ParseFile(fset, absFilepath, nil, 0)
+	if err != nil {
+		t.Fatal("parse file: ", err)
+	}
+	depVis := primitives.NewDependencyVisitor()
+	ast.Walk(depVis, astFile)
+
+	var actualIdents []string
+	for _, node := range depVis.InOrderNodes() {
+		ident, ok := node.(*primitives.Ident)
+		if !ok {
+			continue
+		}
+		actualIdents = append(actualIdents, ident.EnclosingDecl+": "+ident.String())
+	}
+
+	expectedIdents := []string{
+		"IdTrimmed: TrimSpace",
+		"IdFn: IdOptions",
+		"IdFn: ToUpper",
+		"IdFn: Local",
+		"IdFn: Repeat",
+		"IdFn: IdTrimmed",
+	}
+
+	if diff := cmp.Diff(expectedIdents, actualIdents); diff != "" {
+		t.Error(test.Mismatch("", diff))
+	}
+}
@@ -0,0 +1,182 @@
+package primitives
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ImportNames resolves the package name of an import path
+type ImportNames map[string]string
+
+// Name returns the package name of the import path, falling back to the name
+// assumed from the import path, see ImportPathToName
+func (names ImportNames) Name(importPath string) string {
+	if name, ok := names[importPath]; ok && name != "" {
+		return name
+	}
+	return ImportPathToName(importPath)
+}
+
+// ImportPathToName returns the package name assumed from the import path,
+// the last path element without a major version suffix, e.g. /v2 or .v3,
+// a go- prefix and anything which isn't part of an identifier
+func ImportPathToName(importPath string) string {
+	base := path.Base(importPath)
+	if isMajorVersion(base) {
+		if dir := path.Dir(importPath); dir != "." {
+			base = path.Base(dir)
+		}
+	}
+	base = strings.TrimPrefix(base, "go-")
+	if i := strings.IndexFunc(base, func(r rune) bool {
+		return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
+
+func isMajorVersion(elem string) bool {
+	if !strings.HasPrefix(elem, "v") {
+		return false
+	}
+	_, err := strconv.Atoi(elem[1:])
+	return err == nil
+}
+
+// isStdlib reports whether the import path belongs to the standard library,
+// whose first path element never contains a dot
+func isStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// listedNames keeps the package names listed by the go command for the rest
+// of the run, keyed by listedNamesKey, so rebuilds, e.g. by watch, don't run
+// it again for the same imports
+var listedNames = struct {
+	sync.Mutex
+	byKey map[string]ImportNames
+}{byKey: make(map[string]ImportNames)}
+
+// resolveExternal adds the package names of the external import paths, e.g.
+// of third-party modules, as listed by the go command from the module cache.
+// Import paths which cannot be listed, e.g. the module isn't downloaded or the
+// go command isn't installed, are left to the name assumed from the import
+// path. The go command is only run for import paths it wasn't asked for
+// before, in this run or one kept in the cache, with the same requirements
+// and target.
+func (names ImportNames) resolveExternal(modules []*parsedModule, importPaths []string, options *options, cache *fileCache) {
+	unresolved := names.unresolved(importPaths)
+	if len(unresolved) == 0 {
+		return
+	}
+
+	key := listedNamesKey(modules, options)
+	listedNames.Lock()
+	defer listedNames.Unlock()
+	listed, ok := listedNames.byKey[key]
+	if !ok {
+		listed = make(ImportNames)
+		cache.load(key, &listed)
+		listedNames.byKey[key] = listed
+	}
+	var unlisted []string
+	for _, importPath := range unresolved {
+		if _, ok := listed[importPath]; !ok {
+			unlisted = append(unlisted, importPath)
+		}
+	}
+	if len(unlisted) != 0 && listed.list(modules[0].Dir, unlisted, options) {
+		cache.store(key, listed)
+	}
+	for _, importPath := range unresolved {
+		if name := listed[importPath]; name != "" {
+			names[importPath] = name
+		}
+	}
+}
+
+// list adds the package names of the import paths listed by the go command,
+// import paths it can't list are added without a name so it isn't asked
+// again. It reports whether the go command ran.
+func (names ImportNames) list(moduleDir string, importPaths []string, options *options) bool {
+	args := []string{"list", "-e", "-find", "-f", "{{.ImportPath}}\t{{.Name}}"}
+	args = append(args, options.buildFlags()...)
+	args = append(args, importPaths...)
+	cmd := exec.Command("go", args...)
+	cmd.Dir = moduleDir
+	// never download modules, only the module cache is consulted
+	cmd.Env = append(options.env(), "GOPROXY=off")
+	out, err := cmd.Output()
+	if err != nil && len(out) == 0 {
+		return false
+	}
+	for _, importPath := range importPaths {
+		names[importPath] = ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		importPath, name, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		names[importPath] = name
+	}
+	return true
+}
+
+// listedNamesKey is the hash of what the names listed by the go command
+// depend on, the requirements of the modules and the target
+func listedNamesKey(modules []*parsedModule, options *options) string {
+	ctx := options.buildContext()
+	hash := sha256.New()
+	for _, part := range []string{cacheVersion, "import names", ctx.GOOS, ctx.GOARCH, strings.Join(ctx.BuildTags, ",")} {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	for _, module := range modules {
+		hash.Write([]byte(module.Path))
+		hash.Write([]byte{0})
+		hash.Write([]byte(module.Dir))
+		hash.Write([]byte{0})
+		for _, name := range []string{"go.mod", "go.sum"} {
+			// a missing go.sum hashes like an empty one
+			src, _ := os.ReadFile(filepath.Join(module.Dir, name))
+			hash.Write(src)
+			hash.Write([]byte{0})
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// unresolved returns the import paths the go command is asked for, sorted and
+// without duplicates, the import paths of every file are passed in so the same
+// path is repeated once per importing file and would overflow the command
+// line of large modules
+func (names ImportNames) unresolved(importPaths []string) []string {
+	var unresolved []string
+	for _, importPath := range importPaths {
+		if _, ok := names[importPath]; ok {
+			continue
+		}
+		// the assumed name is always right for the standard library
+		if importPath == "C" || isStdlib(importPath) {
+			continue
+		}
+		unresolved = append(unresolved, importPath)
+	}
+	slices.Sort(unresolved)
+	return slices.Compact(unresolved)
+}
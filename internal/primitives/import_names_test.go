@@ -0,0 +1,24 @@
+package primitives_test
+
+import (
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"testing"
+)
+
+func TestImportPathToName(t *testing.T) {
+	testCases := map[string]string{
+		"fmt":                        "fmt",
+		"math/rand/v2":               "rand",
+		"github.com/x/y/v2":          "y",
+		"gopkg.in/yaml.v3":           "yaml",
+		"github.com/mattn/go-isatty": "isatty",
+		"github.com/x/go-y.v1/v3":    "y",
+		"v2":                         "v2",
+	}
+
+	for importPath, expected := range testCases {
+		if actual := primitives.ImportPathToName(importPath); actual != expected {
+			t.Errorf("%s: expected %s, got %s", importPath, expected, actual)
+		}
+	}
+}
@@ -5,93 +5,152 @@
 	"fmt"
 	"go/ast"
 	"go/token"
+	"maps"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/graph"
 )
 
 type PrimitiveBuilder struct {
-	modulePath    string
-	moduleRootDir string
+	module *internal.Module
 
+	modulesByUID  map[string]*internal.Module
+	requiredByUID map[string]*internal.Module
 	packagesByUID map[string]*internal.Package
 	filesByUID    map[string]*internal.File
 
+	// membersByPkgUID are the methods and the struct types declaring fields
+	// of each package by method or field name, see addMemberReference
+	membersByPkgUID  map[string]map[string][]*internal.Decl
+	memberReferences []*memberReference
+	// dotReferences are the identifiers used without a selector by files
+	// with dot imports, see resolveDotReferences
+	dotReferences []*dotReference
+
 	curPkg  *internal.Package
 	curFile *internal.File
 }
 
 func NewPrimitiveBuilder(modulePath, moduleRootDir string) *PrimitiveBuilder {
-	return &PrimitiveBuilder{
-		modulePath:    modulePath,
-		moduleRootDir: moduleRootDir,
+	builder := &PrimitiveBuilder{
+		modulesByUID:    make(map[string]*internal.Module),
+		requiredByUID:   make(map[string]*internal.Module),
+		packagesByUID:   make(map[string]*internal.Package),
+		filesByUID:      make(map[string]*internal.File),
+		membersByPkgUID: make(map[string]map[string][]*internal.Decl),
+	}
+	builder.UseModule(modulePath, moduleRootDir)
+	return builder
+}
 
-		packagesByUID: make(map[string]*internal.Package),
-		filesByUID:    make(map[string]*internal.File),
+// UseModule sets the module the packages added next belong to, e.g. the
+// modules of a workspace one after another
+func (builder *PrimitiveBuilder) UseModule(modulePath, moduleRootDir string) {
+	mod, ok := builder.modulesByUID[modulePath]
+	if !ok {
+		mod = &internal.Module{
+			Path:     modulePath,
+			Dir:      moduleRootDir,
+			Packages: make(map[string]*internal.Package),
+		}
+		builder.modulesByUID[mod.UID()] = mod
+	}
+	builder.module = mod
+	builder.curPkg = nil
+	builder.curFile = nil
+}
+
+// AddRequire adds a module required by the modules, stub packages imported
+// from it belong to it rather than to no module
+func (builder *PrimitiveBuilder) AddRequire(modulePath, version string) {
+	if _, ok := builder.requiredByUID[modulePath]; ok {
+		return
+	}
+	builder.requiredByUID[modulePath] = &internal.Module{
+		Path:     modulePath,
+		Version:  version,
+		Packages: make(map[string]*internal.Package),
+		IsStub:   true,
 	}
 }
 
 func (builder *PrimitiveBuilder) MarkupImportCycles() error {
+	builder.resolveMemberReferences()
+	builder.resolveDotReferences()
 	builder.fixupBlankFileImports()
-	stk := NewFileStack()
-	for _, baseFile := range builder.filesByUID {
-		stk.Push(baseFile)
-		err := builder.markupImportCycles(baseFile, stk)
-		if err != nil {
-			return err
+	builder.assignStubModules()
+	pkgs := builder.Packages()
+	builder.markupFileImportCycles(graph.ForFiles(pkgs))
+	builder.markupPackageImportCycles(graph.ForPackageReferences(pkgs))
+	builder.markupModuleImportCycles(graph.ForModules(pkgs))
+	return nil
+}
+
+func (builder *PrimitiveBuilder) markupFileImportCycles(g *graph.Graph) {
+	for i, component := range g.StronglyConnectedComponents() {
+		scc := i + 1
+		for _, node := range component {
+			node.File.SCC = scc
+		}
+		if !g.IsCyclic(component) {
+			continue
+		}
+		for _, node := range component {
+			node.File.InImportCycle = true
+			node.File.Package.InImportCycle = true
+		}
+	}
+	for _, e := range g.Edges() {
+		if e.From.File.SCC != e.To.File.SCC || !e.From.File.InImportCycle {
+			continue
+		}
+		for _, imp := range e.Imports {
+			imp.InImportCycle = true
+			imp.ReferencedFilesInCycle[e.To.File.UID()] = e.To.File
 		}
-		stk.Pop()
 	}
-	return nil
 }
 
-func (builder *PrimitiveBuilder) markupImportCycles(
-	baseFile *internal.File,
-	stk *FileStack,
-) error {
-	for _, imp := range baseFile.Imports {
-		for _, typ := range imp.ReferencedTypes {
-			refFile := typ.File
-			if stk.Contains(refFile) {
-				for i := stk.Len() - 1; i > 0; i-- {
-					curFile := stk.At(i)
-					curFile.InImportCycle = true
-					curFile.Package.InImportCycle = true
-					imp.InImportCycle = true
-					imp.ReferencedFilesInCycle[refFile.UID()] = refFile
-					if curFile.UID() == baseFile.UID() {
-						return nil
-					}
-				}
-			}
-			stk.Push(refFile)
-			err := builder.markupImportCycles(refFile, stk)
-			if err != nil {
-				return err
-			}
-			stk.Pop()
-		}
-	}
-	for _, refFile := range baseFile.ReferencedFiles() {
-		// If you eventually import yourself, it's a cycle
-		if stk.Contains(refFile) {
-			for i := stk.Len() - 1; i > 0; i-- {
-				curFile := stk.At(i)
-				curFile.InImportCycle = true
-				curFile.Package.InImportCycle = true
-				if curFile.UID() == baseFile.UID() {
-					return nil
-				}
-			}
+func (builder *PrimitiveBuilder) markupPackageImportCycles(g *graph.Graph) {
+	for i, component := range g.StronglyConnectedComponents() {
+		scc := i + 1
+		for _, node := range component {
+			node.Package.SCC = scc
+		}
+		if !g.IsCyclic(component) {
+			continue
 		}
-		stk.Push(refFile)
-		err := builder.markupImportCycles(refFile, stk)
-		if err != nil {
-			return err
+		for _, node := range component {
+			node.Package.InImportCycle = true
+		}
+	}
+	for _, e := range g.Edges() {
+		if e.From.Package.SCC != e.To.Package.SCC || !e.From.Package.InImportCycle {
+			continue
+		}
+		for _, imp := range e.Imports {
+			imp.InImportCycle = true
+			imp.SCC = e.From.Package.SCC
+		}
+	}
+}
+
+func (builder *PrimitiveBuilder) markupModuleImportCycles(g *graph.Graph) {
+	for i, component := range g.StronglyConnectedComponents() {
+		scc := i + 1
+		for _, node := range component {
+			node.Module.SCC = scc
+		}
+		if !g.IsCyclic(component) {
+			continue
+		}
+		for _, node := range component {
+			node.Module.InImportCycle = true
 		}
-		stk.Pop()
 	}
-	return nil
 }
 
 func (builder *PrimitiveBuilder) fixupBlankFileImports() {
@@ -122,6 +181,8 @@ func (builder *PrimitiveBuilder) AddNode(node ast.Node) error {
 	case *File:
 		return builder.addFile(node)
 
+	case *Ident:
+		return builder.addIdent(node)
 	case *ImportSpec:
 		return builder.addImport(node)
 
@@ -156,12 +217,14 @@ func (builder *PrimitiveBuilder) Packages() []*internal.Package {
 
 func (builder *PrimitiveBuilder) addPackage(node *Package) error {
 	newPkg := buildPackage(
-		builder.modulePath,
-		builder.moduleRootDir,
+		builder.module.Path,
+		builder.module.Dir,
 		node.DirName,
 		node.Name,
 		len(node.Files),
 	)
+	newPkg.Module = builder.module
+	newPkg.IsTest = node.IsTest
 	newPkgUID := newPkg.UID()
 
 	pkg, pkgExists := builder.packagesByUID[newPkgUID]
@@ -183,6 +246,7 @@ func (builder *PrimitiveBuilder) addPackage(node *Package) error {
 	}
 
 	builder.curPkg = builder.packagesByUID[newPkgUID]
+	builder.module.Packages[newPkgUID] = builder.curPkg
 	return nil
 }
 
@@ -196,6 +260,7 @@ func (builder *PrimitiveBuilder) addFile(node *File) error {
 		AbsPath:  node.AbsPath,
 		Imports:  make(map[string]*internal.Import),
 		Decls:    make(map[string]*internal.Decl),
+		IsTest:   node.IsTest,
 	}
 	fileUID := file.UID()
 	if _, ok := builder.filesByUID[fileUID]; ok {
@@ -221,12 +286,14 @@ func (builder *PrimitiveBuilder) addImport(node *ImportSpec) error {
 		Name:                   node.Name.String(),
 		Path:                   node.Path.Value,
 		IsAliased:              node.IsAliased,
+		IsImplicit:             node.IsImplicit,
 		ReferencedTypes:        make(map[string]*internal.Decl),
 		ReferencedFilesInCycle: make(map[string]*internal.File),
 	}
 	if node.IsAliased {
 		imp.Alias = node.Alias
-		imp.IsBlank = node.Alias == "_"
+		imp.IsBlank = node.Alias == internal.BlankIdentifier
+		imp.IsDot = node.Alias == internal.DotIdentifier
 	}
 
 	impUID := imp.UID()
@@ -235,7 +302,7 @@ func (builder *PrimitiveBuilder) addImport(node *ImportSpec) error {
 	}
 
 	// if the package exists, use it, otherwise use a stub
-	pkg := buildPackage(builder.modulePath, builder.moduleRootDir, imp.Path, imp.Name, 1)
+	pkg := buildPackage(builder.module.Path, builder.module.Dir, imp.Path, imp.Name, 1)
 	pkg.IsStub = true
 	if _, ok := builder.packagesByUID[pkg.UID()]; ok {
 		pkg = builder.packagesByUID[pkg.UID()]
@@ -288,20 +355,35 @@ func (builder *PrimitiveBuilder) addFuncDecl(node *FuncDecl) error {
 	if node.Name.String() == "" {
 		return fmt.Errorf("add func decl: invalid function name")
 	}
-	declUID := node.QualifiedName
-	if _, ok := builder.curFile.Decls[declUID]; ok {
-		return fmt.Errorf("add func decl: duplicate declaration: %s", node.QualifiedName)
-	}
 	decl := &internal.Decl{
-		File: builder.curFile,
-		Name: node.Name.String(),
+		File:     builder.curFile,
+		Name:     node.Name.String(),
+		FuncName: node.ReceiverName,
+		IsMethod: node.IsReceiver(),
+	}
+	if _, ok := builder.curFile.Decls[decl.UID()]; ok {
+		return fmt.Errorf("add func decl: duplicate declaration: %s", node.QualifiedName)
 	}
 	decl = builder.fixupStubDecl(decl)
-	builder.curFile.Decls[declUID] = decl
+	builder.curFile.Decls[decl.UID()] = decl
+	if node.IsReceiver() {
+		builder.addMember(decl.Name, decl)
+	}
 
 	return nil
 }
 
+// addMember indexes a method, or the struct type declaring a field, of the
+// current package by the method or field name
+func (builder *PrimitiveBuilder) addMember(name string, decl *internal.Decl) {
+	members, ok := builder.membersByPkgUID[builder.curPkg.UID()]
+	if !ok {
+		members = make(map[string][]*internal.Decl)
+		builder.membersByPkgUID[builder.curPkg.UID()] = members
+	}
+	members[name] = append(members[name], decl)
+}
+
 func (builder *PrimitiveBuilder) addGenDecl(node *GenDecl) error {
 	if builder.curPkg == nil {
 		return errors.New("add gen decl: no package defined")
@@ -332,6 +414,11 @@ func (builder *PrimitiveBuilder) addGenDecl(node *GenDecl) error {
 			}
 			decl = builder.fixupStubDecl(decl)
 			builder.curFile.Decls[decl.UID()] = decl
+			if structType, ok := spec.Type.(*ast.StructType); ok && node.FuncScopeName == "" {
+				for _, name := range fieldNames(structType) {
+					builder.addMember(name, decl)
+				}
+			}
 
 		case *ast.ValueSpec:
 			if node.Tok != token.CONST && node.Tok != token.VAR {
@@ -375,13 +462,23 @@ func (builder *PrimitiveBuilder) addSelectorExpr(node *SelectorExpr) error {
 	if !hasImp {
 		return fmt.Errorf("add selector expr: no import defined: %s", node.Sel.String())
 	}
+	if node.IsMember {
+		builder.memberReferences = append(builder.memberReferences, &memberReference{
+			imp:           imp,
+			name:          node.Sel.String(),
+			receiverType:  node.ReceiverType,
+			enclosingDecl: node.EnclosingDecl,
+		})
+		return nil
+	}
 
 	decl := &internal.Decl{
 		Name: node.Sel.String(),
 	}
 
-	if _, ok := imp.ReferencedTypes[decl.Name]; ok {
+	if registered, ok := imp.ReferencedTypes[decl.Name]; ok {
 		// type already registered
+		addReferencedTypeByDecl(imp, node.EnclosingDecl, decl.Name, registered)
 		return nil
 	}
 
@@ -427,9 +524,259 @@ func (builder *PrimitiveBuilder) addSelectorExpr(node *SelectorExpr) error {
 	}
 
 	imp.ReferencedTypes[decl.Name] = decl
+	addReferencedTypeByDecl(imp, node.EnclosingDecl, decl.Name, decl)
+	return nil
+}
+
+func (builder *PrimitiveBuilder) addIdent(node *Ident) error {
+	if builder.curPkg == nil {
+		return fmt.Errorf("add ident: no package defined: %s", node.String())
+	}
+	if builder.curFile == nil {
+		return fmt.Errorf("add ident: no file defined: %s", node.String())
+	}
+	builder.dotReferences = append(builder.dotReferences, &dotReference{
+		file:          builder.curFile,
+		name:          node.String(),
+		enclosingDecl: node.EnclosingDecl,
+	})
 	return nil
 }
 
+// dotReference is an identifier used without a selector by a file with dot
+// imports, which is resolved once every package has been added
+type dotReference struct {
+	file          *internal.File
+	name          string
+	enclosingDecl string
+}
+
+// resolveDotReferences resolves the identifiers used without a selector to
+// the declaration of the dot imported package declaring them. Identifiers
+// no dot imported package declares, e.g. local variables or declarations of
+// packages outside of the modules, are ignored.
+func (builder *PrimitiveBuilder) resolveDotReferences() {
+	for _, ref := range builder.dotReferences {
+		for _, impUID := range slices.Sorted(maps.Keys(ref.file.Imports)) {
+			imp := ref.file.Imports[impUID]
+			if !imp.IsDot || imp.Package == nil {
+				continue
+			}
+			decl, ok := packageDecl(imp.Package, ref.name)
+			if !ok {
+				continue
+			}
+			imp.ReferencedTypes[decl.UID()] = decl
+			addReferencedTypeByDecl(imp, ref.enclosingDecl, decl.UID(), decl)
+			break
+		}
+	}
+	builder.dotReferences = nil
+}
+
+// packageDecl finds the top level declaration of the package with the name
+func packageDecl(pkg *internal.Package, name string) (*internal.Decl, bool) {
+	for _, file := range pkg.Files {
+		if file.IsStub {
+			continue
+		}
+		if decl, ok := file.Decls[name]; ok {
+			return decl, true
+		}
+	}
+	return nil, false
+}
+
+// memberReference is a method or field selected from a value of an imported
+// package, which is resolved once every package has been added
+type memberReference struct {
+	imp           *internal.Import
+	name          string
+	receiverType  string
+	enclosingDecl string
+}
+
+// resolveMemberReferences resolves the methods and fields selected from values
+// of imported packages to the method, or the struct type declaring the field,
+// of the imported package with the name, see memberOf. Members of packages
+// outside of the modules are left unresolved.
+func (builder *PrimitiveBuilder) resolveMemberReferences() {
+	for _, ref := range builder.memberReferences {
+		if ref.imp.Package == nil {
+			continue
+		}
+		members := builder.membersByPkgUID[ref.imp.Package.UID()][ref.name]
+		decl := memberOf(members, ref.receiverType)
+		if decl == nil {
+			continue
+		}
+		ref.imp.ReferencedTypes[decl.UID()] = decl
+		addReferencedTypeByDecl(ref.imp, ref.enclosingDecl, decl.UID(), decl)
+	}
+	builder.memberReferences = nil
+}
+
+// memberOf returns the member declared by the receiver's type, the method or
+// the struct type declaring the field. Members of unknown types, or promoted
+// from embedded types, resolve to the only member of the name, names declared
+// by more than one type of the package are left unresolved.
+func memberOf(members []*internal.Decl, receiverType string) *internal.Decl {
+	if receiverType != "" {
+		for _, member := range members {
+			if member.IsMethod && member.FuncName == receiverType {
+				return member
+			}
+			if !member.IsMethod && member.Name == receiverType {
+				return member
+			}
+		}
+	}
+	if len(members) != 1 {
+		return nil
+	}
+	return members[0]
+}
+
+// addReferencedTypeByDecl records the referenced type for the top level
+// declaration referencing it, references outside of a declaration are only
+// recorded for the file
+func addReferencedTypeByDecl(imp *internal.Import, enclosingDecl, key string, decl *internal.Decl) {
+	if enclosingDecl == "" {
+		return
+	}
+	if imp.ReferencedTypesByDecl == nil {
+		imp.ReferencedTypesByDecl = make(map[string]map[string]*internal.Decl)
+	}
+	refs, ok := imp.ReferencedTypesByDecl[enclosingDecl]
+	if !ok {
+		refs = make(map[string]*internal.Decl)
+		imp.ReferencedTypesByDecl[enclosingDecl] = refs
+	}
+	refs[key] = decl
+}
+
+// AddReference records a reference resolved by the type checker. The
+// referencing file's import of the declaration's package is added as an
+// implicit import if the file doesn't import the package itself.
+func (builder *PrimitiveBuilder) AddReference(ref *Reference) error {
+	file, ok := builder.filesByUID[ref.FileUID]
+	if !ok {
+		return fmt.Errorf("add reference: no file defined: %s", ref.FileUID)
+	}
+	builder.curPkg = file.Package
+	builder.curFile = file
+
+	imp, ok := file.Imports[ref.ImportUID]
+	if !ok {
+		implicit := &ImportSpec{
+			ImportSpec: &ast.ImportSpec{
+				Name: ast.NewIdent(ref.ImportName),
+				Path: &ast.BasicLit{Kind: token.STRING, Value: ref.ImportPath},
+			},
+			IsImplicit: true,
+		}
+		if err := builder.addImport(implicit); err != nil {
+			return fmt.Errorf("add reference: %w", err)
+		}
+		imp = file.Imports[ref.ImportPath]
+	}
+	if imp.Package == nil {
+		return fmt.Errorf("add reference: no package defined here: %s", ref.Decl.QualifiedName())
+	}
+
+	declFile, ok := builder.filesByUID[ref.DeclFileUID]
+	if !ok {
+		// declarations outside the module are kept in the package's stub file
+		for _, pkgFile := range imp.Package.Files {
+			if pkgFile.IsStub && !pkgFile.IsBlankImport {
+				declFile = pkgFile
+				break
+			}
+		}
+	}
+	if declFile == nil {
+		return fmt.Errorf("add reference: no stub file defined: %s", ref.Decl.QualifiedName())
+	}
+
+	decl, ok := declFile.Decls[ref.Decl.UID()]
+	if !ok {
+		decl = &internal.Decl{
+			File:     declFile,
+			Name:     ref.Decl.Name,
+			FuncName: ref.Decl.FuncName,
+			IsMethod: ref.Decl.IsMethod,
+		}
+		declFile.Decls[decl.UID()] = decl
+	}
+	imp.ReferencedTypes[decl.UID()] = decl
+	addReferencedTypeByDecl(imp, ref.EnclosingDecl, decl.UID(), decl)
+	return nil
+}
+
+// assignStubModules assigns each stub package to the required module with
+// the longest path prefixing its import path or to the standard library
+func (builder *PrimitiveBuilder) assignStubModules() {
+	var stdlib *internal.Module
+	for _, pkg := range builder.packagesByUID {
+		if !pkg.IsStub || pkg.Module != nil {
+			continue
+		}
+		importPath := pkg.ImportPath()
+		mod := longestPrefixModule(builder.requiredByUID, importPath)
+		// a package of an analyzed module which doesn't exist
+		if own := longestPrefixModule(builder.modulesByUID, importPath); own != nil && (mod == nil || len(own.Path) >= len(mod.Path)) {
+			continue
+		}
+		if mod == nil && importPath != "C" && isStdlib(importPath) {
+			if stdlib == nil {
+				stdlib = &internal.Module{
+					Path:     internal.StdlibModulePath,
+					Packages: make(map[string]*internal.Package),
+					IsStub:   true,
+					IsStdlib: true,
+				}
+			}
+			mod = stdlib
+		}
+		if mod == nil {
+			continue
+		}
+		pkg.Module = mod
+		mod.Packages[pkg.UID()] = pkg
+	}
+}
+
+func longestPrefixModule(modulesByUID map[string]*internal.Module, importPath string) *internal.Module {
+	var found *internal.Module
+	for modulePath, mod := range modulesByUID {
+		if importPath != modulePath && !strings.HasPrefix(importPath, modulePath+"/") {
+			continue
+		}
+		if found == nil || len(modulePath) > len(found.Path) {
+			found = mod
+		}
+	}
+	return found
+}
+
+// removeEmptyStubFiles removes the stub files left without declarations in
+// packages of the module, references resolved by the type checker never
+// declare anything in them
+func (builder *PrimitiveBuilder) removeEmptyStubFiles() {
+	for _, pkg := range builder.packagesByUID {
+		if pkg.IsStub {
+			continue
+		}
+		for fileUID, file := range pkg.Files {
+			if !file.IsStub || len(file.Decls) > 0 {
+				continue
+			}
+			delete(pkg.Files, fileUID)
+			delete(builder.filesByUID, fileUID)
+		}
+	}
+}
+
 func (builder *PrimitiveBuilder) fixupStubDecl(newDecl *internal.Decl) *internal.Decl {
 	for fileUID, file := range builder.curPkg.Files {
 		// can only fix-up declarations in stub files
@@ -459,6 +806,27 @@ func (builder *PrimitiveBuilder) fixupStubDecl(newDecl *internal.Decl) *internal
 	return newDecl
 }
 
+// fieldNames are the names of the fields of the struct type, embedded
+// fields are named after their type
+func fieldNames(structType *ast.StructType) []string {
+	if structType.Fields == nil {
+		return nil
+	}
+	var names []string
+	for _, field := range structType.Fields.List {
+		if len(field.Names) == 0 {
+			if name := receiverExprName(field.Type); name != "" {
+				names = append(names, name)
+			}
+			continue
+		}
+		for _, name := range field.Names {
+			names = append(names, name.String())
+		}
+	}
+	return names
+}
+
 func buildPackage(
 	modulePath,
 	moduleRootDir,
@@ -517,16 +885,21 @@ func buildBlankDecl(file *internal.File) *internal.Decl {
 
 func shallowCopyPackage(to, from *internal.Package) {
 	to.DirName = from.DirName
+	to.Module = from.Module
+	to.ModulePath = from.ModulePath
 	to.ModuleDir = from.ModuleDir
 	to.Name = from.Name
 	to.BlankImportFile = from.BlankImportFile
 	to.IsStub = from.IsStub
+	to.IsTest = from.IsTest
 	to.InImportCycle = from.InImportCycle
 }
 
 func copyDeclaration(to, from *internal.Decl) {
 	to.File = from.File
 	to.Name = from.Name
+	to.FuncName = from.FuncName
+	to.IsMethod = from.IsMethod
 }
 
 func copyImports(to, from *internal.File) {
@@ -537,51 +910,3 @@ func copyImports(to, from *internal.File) {
 		to.Imports[uid] = imp
 	}
 }
-
-type FileStack struct {
-	indexByUID map[string]int
-	stack      []*internal.File
-}
-
-func NewFileStack() *FileStack {
-	return &FileStack{
-		indexByUID: make(map[string]int),
-		stack:      make([]*internal.File, 0),
-	}
-}
-
-func (s *FileStack) Push(f *internal.File) {
-	s.stack = append(s.stack, f)
-	s.indexByUID[f.UID()] = len(s.stack) - 1
-}
-
-func (s *FileStack) Pop() {
-	delete(s.indexByUID, s.stack[len(s.stack)-1].UID())
-	s.stack = s.stack[0 : len(s.stack)-1]
-}
-
-func (s *FileStack) Top() *internal.File {
-	if len(s.stack) == 0 {
-		return nil
-	}
-	return s.stack[len(s.stack)-1]
-}
-
-func (s *FileStack) At(i int) *internal.File {
-	if len(s.stack) == 0 {
-		return nil
-	}
-	if i > (len(s.stack) - 1) {
-		return nil
-	}
-	return s.stack[i]
-}
-
-func (s *FileStack) Contains(f *internal.File) bool {
-	_, ok := s.indexByUID[f.UID()]
-	return ok
-}
-
-func (s *FileStack) Len() int {
-	return len(s.stack)
-}
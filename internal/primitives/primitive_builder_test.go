@@ -57,6 +57,11 @@ func TestPrimitiveBuilder_AddNode_Package(t *testing.T) {
 	for desc, testCase := range testCases {
 		builder := primitives.NewPrimitiveBuilder(modulePath, moduleRoot)
 		expected := testCase.expected
+		expected.Module = &internal.Module{
+			Path:     modulePath,
+			Dir:      moduleRoot,
+			Packages: map[string]*internal.Package{expected.UID(): expected},
+		}
 		err = builder.AddNode(&primitives.Package{
 			Package: &ast.Package{
 				Name: expected.Name,
@@ -135,6 +140,11 @@ func TestPrimitiveBuilder_AddNode_File(t *testing.T) {
 	for desc, testCase := range testCases {
 		builder := primitives.NewPrimitiveBuilder(modulePath, moduleRoot)
 		expected := testCase.pkg
+		expected.Module = &internal.Module{
+			Path:     modulePath,
+			Dir:      moduleRoot,
+			Packages: map[string]*internal.Package{expected.UID(): expected},
+		}
 		err = builder.AddNode(&primitives.Package{
 			Package: &ast.Package{
 				Name: expected.Name,
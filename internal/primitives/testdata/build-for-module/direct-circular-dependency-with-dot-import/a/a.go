@@ -0,0 +1,9 @@
+package a
+
+import "github.com/fake/fake/b"
+
+func Fn() {
+	b.Run()
+}
+
+func Helper() {}
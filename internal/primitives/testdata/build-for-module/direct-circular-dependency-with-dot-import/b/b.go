@@ -0,0 +1,7 @@
+package b
+
+import . "github.com/fake/fake/a"
+
+func Run() {
+	Helper()
+}
@@ -0,0 +1,9 @@
+package a
+
+import (
+	"github.com/fake/fake/b"
+)
+
+func Name() string {
+	return b.Name()
+}
@@ -0,0 +1,9 @@
+package a
+
+import (
+	"github.com/fake/fake/c"
+)
+
+func Name() string {
+	return c.Name()
+}
@@ -0,0 +1,9 @@
+//go:build extra
+
+package a
+
+import (
+	"github.com/fake/fake/d"
+)
+
+var Extra = d.Name()
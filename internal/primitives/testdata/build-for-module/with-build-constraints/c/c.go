@@ -0,0 +1,5 @@
+package c
+
+func Name() string {
+	return "c"
+}
@@ -0,0 +1,5 @@
+package d
+
+func Name() string {
+	return "d"
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"log"
+
+	"github.com/fake/fake/a"
+)
+
+func main() {
+	log.Println(a.Name())
+}
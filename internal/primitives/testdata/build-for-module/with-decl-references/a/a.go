@@ -0,0 +1,19 @@
+package a
+
+import "github.com/fake/fake/b"
+
+var V = b.G
+
+type T struct {
+	x b.B
+}
+
+func (t T) M() {
+	b.H()
+}
+
+func Fn() {
+	b.G()
+}
+
+func Unrelated() {}
@@ -0,0 +1,13 @@
+package b
+
+import "github.com/fake/fake/a"
+
+type B struct{}
+
+func G() {}
+
+func H() {}
+
+func K() {
+	a.Fn()
+}
@@ -0,0 +1,8 @@
+package main
+
+import "github.com/fake/fake/a"
+
+func main() {
+	a.Fn()
+	_ = a.T{}
+}
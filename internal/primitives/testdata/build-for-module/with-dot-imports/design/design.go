@@ -0,0 +1,16 @@
+package design
+
+import . "github.com/fake/fake/dsl"
+
+type options struct {
+	Description string
+}
+
+const Name = "design"
+
+var Attr = Attribute("id", String)
+
+func Design() {
+	Description(Name)
+	_ = options{Description: Name}
+}
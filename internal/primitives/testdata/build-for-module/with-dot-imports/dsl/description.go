@@ -0,0 +1,7 @@
+package dsl
+
+import "github.com/fake/fake/design"
+
+func Description(description string) {
+	_ = design.Name
+}
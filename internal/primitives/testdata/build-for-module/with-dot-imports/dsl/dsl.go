@@ -0,0 +1,9 @@
+package dsl
+
+type Type struct{}
+
+const String = "string"
+
+func Attribute(name string, typ string) Type {
+	return Type{}
+}
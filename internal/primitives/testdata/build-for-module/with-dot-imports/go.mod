@@ -0,0 +1,3 @@
+module github.com/fake/fake
+
+go 1.24
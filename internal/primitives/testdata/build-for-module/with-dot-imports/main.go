@@ -0,0 +1,7 @@
+package main
+
+import "github.com/fake/fake/design"
+
+func main() {
+	design.Design()
+}
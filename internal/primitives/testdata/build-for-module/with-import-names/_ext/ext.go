@@ -0,0 +1,3 @@
+package extension
+
+func Fn() {}
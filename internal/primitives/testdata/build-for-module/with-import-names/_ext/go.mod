@@ -0,0 +1,3 @@
+module github.com/fake/ext/v2
+
+go 1.24
@@ -0,0 +1,7 @@
+module github.com/fake/fake
+
+go 1.24
+
+require github.com/fake/ext/v2 v2.0.0
+
+replace github.com/fake/ext/v2 => ./_ext
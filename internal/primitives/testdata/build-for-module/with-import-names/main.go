@@ -0,0 +1,16 @@
+package main
+
+import (
+	"math/rand/v2"
+
+	"github.com/fake/ext/v2"
+	"github.com/fake/fake/lib/v2"
+	"github.com/fake/fake/util"
+)
+
+func main() {
+	_ = rand.Int()
+	extension.Fn()
+	lib.Fn()
+	helpers.Fn()
+}
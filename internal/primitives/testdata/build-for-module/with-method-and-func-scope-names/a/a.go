@@ -0,0 +1,13 @@
+package a
+
+type T struct{}
+
+type M struct{}
+
+// the variable declared by the method M is qualified like the method x of M
+func (T) M() {
+	var x int
+	_ = x
+}
+
+func (M) x() {}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/fake/fake/a"
+
+func main() {
+	a.T{}.M()
+}
@@ -0,0 +1,9 @@
+package main
+
+import "github.com/fake/fake/thing"
+
+func main() {
+	thing.NewThing().Method()
+	_ = thing.Default.Name
+	_ = thing.Default.ID
+}
@@ -0,0 +1,3 @@
+package thing
+
+func (t *Thing) Method() {}
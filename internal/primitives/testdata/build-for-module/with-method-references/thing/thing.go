@@ -0,0 +1,16 @@
+package thing
+
+type Base struct {
+	ID int
+}
+
+type Thing struct {
+	Base
+	Name string
+}
+
+var Default = NewThing()
+
+func NewThing() *Thing {
+	return &Thing{}
+}
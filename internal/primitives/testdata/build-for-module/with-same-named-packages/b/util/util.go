@@ -0,0 +1,7 @@
+package util
+
+import "github.com/fake/fake/a/util"
+
+func B() {
+	util.A()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/fake/fake/b/util"
+
+func main() {
+	util.B()
+}
@@ -0,0 +1,7 @@
+package main
+
+import "github.com/fake/fake/a/util"
+
+func main() {
+	util.A()
+}
@@ -0,0 +1,33 @@
+package main
+
+import "github.com/fake/fake/shapes"
+
+var circle = shapes.Circle{}
+
+func main() {
+	_ = circleString()
+	_ = squareName()
+}
+
+func circleString() string {
+	return circle.String()
+}
+
+func squareName() string {
+	return square.Name
+}
+
+func literal() string {
+	return (&shapes.Square{}).String()
+}
+
+func local() string {
+	c := &shapes.Circle{}
+	return c.String()
+}
+
+func parameter(s *shapes.Square) string {
+	return s.String()
+}
+
+var square *shapes.Square
@@ -0,0 +1,9 @@
+package shapes
+
+type Circle struct {
+	Name string
+}
+
+func (c Circle) String() string {
+	return c.Name
+}
@@ -0,0 +1,9 @@
+package shapes
+
+type Square struct {
+	Name string
+}
+
+func (s *Square) String() string {
+	return s.Name
+}
@@ -0,0 +1,5 @@
+package foo
+
+func Fn() string {
+	return "foo"
+}
@@ -0,0 +1,13 @@
+package foo
+
+import (
+	"testing"
+
+	"github.com/fake/fake/helpers"
+)
+
+func TestFn(t *testing.T) {
+	if Fn() != helpers.Expected() {
+		t.Fail()
+	}
+}
@@ -0,0 +1,14 @@
+package foo_test
+
+import (
+	"testing"
+
+	"github.com/fake/fake/foo"
+	"github.com/fake/fake/helpers"
+)
+
+func TestFn_External(t *testing.T) {
+	if foo.Fn() != helpers.Expected() {
+		t.Fail()
+	}
+}
@@ -0,0 +1,9 @@
+package helpers
+
+import (
+	"github.com/fake/fake/foo"
+)
+
+func Expected() string {
+	return foo.Fn()
+}
@@ -0,0 +1,11 @@
+package main
+
+import (
+	"log"
+
+	"github.com/fake/fake/foo"
+)
+
+func main() {
+	log.Println(foo.Fn())
+}
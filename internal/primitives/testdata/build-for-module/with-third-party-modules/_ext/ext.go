@@ -0,0 +1,3 @@
+package ext
+
+func Fn() {}
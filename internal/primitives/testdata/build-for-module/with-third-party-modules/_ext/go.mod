@@ -0,0 +1,3 @@
+module github.com/fake/ext
+
+go 1.24
@@ -0,0 +1,3 @@
+module github.com/fake/other
+
+go 1.24
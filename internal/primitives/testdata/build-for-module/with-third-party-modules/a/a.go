@@ -0,0 +1,13 @@
+package a
+
+import (
+	"fmt"
+
+	"github.com/fake/ext"
+	"github.com/fake/fake/b"
+)
+
+func A() {
+	fmt.Println(b.B())
+	ext.Fn()
+}
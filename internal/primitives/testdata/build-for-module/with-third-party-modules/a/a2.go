@@ -0,0 +1,7 @@
+package a
+
+import "github.com/fake/ext"
+
+func A2() {
+	ext.Fn()
+}
@@ -0,0 +1,12 @@
+package b
+
+import (
+	"strings"
+
+	"github.com/fake/other/sub"
+)
+
+func B() string {
+	sub.Fn()
+	return strings.ToUpper("b")
+}
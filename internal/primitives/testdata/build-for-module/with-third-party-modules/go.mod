@@ -0,0 +1,13 @@
+module github.com/fake/fake
+
+go 1.24
+
+require (
+	github.com/fake/ext v1.0.0
+	github.com/fake/other v1.2.0 // indirect
+)
+
+replace (
+	github.com/fake/ext => ./_ext
+	github.com/fake/other => ./_other
+)
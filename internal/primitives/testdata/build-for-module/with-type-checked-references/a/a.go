@@ -0,0 +1,13 @@
+package a
+
+import (
+	"github.com/fake/fake/b"
+)
+
+type A struct {
+	b.B
+}
+
+func New() A {
+	return A{}
+}
@@ -0,0 +1,11 @@
+package b
+
+import (
+	"log"
+)
+
+type B struct{}
+
+func (B) Method() {
+	log.Println("b.B.Method")
+}
@@ -0,0 +1,13 @@
+package c
+
+import (
+	"github.com/fake/fake/a"
+)
+
+func Fn() {
+	x := a.New()
+	x.Method()
+
+	b := struct{ Fn func() }{Fn: func() {}}
+	b.Fn()
+}
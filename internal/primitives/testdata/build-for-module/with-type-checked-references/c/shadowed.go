@@ -0,0 +1,11 @@
+package c
+
+import (
+	"github.com/fake/fake/b"
+)
+
+func Shadowed() {
+	var _ b.B
+	b := struct{ Fn func() }{Fn: func() {}}
+	b.Fn()
+}
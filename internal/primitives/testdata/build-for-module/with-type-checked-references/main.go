@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/fake/fake/c"
+)
+
+func main() {
+	c.Fn()
+}
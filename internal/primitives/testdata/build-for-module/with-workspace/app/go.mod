@@ -0,0 +1,3 @@
+module github.com/fake/app
+
+go 1.24
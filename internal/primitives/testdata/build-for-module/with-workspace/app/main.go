@@ -0,0 +1,9 @@
+package main
+
+import (
+	"github.com/fake/lib/x"
+)
+
+func main() {
+	x.Fn()
+}
@@ -0,0 +1,3 @@
+module github.com/fake/lib
+
+go 1.24
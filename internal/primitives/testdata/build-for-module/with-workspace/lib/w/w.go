@@ -0,0 +1,3 @@
+package w
+
+func Fn() {}
@@ -0,0 +1,9 @@
+package x
+
+import (
+	"github.com/fake/util/y"
+)
+
+func Fn() {
+	y.Fn()
+}
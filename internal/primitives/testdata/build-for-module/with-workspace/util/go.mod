@@ -0,0 +1,3 @@
+module github.com/fake/util
+
+go 1.24
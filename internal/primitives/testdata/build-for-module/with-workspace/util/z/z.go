@@ -0,0 +1,9 @@
+package z
+
+import (
+	"github.com/fake/lib/w"
+)
+
+func Fn() {
+	w.Fn()
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"fmt"
+	. "strings"
+)
+
+type IdOptions struct {
+	Prefix string
+}
+
+var IdTrimmed = TrimSpace(" id ")
+
+func IdFn(Local string) {
+	_ = IdOptions{Prefix: ToUpper(Local)}
+	_ = map[string]int{Repeat("a", 2): 1}
+	fmt.Println(IdTrimmed)
+}
@@ -3,13 +3,17 @@
 type Resolution string
 
 const (
+	DeclResolution    Resolution = "decl"
 	FileResolution    Resolution = "file"
 	PackageResolution Resolution = "package"
+	ModuleResolution  Resolution = "module"
 )
 
 var validResolutions = map[Resolution]bool{
+	DeclResolution:    true,
 	FileResolution:    true,
 	PackageResolution: true,
+	ModuleResolution:  true,
 }
 
 func IsValidResolution(resolution Resolution) bool {
@@ -0,0 +1,337 @@
+package serve
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/html"
+)
+
+// node is a file, a package or a module, it's identified by either its ID or
+// its name in requests
+type node struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	IsTest        bool   `json:"isTest"`
+	IsStub        bool   `json:"isStub"`
+	InImportCycle bool   `json:"inImportCycle"`
+}
+
+type pkgNode struct {
+	node
+	ImportPath string   `json:"importPath"`
+	Files      []string `json:"files"`
+}
+
+type hop struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Decls []string `json:"decls"`
+}
+
+type neighbor struct {
+	Node  node     `json:"node"`
+	Decls []string `json:"decls"`
+}
+
+type neighbors struct {
+	Node       node        `json:"node"`
+	Imports    []*neighbor `json:"imports"`
+	ImportedBy []*neighbor `json:"importedBy"`
+}
+
+type cycle struct {
+	Nodes []string `json:"nodes"`
+	Hops  []hop    `json:"hops"`
+}
+
+type cycleList struct {
+	Resolution string  `json:"resolution"`
+	Cycles     []cycle `json:"cycles"`
+	Truncated  bool    `json:"truncated"`
+}
+
+type path struct {
+	Nodes []node `json:"nodes"`
+	Hops  []hop  `json:"hops"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
+	b, version, err := s.current()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	output, err := html.Marshal(
+		b.modulePath,
+		b.pkgs,
+		html.WithPalette(s.options.palette),
+		html.WithLive(html.Live{
+			StatusURL:  "api/status",
+			RebuildURL: "api/rebuild",
+			Version:    version,
+		}),
+	)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(output)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.status())
+}
+
+func (s *Server) handleRebuild(w http.ResponseWriter, r *http.Request) {
+	if !isSameOrigin(r) {
+		writeError(w, http.StatusForbidden, errCrossOrigin)
+		return
+	}
+	code := http.StatusOK
+	if err := s.Rebuild(); err != nil {
+		code = http.StatusInternalServerError
+	}
+	writeJSON(w, code, s.status())
+}
+
+func (s *Server) handlePackages(w http.ResponseWriter, r *http.Request) {
+	b, _, err := s.current()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	fg := graph.Visible(b.pkgs, internal.FileResolution)
+	files := make(map[string][]string)
+	for _, n := range fg.Nodes() {
+		files[n.Package.UID()] = append(files[n.Package.UID()], cycles.NodeName(n))
+	}
+	pkgs := make([]*pkgNode, 0)
+	for _, n := range graph.Visible(b.pkgs, internal.PackageResolution).Nodes() {
+		pkgFiles := files[n.ID]
+		if pkgFiles == nil {
+			pkgFiles = make([]string, 0)
+		}
+		pkgs = append(pkgs, &pkgNode{
+			node:       buildNode(n),
+			ImportPath: n.Package.ImportPath(),
+			Files:      pkgFiles,
+		})
+	}
+	writeJSON(w, http.StatusOK, pkgs)
+}
+
+func (s *Server) handleNeighbors(w http.ResponseWriter, r *http.Request) {
+	b, _, err := s.current()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	g, err := requestedGraph(b, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	n, err := findNode(g, r.URL.Query().Get("node"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	resp := neighbors{
+		Node:       buildNode(n),
+		Imports:    make([]*neighbor, 0),
+		ImportedBy: make([]*neighbor, 0),
+	}
+	for _, succ := range g.Successors(n.ID) {
+		resp.Imports = append(resp.Imports, &neighbor{
+			Node:  buildNode(succ),
+			Decls: declNames(g.Edge(n.ID, succ.ID)),
+		})
+	}
+	for _, pred := range g.Predecessors(n.ID) {
+		resp.ImportedBy = append(resp.ImportedBy, &neighbor{
+			Node:  buildNode(pred),
+			Decls: declNames(g.Edge(pred.ID, n.ID)),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handleCycles(w http.ResponseWriter, r *http.Request) {
+	b, _, err := s.current()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	resolution, err := requestedResolution(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	found, truncated := cycles.FindAtMost(b.pkgs, resolution, s.options.maxCycles)
+	resp := cycleList{
+		Resolution: string(resolution),
+		Cycles:     make([]cycle, 0, len(found)),
+		Truncated:  truncated,
+	}
+	for _, c := range found {
+		hops := make([]hop, 0, len(c.Hops))
+		for _, h := range c.Hops {
+			decls := make([]string, 0, len(h.Decls))
+			for _, decl := range h.Decls {
+				decls = append(decls, cycles.DeclName(decl))
+			}
+			slices.Sort(decls)
+			hops = append(hops, hop{From: h.From, To: h.To, Decls: slices.Compact(decls)})
+		}
+		resp.Cycles = append(resp.Cycles, cycle{Nodes: c.Nodes(), Hops: hops})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) handlePath(w http.ResponseWriter, r *http.Request) {
+	b, _, err := s.current()
+	if err != nil {
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	}
+	g, err := requestedGraph(b, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	from, err := findNode(g, r.URL.Query().Get("from"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	to, err := findNode(g, r.URL.Query().Get("to"))
+	if err != nil {
+		writeError(w, http.StatusNotFound, err)
+		return
+	}
+	nodes := g.ShortestPath(from.ID, to.ID)
+	if nodes == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("no path from %s to %s", cycles.NodeName(from), cycles.NodeName(to)))
+		return
+	}
+	resp := path{
+		Nodes: make([]node, 0, len(nodes)),
+		Hops:  make([]hop, 0, len(nodes)-1),
+	}
+	for i, n := range nodes {
+		resp.Nodes = append(resp.Nodes, buildNode(n))
+		if i == 0 {
+			continue
+		}
+		resp.Hops = append(resp.Hops, hop{
+			From:  cycles.NodeName(nodes[i-1]),
+			To:    cycles.NodeName(n),
+			Decls: declNames(g.Edge(nodes[i-1].ID, n.ID)),
+		})
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// requestedGraph returns the graph of the build at the requested resolution
+// restricted to the nodes and edges rendered
+func requestedGraph(b *build, r *http.Request) (*graph.Graph, error) {
+	resolution, err := requestedResolution(r)
+	if err != nil {
+		return nil, err
+	}
+	return graph.Visible(b.pkgs, resolution), nil
+}
+
+// requestedResolution is the resolution query parameter, the package
+// resolution by default, declarations are only explored through the edges
+// between files, packages and modules
+func requestedResolution(r *http.Request) (internal.Resolution, error) {
+	resolution := internal.Resolution(r.URL.Query().Get("resolution"))
+	switch resolution {
+	case "":
+		return internal.PackageResolution, nil
+	case internal.FileResolution, internal.PackageResolution, internal.ModuleResolution:
+		return resolution, nil
+	}
+	return "", fmt.Errorf("unsupported resolution %q", resolution)
+}
+
+// findNode finds the node by its ID or, failing that, by its name
+func findNode(g *graph.Graph, idOrName string) (*graph.Node, error) {
+	if idOrName == "" {
+		return nil, fmt.Errorf("no node given")
+	}
+	if n := g.Node(idOrName); n != nil {
+		return n, nil
+	}
+	for _, n := range g.Nodes() {
+		if cycles.NodeName(n) == idOrName {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("node %q not found", idOrName)
+}
+
+func buildNode(n *graph.Node) node {
+	return node{
+		ID:            n.ID,
+		Name:          cycles.NodeName(n),
+		IsTest:        n.IsTest(),
+		IsStub:        n.IsStub(),
+		InImportCycle: n.InImportCycle(),
+	}
+}
+
+func declNames(e *graph.Edge) []string {
+	names := make([]string, 0, len(e.Decls))
+	for _, decl := range e.Decls {
+		names = append(names, cycles.DeclName(decl))
+	}
+	slices.Sort(names)
+	return slices.Compact(names)
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// isSameOrigin reports whether the request was sent by the report served or
+// by a client other than a browser, browsers tell the site a request was sent
+// from by Sec-Fetch-Site or, in older ones, Origin, so other sites can't make
+// a visitor's browser rebuild the module
+func isSameOrigin(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "":
+	case "same-origin", "none":
+		return true
+	default:
+		return false
+	}
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return originURL.Host == r.Host
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, errorResponse{Error: err.Error()})
+}
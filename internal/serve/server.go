@@ -0,0 +1,122 @@
+package serve
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+)
+
+// BuildFunc builds the packages of the served module, or workspace, and
+// returns its path
+type BuildFunc func() (string, []*internal.Package, error)
+
+// Server holds the last build of a module in memory and serves the web UI
+// and the JSON API exploring it, see Handler
+type Server struct {
+	build   BuildFunc
+	options options
+	mux     *http.ServeMux
+
+	// rebuilding serializes the rebuilds, mu guards the build served
+	rebuilding sync.Mutex
+	mu         sync.RWMutex
+	served     *build
+	version    int
+	err        error
+}
+
+// build is a successful build, the packages are only read once built
+type build struct {
+	modulePath string
+	pkgs       []*internal.Package
+}
+
+var (
+	errNotBuilt    = errors.New("module not built yet")
+	errCrossOrigin = errors.New("cross-origin request")
+)
+
+// New returns a server of the module built by build, nothing is served until
+// the module is rebuilt, see Rebuild
+func New(build BuildFunc, opts ...Option) *Server {
+	options := options{
+		palette: *color.DefaultPalette,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	s := &Server{
+		build:   build,
+		options: options,
+		mux:     http.NewServeMux(),
+	}
+	s.mux.HandleFunc("GET /{$}", s.handleIndex)
+	s.mux.HandleFunc("GET /api/status", s.handleStatus)
+	s.mux.HandleFunc("POST /api/rebuild", s.handleRebuild)
+	s.mux.HandleFunc("GET /api/packages", s.handlePackages)
+	s.mux.HandleFunc("GET /api/neighbors", s.handleNeighbors)
+	s.mux.HandleFunc("GET /api/cycles", s.handleCycles)
+	s.mux.HandleFunc("GET /api/path", s.handlePath)
+	return s
+}
+
+// Handler serves the web UI at / and the JSON API below /api/
+func (s *Server) Handler() http.Handler {
+	return s.mux
+}
+
+// Rebuild builds the module again and serves the new build, the previous
+// build is kept and the error is reported by the status when it fails.
+// Concurrent rebuilds run one after the other.
+func (s *Server) Rebuild() error {
+	s.rebuilding.Lock()
+	defer s.rebuilding.Unlock()
+
+	modulePath, pkgs, err := s.build()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+	if err != nil {
+		return err
+	}
+	s.served = &build{modulePath: modulePath, pkgs: pkgs}
+	s.version++
+	return nil
+}
+
+// current returns the build served along with its version
+func (s *Server) current() (*build, int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.served == nil {
+		if s.err != nil {
+			return nil, 0, s.err
+		}
+		return nil, 0, errNotBuilt
+	}
+	return s.served, s.version, nil
+}
+
+type status struct {
+	Module  string `json:"module"`
+	Version int    `json:"version"`
+	Error   string `json:"error,omitempty"`
+}
+
+func (s *Server) status() status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	st := status{Version: s.version}
+	if s.served != nil {
+		st.Module = s.served.modulePath
+	}
+	if s.err != nil {
+		st.Error = s.err.Error()
+	}
+	return st
+}
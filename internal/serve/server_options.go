@@ -0,0 +1,37 @@
+package serve
+
+import (
+	"github.com/samlitowitz/godepvis/internal/color"
+)
+
+type options struct {
+	palette   color.Palette
+	maxCycles int
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type paletteOption color.Palette
+
+func (opt paletteOption) apply(opts *options) {
+	opts.palette = color.Palette(opt)
+}
+
+// WithPalette sets the palette of the web UI
+func WithPalette(palette color.Palette) Option {
+	return paletteOption(palette)
+}
+
+type maxCyclesOption int
+
+func (opt maxCyclesOption) apply(opts *options) {
+	opts.maxCycles = int(opt)
+}
+
+// WithMaxCycles sets the number of import cycles listed at most, 0 lists
+// every one
+func WithMaxCycles(maxCycles int) Option {
+	return maxCyclesOption(maxCycles)
+}
@@ -0,0 +1,302 @@
+package serve_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+	"github.com/samlitowitz/godepvis/internal/serve"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+const modulePath = "github.com/fake/fake"
+
+type node struct {
+	Name string `json:"name"`
+}
+
+type hop struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Decls []string `json:"decls"`
+}
+
+func TestServer_API(t *testing.T) {
+	srv := httptest.NewServer(newServer(t, "transitive-circular-dependency").Handler())
+	defer srv.Close()
+
+	t.Run("packages", func(t *testing.T) {
+		var pkgs []struct {
+			node
+			Files []string `json:"files"`
+		}
+		get(t, srv, "/api/packages", http.StatusOK, &pkgs)
+		actual := make(map[string][]string)
+		for _, pkg := range pkgs {
+			actual[pkg.Name] = pkg.Files
+		}
+		expected := map[string][]string{
+			"main": {"main.go"},
+			"a":    {"a/a.go"},
+			"b":    {"b/b.go"},
+			"c":    {"c/c.go"},
+		}
+		if diff := cmp.Diff(expected, actual); diff != "" {
+			t.Error(test.Mismatch("expected packages: ", diff))
+		}
+	})
+
+	t.Run("neighbors", func(t *testing.T) {
+		var neighbors struct {
+			Imports []struct {
+				Node  node     `json:"node"`
+				Decls []string `json:"decls"`
+			} `json:"imports"`
+			ImportedBy []struct {
+				Node node `json:"node"`
+			} `json:"importedBy"`
+		}
+		get(t, srv, "/api/neighbors?resolution=file&node=a/a.go", http.StatusOK, &neighbors)
+		if len(neighbors.Imports) != 1 || neighbors.Imports[0].Node.Name != "c/c.go" {
+			t.Errorf("expected a/a.go to import c/c.go, got %+v", neighbors.Imports)
+		}
+		if diff := cmp.Diff([]string{"c.Fn"}, neighbors.Imports[0].Decls); diff != "" {
+			t.Error(test.Mismatch("expected declarations: ", diff))
+		}
+		var importedBy []string
+		for _, n := range neighbors.ImportedBy {
+			importedBy = append(importedBy, n.Node.Name)
+		}
+		if diff := cmp.Diff([]string{"main.go", "b/b.go"}, importedBy); diff != "" {
+			t.Error(test.Mismatch("expected a/a.go to be imported by: ", diff))
+		}
+	})
+
+	t.Run("cycles", func(t *testing.T) {
+		var list struct {
+			Cycles []struct {
+				Nodes []string `json:"nodes"`
+			} `json:"cycles"`
+		}
+		get(t, srv, "/api/cycles?resolution=package", http.StatusOK, &list)
+		if len(list.Cycles) != 1 {
+			t.Fatalf("expected a single import cycle, got %+v", list.Cycles)
+		}
+		if diff := cmp.Diff([]string{"a", "c", "b"}, list.Cycles[0].Nodes); diff != "" {
+			t.Error(test.Mismatch("expected import cycle: ", diff))
+		}
+	})
+
+	t.Run("path", func(t *testing.T) {
+		var p struct {
+			Hops []hop `json:"hops"`
+		}
+		get(t, srv, "/api/path?from=main&to=b", http.StatusOK, &p)
+		expected := []hop{
+			{From: "main", To: "a", Decls: []string{"a.Fn"}},
+			{From: "a", To: "c", Decls: []string{"c.Fn"}},
+			{From: "c", To: "b", Decls: []string{"b.Fn"}},
+		}
+		if diff := cmp.Diff(expected, p.Hops); diff != "" {
+			t.Error(test.Mismatch("expected path: ", diff))
+		}
+	})
+
+	errorCases := map[string]struct {
+		url          string
+		expectedCode int
+	}{
+		"no path":             {url: "/api/path?from=b&to=main", expectedCode: http.StatusNotFound},
+		"unknown node":        {url: "/api/neighbors?node=d", expectedCode: http.StatusNotFound},
+		"missing node":        {url: "/api/neighbors", expectedCode: http.StatusNotFound},
+		"unknown resolution":  {url: "/api/cycles?resolution=decl", expectedCode: http.StatusBadRequest},
+		"rebuild is a post":   {url: "/api/rebuild", expectedCode: http.StatusMethodNotAllowed},
+		"only the index page": {url: "/index.html", expectedCode: http.StatusNotFound},
+	}
+	for desc, testCase := range errorCases {
+		resp, err := http.Get(srv.URL + testCase.url)
+		if err != nil {
+			t.Fatal(desc, ": GET: ", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != testCase.expectedCode {
+			t.Errorf("%s: expected status %d, got %d", desc, testCase.expectedCode, resp.StatusCode)
+		}
+	}
+}
+
+func TestServer_Index(t *testing.T) {
+	srv := httptest.NewServer(newServer(t, "transitive-circular-dependency").Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/")
+	if err != nil {
+		t.Fatal("GET: ", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	if contentType := resp.Header.Get("Content-Type"); !strings.HasPrefix(contentType, "text/html") {
+		t.Errorf("expected an html page, got %s", contentType)
+	}
+}
+
+func TestServer_Rebuild(t *testing.T) {
+	dir, err := filepath.Abs(filepath.Join("..", "primitives", "testdata", "build-for-module", "transitive-circular-dependency"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buildErr error
+	s := serve.New(func() (string, []*internal.Package, error) {
+		if buildErr != nil {
+			return "", nil, buildErr
+		}
+		pkgs, err := primitives.BuildForModule(modulePath, dir)
+		return modulePath, pkgs, err
+	})
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	type status struct {
+		Module  string `json:"module"`
+		Version int    `json:"version"`
+		Error   string `json:"error"`
+	}
+	var actual status
+
+	// nothing is served before the first build
+	get(t, srv, "/api/status", http.StatusOK, &actual)
+	if diff := cmp.Diff(status{}, actual); diff != "" {
+		t.Error(test.Mismatch("expected status before building: ", diff))
+	}
+	get(t, srv, "/api/packages", http.StatusServiceUnavailable, nil)
+
+	post(t, srv, "/api/rebuild", http.StatusOK, &actual)
+	if diff := cmp.Diff(status{Module: modulePath, Version: 1}, actual); diff != "" {
+		t.Error(test.Mismatch("expected status after building: ", diff))
+	}
+
+	// the previous build is kept when rebuilding fails
+	buildErr = errors.New("expected 'package', found 'EOF'")
+	post(t, srv, "/api/rebuild", http.StatusInternalServerError, &actual)
+	if diff := cmp.Diff(status{Module: modulePath, Version: 1, Error: buildErr.Error()}, actual); diff != "" {
+		t.Error(test.Mismatch("expected status after failing to rebuild: ", diff))
+	}
+	get(t, srv, "/api/packages", http.StatusOK, nil)
+
+	buildErr = nil
+	if err := s.Rebuild(); err != nil {
+		t.Fatal("Rebuild: ", err)
+	}
+	actual = status{}
+	get(t, srv, "/api/status", http.StatusOK, &actual)
+	if diff := cmp.Diff(status{Module: modulePath, Version: 2}, actual); diff != "" {
+		t.Error(test.Mismatch("expected status after rebuilding: ", diff))
+	}
+}
+
+func TestServer_RebuildCrossOrigin(t *testing.T) {
+	s := newServer(t, "transitive-circular-dependency")
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	testCases := map[string]struct {
+		header       http.Header
+		expectedCode int
+	}{
+		"no browser": {
+			expectedCode: http.StatusOK,
+		},
+		"same origin": {
+			header:       http.Header{"Origin": {srv.URL}},
+			expectedCode: http.StatusOK,
+		},
+		"same site fetch": {
+			header:       http.Header{"Sec-Fetch-Site": {"same-origin"}, "Origin": {srv.URL}},
+			expectedCode: http.StatusOK,
+		},
+		"cross origin": {
+			header:       http.Header{"Origin": {"http://example.com"}},
+			expectedCode: http.StatusForbidden,
+		},
+		"cross site fetch": {
+			header:       http.Header{"Sec-Fetch-Site": {"cross-site"}, "Origin": {"http://example.com"}},
+			expectedCode: http.StatusForbidden,
+		},
+		"same site fetch from another origin": {
+			header:       http.Header{"Sec-Fetch-Site": {"same-site"}, "Origin": {"http://other.localhost"}},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for desc, testCase := range testCases {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/rebuild", nil)
+		if err != nil {
+			t.Fatal(desc, ": ", err)
+		}
+		req.Header = testCase.header
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(desc, ": POST /api/rebuild: ", err)
+		}
+		decode(t, resp, desc, testCase.expectedCode, nil)
+	}
+}
+
+// newServer serves the module of the primitives package's build-for-module
+// test data, built once
+func newServer(t *testing.T, testData string) *serve.Server {
+	t.Helper()
+	dir, err := filepath.Abs(filepath.Join("..", "primitives", "testdata", "build-for-module", testData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := serve.New(func() (string, []*internal.Package, error) {
+		pkgs, err := primitives.BuildForModule(modulePath, dir)
+		return modulePath, pkgs, err
+	})
+	if err := s.Rebuild(); err != nil {
+		t.Fatal("Rebuild: ", err)
+	}
+	return s
+}
+
+func get(t *testing.T, srv *httptest.Server, url string, expectedCode int, v any) {
+	t.Helper()
+	resp, err := http.Get(srv.URL + url)
+	if err != nil {
+		t.Fatal("GET ", url, ": ", err)
+	}
+	decode(t, resp, url, expectedCode, v)
+}
+
+func post(t *testing.T, srv *httptest.Server, url string, expectedCode int, v any) {
+	t.Helper()
+	resp, err := http.Post(srv.URL+url, "", nil)
+	if err != nil {
+		t.Fatal("POST ", url, ": ", err)
+	}
+	decode(t, resp, url, expectedCode, v)
+}
+
+func decode(t *testing.T, resp *http.Response, url string, expectedCode int, v any) {
+	t.Helper()
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedCode {
+		t.Fatalf("%s: expected status %d, got %d", url, expectedCode, resp.StatusCode)
+	}
+	if v == nil {
+		return
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(url, ": decode: ", err)
+	}
+}
@@ -0,0 +1,280 @@
+package svg
+
+import (
+	"math"
+	"unicode/utf8"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/layout"
+)
+
+const (
+	// charWidth is a generous estimate of the mean width of a character of
+	// the sans-serif font, the renderer's font metrics aren't known
+	charWidth          = 8
+	nodePadding        = 12
+	nodeHeight         = 36
+	clusterPadding     = 12
+	clusterLabelHeight = 24
+)
+
+var spacing = layout.Spacing{
+	Node:  24,
+	Layer: 48,
+}
+
+// box is a node or a cluster, its center and size
+type box struct {
+	center     layout.Point
+	size       layout.Size
+	label      string
+	text       string
+	background string
+	stub       bool
+}
+
+// line is an edge from the boundary of its source to the boundary of its
+// target
+type line struct {
+	points      []layout.Point
+	color       string
+	highlighted bool
+}
+
+type drawing struct {
+	width    float64
+	height   float64
+	clusters []box
+	nodes    []box
+	edges    []line
+}
+
+func textWidth(text string) float64 {
+	return float64(utf8.RuneCountInString(text) * charWidth)
+}
+
+func nodeSize(label string) layout.Size {
+	return layout.Size{
+		Width:  textWidth(label) + 2*nodePadding,
+		Height: nodeHeight,
+	}
+}
+
+func drawPackageResolution(palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, g *graph.Graph) *drawing {
+	nodes := g.Nodes()
+	index := make(map[string]int, len(nodes))
+	sizes := make([]layout.Size, len(nodes))
+	for i, n := range nodes {
+		index[n.ID] = i
+		sizes[i] = nodeSize(n.Label())
+	}
+	edges := g.Edges()
+	layoutEdges := make([]layout.Edge, len(edges))
+	for i, e := range edges {
+		layoutEdges[i] = layout.Edge{From: index[e.From.ID], To: index[e.To.ID]}
+	}
+
+	l := layout.Layered(sizes, layoutEdges, spacing)
+
+	d := &drawing{width: l.Width, height: l.Height}
+	for i, n := range nodes {
+		half := nodeHalf(palette, n)
+		text := half.PackageName
+		background := half.PackageBackground
+		d.nodes = append(d.nodes, box{
+			center:     l.Nodes[i],
+			size:       sizes[i],
+			label:      n.Label(),
+			text:       text.Hex(),
+			background: background.Hex(),
+			stub:       n.IsStub(),
+		})
+	}
+	for i, e := range edges {
+		d.edges = append(d.edges, newLine(
+			palette,
+			highlightedEdges,
+			e,
+			d.nodes[index[e.From.ID]],
+			d.nodes[index[e.To.ID]],
+			l.Edges[i],
+		))
+	}
+	return d
+}
+
+// nodeHalf is the half-palette of a package or module node
+func nodeHalf(palette *color.Palette, n *graph.Node) *color.HalfPalette {
+	if n.IsStub() {
+		return palette.Stub(n.IsStdlib())
+	}
+	return palette.Half(n.IsTest(), n.InImportCycle())
+}
+
+// drawFileResolution lays out the files of each package as a cluster, then
+// lays out the clusters. Edges between packages are routed along the edges
+// between their clusters. Stub nodes are laid out like clusters of their own
+// without a box.
+func drawFileResolution(palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, g *graph.Graph) *drawing {
+	type member struct {
+		cluster int
+		index   int
+	}
+
+	nodes := g.Nodes()
+	// pkgs holds nil for the clusters of stub nodes
+	var pkgs []*internal.Package
+	clusterIndex := make(map[string]int)
+	var clusterSizes [][]layout.Size
+	members := make(map[string]member, len(nodes))
+	for _, n := range nodes {
+		key, pkg := n.ID, (*internal.Package)(nil)
+		if !n.IsStub() {
+			key, pkg = n.Package.UID(), n.Package
+		}
+		c, ok := clusterIndex[key]
+		if !ok {
+			c = len(pkgs)
+			clusterIndex[key] = c
+			pkgs = append(pkgs, pkg)
+			clusterSizes = append(clusterSizes, nil)
+		}
+		members[n.ID] = member{cluster: c, index: len(clusterSizes[c])}
+		clusterSizes[c] = append(clusterSizes[c], nodeSize(n.Label()))
+	}
+
+	// each edge is either laid out within a cluster or between clusters
+	edges := g.Edges()
+	innerEdges := make([][]layout.Edge, len(pkgs))
+	var outerEdges []layout.Edge
+	edgeIndex := make([]int, len(edges))
+	for i, e := range edges {
+		from, to := members[e.From.ID], members[e.To.ID]
+		if from.cluster == to.cluster {
+			edgeIndex[i] = len(innerEdges[from.cluster])
+			innerEdges[from.cluster] = append(innerEdges[from.cluster], layout.Edge{From: from.index, To: to.index})
+			continue
+		}
+		edgeIndex[i] = len(outerEdges)
+		outerEdges = append(outerEdges, layout.Edge{From: from.cluster, To: to.cluster})
+	}
+
+	inner := make([]*layout.Layout, len(pkgs))
+	outerSizes := make([]layout.Size, len(pkgs))
+	for c, pkg := range pkgs {
+		inner[c] = layout.Layered(clusterSizes[c], innerEdges[c], spacing)
+		if pkg == nil {
+			outerSizes[c] = layout.Size{Width: inner[c].Width, Height: inner[c].Height}
+			continue
+		}
+		outerSizes[c] = layout.Size{
+			Width:  max(inner[c].Width, textWidth(pkg.ModuleRelativePath())) + 2*clusterPadding,
+			Height: inner[c].Height + clusterLabelHeight + 2*clusterPadding,
+		}
+	}
+	outer := layout.Layered(outerSizes, outerEdges, spacing)
+
+	d := &drawing{width: outer.Width, height: outer.Height}
+	offsets := make([]layout.Point, len(pkgs))
+	for c, pkg := range pkgs {
+		if pkg == nil {
+			offsets[c] = layout.Point{
+				X: outer.Nodes[c].X - inner[c].Width/2,
+				Y: outer.Nodes[c].Y - inner[c].Height/2,
+			}
+			continue
+		}
+		half := palette.Half(pkg.IsTest, pkg.InImportCycle)
+		text := half.PackageName
+		background := half.PackageBackground
+		d.clusters = append(d.clusters, box{
+			center:     outer.Nodes[c],
+			size:       outerSizes[c],
+			label:      pkg.ModuleRelativePath(),
+			text:       text.Hex(),
+			background: background.Hex(),
+		})
+		// the files are centered below the label
+		offsets[c] = layout.Point{
+			X: outer.Nodes[c].X - inner[c].Width/2,
+			Y: outer.Nodes[c].Y - outerSizes[c].Height/2 + clusterLabelHeight + clusterPadding,
+		}
+	}
+
+	boxes := make(map[string]box, len(nodes))
+	for _, n := range nodes {
+		m := members[n.ID]
+		half := palette.Half(n.IsTest(), n.InImportCycle())
+		text := half.FileName
+		background := half.FileBackground
+		if n.IsStub() {
+			half = nodeHalf(palette, n)
+			text = half.PackageName
+			background = half.PackageBackground
+		}
+		b := box{
+			center:     translate(inner[m.cluster].Nodes[m.index], offsets[m.cluster]),
+			size:       clusterSizes[m.cluster][m.index],
+			label:      n.Label(),
+			text:       text.Hex(),
+			background: background.Hex(),
+			stub:       n.IsStub(),
+		}
+		boxes[n.ID] = b
+		d.nodes = append(d.nodes, b)
+	}
+	for i, e := range edges {
+		from, to := members[e.From.ID], members[e.To.ID]
+		var bends []layout.Point
+		if from.cluster == to.cluster {
+			for _, p := range inner[from.cluster].Edges[edgeIndex[i]] {
+				bends = append(bends, translate(p, offsets[from.cluster]))
+			}
+		} else {
+			bends = outer.Edges[edgeIndex[i]]
+		}
+		d.edges = append(d.edges, newLine(palette, highlightedEdges, e, boxes[e.From.ID], boxes[e.To.ID], bends))
+	}
+	return d
+}
+
+func translate(p, offset layout.Point) layout.Point {
+	return layout.Point{X: p.X + offset.X, Y: p.Y + offset.Y}
+}
+
+func newLine(palette *color.Palette, highlightedEdges map[graph.EdgeID]bool, e *graph.Edge, from, to box, bends []layout.Point) line {
+	first, last := to.center, from.center
+	if len(bends) > 0 {
+		first, last = bends[0], bends[len(bends)-1]
+	}
+	points := make([]layout.Point, 0, len(bends)+2)
+	points = append(points, clip(from, first))
+	points = append(points, bends...)
+	points = append(points, clip(to, last))
+
+	half := palette.Half(e.From.IsTest(), e.InImportCycle())
+	if e.To.IsStub() {
+		half = palette.Stub(e.To.IsStdlib())
+	}
+	return line{
+		points:      points,
+		color:       half.ImportArrow.Hex(),
+		highlighted: highlightedEdges[e.ID()],
+	}
+}
+
+// clip returns the point where the segment from the center of the box
+// towards p leaves the box
+func clip(b box, p layout.Point) layout.Point {
+	dx, dy := p.X-b.center.X, p.Y-b.center.Y
+	t := 1.0
+	if dx != 0 {
+		t = min(t, b.size.Width/2/math.Abs(dx))
+	}
+	if dy != 0 {
+		t = min(t, b.size.Height/2/math.Abs(dy))
+	}
+	return layout.Point{X: b.center.X + t*dx, Y: b.center.Y + t*dy}
+}
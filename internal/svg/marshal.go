@@ -0,0 +1,200 @@
+package svg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+	"github.com/samlitowitz/godepvis/internal/layout"
+)
+
+const (
+	fontSize    = 14
+	margin      = 8
+	titleHeight = 32
+	arrowLength = 10
+	arrowWidth  = 7
+
+	highlightedEdgeStyle = ` stroke-width="2" stroke-dasharray="5 2"`
+	stubNodeStyle        = ` stroke-dasharray="5 5"`
+)
+
+// Marshal lays out the dependency graph and renders it as an SVG image in
+// the style of the dot output, packages are clusters at the file resolution
+func Marshal(modulePath string, pkgs []*internal.Package, opts ...Option) ([]byte, error) {
+	options := options{
+		palette:    *color.DefaultPalette,
+		resolution: internal.FileResolution,
+	}
+	for _, opt := range opts {
+		opt.apply(&options)
+	}
+
+	g := graph.Visible(
+		pkgs,
+		options.resolution,
+		graph.WithStdlib(options.stdlib),
+		graph.WithStubs(options.stubs),
+		graph.WithCollapsedStubs(options.collapseStubs),
+		graph.WithStubPrefixes(options.stubPrefixes),
+	)
+	d := &drawing{}
+	switch options.resolution {
+	case internal.FileResolution:
+		d = drawFileResolution(&options.palette, options.highlightedEdges, g)
+	case internal.PackageResolution, internal.ModuleResolution:
+		d = drawPackageResolution(&options.palette, options.highlightedEdges, g)
+	}
+
+	buf := &bytes.Buffer{}
+	writeHeader(buf, modulePath, d)
+	for _, cluster := range d.clusters {
+		writeCluster(buf, cluster)
+	}
+	for _, edge := range d.edges {
+		writeLine(buf, edge)
+	}
+	for _, node := range d.nodes {
+		writeNode(buf, node)
+	}
+	writeFooter(buf)
+
+	return buf.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, modulePath string, d *drawing) {
+	width := max(d.width, textWidth(modulePath)) + 2*margin
+	height := d.height + titleHeight + 2*margin
+	_, err := fmt.Fprintf(
+		buf,
+		`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" width="%s" height="%s" viewBox="0 0 %s %s" font-family="sans-serif" font-size="%d">
+	<title>%s</title>
+	<rect width="100%%" height="100%%" fill="#ffffff"/>
+	<text x="%s" y="%s" text-anchor="middle" dominant-baseline="central">%s</text>
+	<g transform="translate(%s %s)">
+`,
+		num(width), num(height), num(width), num(height), fontSize,
+		escape(modulePath),
+		num(width/2), num(margin+titleHeight/2), escape(modulePath),
+		num(margin+(width-2*margin-d.width)/2), num(margin+titleHeight),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// writeCluster labels the cluster in its top left corner, where edges
+// entering the cluster are least likely to cross the label
+func writeCluster(buf *bytes.Buffer, b box) {
+	left, top := b.center.X-b.size.Width/2, b.center.Y-b.size.Height/2
+	_, err := fmt.Fprintf(
+		buf,
+		`		<g class="cluster">
+			<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="#000000"/>
+			<text x="%s" y="%s" dominant-baseline="central" fill="%s">%s</text>
+		</g>
+`,
+		num(left), num(top), num(b.size.Width), num(b.size.Height), b.background,
+		num(left+clusterPadding), num(top+clusterPadding+clusterLabelHeight/2), b.text, escape(b.label),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// writeNode draws stub nodes with a dashed border like the dot output
+func writeNode(buf *bytes.Buffer, b box) {
+	style := ""
+	if b.stub {
+		style = stubNodeStyle
+	}
+	_, err := fmt.Fprintf(
+		buf,
+		`		<g class="node">
+			<rect x="%s" y="%s" width="%s" height="%s" fill="%s" stroke="#000000"%s/>
+			<text x="%s" y="%s" text-anchor="middle" dominant-baseline="central" fill="%s">%s</text>
+		</g>
+`,
+		num(b.center.X-b.size.Width/2), num(b.center.Y-b.size.Height/2), num(b.size.Width), num(b.size.Height), b.background, style,
+		num(b.center.X), num(b.center.Y), b.text, escape(b.label),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// writeLine draws the edge ending in a filled arrowhead, the line stops at
+// the base of the arrowhead so a bold line doesn't blunt its tip
+func writeLine(buf *bytes.Buffer, l line) {
+	tip := l.points[len(l.points)-1]
+	prev := l.points[len(l.points)-2]
+	dx, dy := tip.X-prev.X, tip.Y-prev.Y
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		length = 1
+	}
+	dx, dy = dx/length, dy/length
+	base := layout.Point{X: tip.X - dx*arrowLength, Y: tip.Y - dy*arrowLength}
+
+	path := make([]string, 0, len(l.points))
+	for i, p := range l.points[:len(l.points)-1] {
+		cmd := "L"
+		if i == 0 {
+			cmd = "M"
+		}
+		path = append(path, cmd+num(p.X)+" "+num(p.Y))
+	}
+	path = append(path, "L"+num(base.X)+" "+num(base.Y))
+
+	style := ""
+	if l.highlighted {
+		style = highlightedEdgeStyle
+	}
+	_, err := fmt.Fprintf(
+		buf,
+		`		<g class="edge">
+			<path d="%s" fill="none" stroke="%s"%s/>
+			<polygon points="%s,%s %s,%s %s,%s" fill="%s" stroke="%s"/>
+		</g>
+`,
+		strings.Join(path, " "), l.color, style,
+		num(tip.X), num(tip.Y),
+		num(base.X-dy*arrowWidth/2), num(base.Y+dx*arrowWidth/2),
+		num(base.X+dy*arrowWidth/2), num(base.Y-dx*arrowWidth/2),
+		l.color, l.color,
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func writeFooter(buf *bytes.Buffer) {
+	buf.WriteString(`	</g>
+</svg>
+`,
+	)
+}
+
+// num formats the coordinate to two decimal places at most
+func num(f float64) string {
+	rounded := math.Round(f*100) / 100
+	if rounded == 0 {
+		// avoid -0
+		rounded = 0
+	}
+	return strconv.FormatFloat(rounded, 'f', -1, 64)
+}
+
+func escape(text string) string {
+	escaped := &strings.Builder{}
+	// writing to a strings.Builder never fails
+	_ = xml.EscapeText(escaped, []byte(text))
+	return escaped.String()
+}
@@ -0,0 +1,104 @@
+package svg
+
+import (
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/color"
+	"github.com/samlitowitz/godepvis/internal/graph"
+)
+
+type options struct {
+	resolution       internal.Resolution
+	palette          color.Palette
+	highlightedEdges map[graph.EdgeID]bool
+	stdlib           bool
+	stubs            bool
+	collapseStubs    bool
+	stubPrefixes     []string
+}
+
+type Option interface {
+	apply(*options)
+}
+
+type resolutionOption internal.Resolution
+
+func (opt resolutionOption) apply(opts *options) {
+	opts.resolution = internal.Resolution(opt)
+}
+
+func WithResolution(resolution internal.Resolution) Option {
+	return resolutionOption(resolution)
+}
+
+type paletteOption color.Palette
+
+func (opt paletteOption) apply(opts *options) {
+	opts.palette = color.Palette(opt)
+}
+
+func WithPalette(palette color.Palette) Option {
+	return paletteOption(palette)
+}
+
+type highlightedEdgesOption []graph.EdgeID
+
+func (opt highlightedEdgesOption) apply(opts *options) {
+	opts.highlightedEdges = make(map[graph.EdgeID]bool, len(opt))
+	for _, id := range opt {
+		opts.highlightedEdges[id] = true
+	}
+}
+
+// WithHighlightedEdges draws the edges, identified by package or file UIDs
+// depending on the resolution, dashed and bold, e.g. to show suggested cuts
+func WithHighlightedEdges(ids []graph.EdgeID) Option {
+	return highlightedEdgesOption(ids)
+}
+
+type stdlibOption bool
+
+func (opt stdlibOption) apply(opts *options) {
+	opts.stdlib = bool(opt)
+}
+
+// WithStdlib shows the standard library module at the module resolution
+func WithStdlib(stdlib bool) Option {
+	return stdlibOption(stdlib)
+}
+
+type stubsOption bool
+
+func (opt stubsOption) apply(opts *options) {
+	opts.stubs = bool(opt)
+}
+
+// WithStubs draws packages of other modules and of the standard library as
+// nodes at the file and package resolutions
+func WithStubs(stubs bool) Option {
+	return stubsOption(stubs)
+}
+
+type collapsedStubsOption bool
+
+func (opt collapsedStubsOption) apply(opts *options) {
+	opts.collapseStubs = bool(opt)
+}
+
+// WithCollapsedStubs draws a node per module rather than per package of
+// other modules and of the standard library, see WithStubs
+func WithCollapsedStubs(collapse bool) Option {
+	return collapsedStubsOption(collapse)
+}
+
+type stubPrefixesOption []string
+
+func (opt stubPrefixesOption) apply(opts *options) {
+	opts.stubPrefixes = []string(opt)
+}
+
+// WithStubPrefixes only draws the packages of other modules and of the
+// standard library whose import path starts with one of the prefixes, see
+// WithStubs
+func WithStubPrefixes(prefixes []string) Option {
+	return stubPrefixesOption(prefixes)
+}
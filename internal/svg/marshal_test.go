@@ -0,0 +1,57 @@
+package svg_test
+
+import (
+	"testing"
+
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/svg"
+	"github.com/samlitowitz/godepvis/internal/test"
+)
+
+func TestMarshal(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"transitive-circular-dependency at the file resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.FileResolution,
+				Golden:     "transitive-circular-dependency.file.svg",
+			},
+			"transitive-circular-dependency at the package resolution": {
+				Dir:        "transitive-circular-dependency",
+				Resolution: internal.PackageResolution,
+				Golden:     "transitive-circular-dependency.package.svg",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return svg.Marshal(modulePath, pkgs, svg.WithResolution(resolution))
+		},
+	)
+}
+
+func TestMarshal_WithStubs(t *testing.T) {
+	test.MarshalGolden(
+		t,
+		map[string]test.GoldenCase{
+			"with-third-party-modules at the package resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.PackageResolution,
+				Golden:     "with-third-party-modules.package.stubs.svg",
+			},
+			"with-third-party-modules at the file resolution": {
+				Dir:        "with-third-party-modules",
+				Resolution: internal.FileResolution,
+				Golden:     "with-third-party-modules.file.stubs.svg",
+			},
+		},
+		func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error) {
+			return svg.Marshal(
+				modulePath,
+				pkgs,
+				svg.WithResolution(resolution),
+				svg.WithStubs(true),
+				svg.WithCollapsedStubs(resolution == internal.FileResolution),
+			)
+		},
+	)
+}
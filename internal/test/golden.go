@@ -0,0 +1,139 @@
+package test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/modfile"
+	"github.com/samlitowitz/godepvis/internal/primitives"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// GoldenCase is a module, or a workspace, of the primitives package's
+// build-for-module test data marshaled at a resolution and the golden file, in the testdata
+// directory of the package under test, the output is compared to
+type GoldenCase struct {
+	Dir        string
+	Resolution internal.Resolution
+	Golden     string
+	// BuildOptions select the files of the module which are built
+	BuildOptions []primitives.Option
+}
+
+// MarshalFunc marshals the packages of the module at the resolution
+type MarshalFunc func(modulePath string, pkgs []*internal.Package, resolution internal.Resolution) ([]byte, error)
+
+// MarshalGolden builds the module of each test case and compares its output
+// to the golden file, see Golden. The directory the module is copied to is
+// replaced by /module in the output.
+func MarshalGolden(t *testing.T, testCases map[string]GoldenCase, marshal MarshalFunc) {
+	t.Helper()
+	for desc, testCase := range testCases {
+		func() {
+			// REFURL: https://github.com/golang/go/blob/988b718f4130ab5b3ce5a5774e1a58e83c92a163/src/path/filepath/path_test.go#L600
+			// -- START -- //
+			if runtime.GOOS == "ios" {
+				restore := Chtmpdir(t)
+				defer restore()
+			}
+
+			tmpDir := t.TempDir()
+
+			origDir, err := os.Getwd()
+			if err != nil {
+				t.Fatal("finding working dir:", err)
+			}
+			if err = os.Chdir(tmpDir); err != nil {
+				t.Fatal("entering temp dir:", err)
+			}
+			defer os.Chdir(origDir)
+			// -- END -- //
+
+			err = os.CopyFS(tmpDir, os.DirFS(filepath.Join(origDir, "..", "primitives", "testdata", "build-for-module", testCase.Dir)))
+			if err != nil {
+				t.Fatal("copy test data:", err)
+			}
+
+			moduleDir := tmpDir
+
+			modulePath, pkgs := build(t, desc, testCase, moduleDir)
+			output, err := marshal(modulePath, pkgs, testCase.Resolution)
+			if err != nil {
+				t.Fatal(desc, ": Marshal: ", err)
+			}
+
+			// the module is copied to a different directory every run
+			output = []byte(strings.ReplaceAll(string(output), moduleDir, "/module"))
+			Golden(t, filepath.Join(origDir, "testdata", testCase.Golden), output)
+		}()
+	}
+}
+
+// build builds the module, or the workspace when the test data has a go.work
+// file, the workspace is named after the test data's directory
+func build(t *testing.T, desc string, testCase GoldenCase, moduleDir string) (string, []*internal.Package) {
+	t.Helper()
+	if _, err := os.Stat(filepath.Join(moduleDir, "go.work")); err == nil {
+		moduleDirs, err := modfile.GetWorkspaceModuleDirs(filepath.Join(moduleDir, "go.work"))
+		if err != nil {
+			t.Fatal(desc, ": failed to get workspace modules: ", err)
+		}
+		modules := make([]primitives.WorkspaceModule, 0, len(moduleDirs))
+		for _, dir := range moduleDirs {
+			modulePath, err := modfile.GetModulePath(filepath.Join(dir, "go.mod"))
+			if err != nil {
+				t.Fatal(desc, ": failed to get module path: ", err)
+			}
+			modules = append(modules, primitives.WorkspaceModule{Path: modulePath, Dir: dir})
+		}
+		pkgs, err := primitives.BuildForWorkspace(modules, testCase.BuildOptions...)
+		if err != nil {
+			t.Fatal(desc, ": BuildForWorkspace: ", err)
+		}
+		return testCase.Dir, pkgs
+	}
+
+	goModFile, err := modfile.FindGoModFile(moduleDir)
+	if err != nil {
+		t.Fatal(desc, ": failed to find go.mod: ", err)
+	}
+	modulePath, err := modfile.GetModulePath(goModFile)
+	if err != nil {
+		t.Fatal(desc, ": failed to get module path: ", err)
+	}
+
+	pkgs, err := primitives.BuildForModule(modulePath, moduleDir, testCase.BuildOptions...)
+	if err != nil {
+		t.Fatal(desc, ": BuildForModule: ", err)
+	}
+	return modulePath, pkgs
+}
+
+// Golden compares actual to the content of the golden file, the golden file
+// is rewritten with actual instead when run with go test -update
+func Golden(t *testing.T, goldenFile string, actual []byte) {
+	t.Helper()
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(goldenFile), 0755); err != nil {
+			t.Fatal("create golden dir:", err)
+		}
+		if err := os.WriteFile(goldenFile, actual, 0644); err != nil {
+			t.Fatal("write golden file:", err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(goldenFile)
+	if err != nil {
+		t.Fatal("read golden file:", err)
+	}
+	if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+		t.Error(goldenFile, Mismatch(": expected output: ", diff))
+	}
+}
@@ -0,0 +1,30 @@
+package watch
+
+import (
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/baseline"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+)
+
+// DiffCycles returns the package and file import cycles found after but not
+// before, and the ones found before but not after, regardless of which node
+// they start from
+func DiffCycles(before, after []cycles.Cycle) ([]cycles.Cycle, []cycles.Cycle) {
+	appeared := baseline.New(byResolution(before)).Unknown(after)
+	disappeared := baseline.New(byResolution(after)).Unknown(before)
+	return appeared, disappeared
+}
+
+// byResolution splits the cycles into the package and the file cycles
+func byResolution(found []cycles.Cycle) ([]cycles.Cycle, []cycles.Cycle) {
+	var pkgCycles, fileCycles []cycles.Cycle
+	for _, cycle := range found {
+		switch cycle.Resolution {
+		case internal.PackageResolution:
+			pkgCycles = append(pkgCycles, cycle)
+		case internal.FileResolution:
+			fileCycles = append(fileCycles, cycle)
+		}
+	}
+	return pkgCycles, fileCycles
+}
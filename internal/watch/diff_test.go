@@ -0,0 +1,96 @@
+package watch_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal"
+	"github.com/samlitowitz/godepvis/internal/cycles"
+	"github.com/samlitowitz/godepvis/internal/test"
+	"github.com/samlitowitz/godepvis/internal/watch"
+)
+
+func TestDiffCycles(t *testing.T) {
+	testCases := map[string]struct {
+		before              []cycles.Cycle
+		after               []cycles.Cycle
+		expectedAppeared    []string
+		expectedDisappeared []string
+	}{
+		"unchanged": {
+			before: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+			},
+			after: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+			},
+		},
+		"unchanged with different starting node": {
+			before: []cycles.Cycle{
+				buildCycle(internal.FileResolution, "a/a.go", "b/b.go", "c/c.go"),
+			},
+			after: []cycles.Cycle{
+				buildCycle(internal.FileResolution, "c/c.go", "a/a.go", "b/b.go"),
+			},
+		},
+		"appeared": {
+			before: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+			},
+			after: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+				buildCycle(internal.FileResolution, "a/a.go", "b/b.go"),
+			},
+			expectedAppeared: []string{"a/a.go -> b/b.go -> a/a.go"},
+		},
+		"disappeared": {
+			before: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+				buildCycle(internal.PackageResolution, "b", "c"),
+			},
+			after: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "b", "c"),
+			},
+			expectedDisappeared: []string{"a -> b -> a"},
+		},
+		"same nodes at another resolution": {
+			before: []cycles.Cycle{
+				buildCycle(internal.PackageResolution, "a", "b"),
+			},
+			after: []cycles.Cycle{
+				buildCycle(internal.FileResolution, "a", "b"),
+			},
+			expectedAppeared:    []string{"a -> b -> a"},
+			expectedDisappeared: []string{"a -> b -> a"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		appeared, disappeared := watch.DiffCycles(testCase.before, testCase.after)
+		if diff := cmp.Diff(testCase.expectedAppeared, cycleStrings(appeared)); diff != "" {
+			t.Error(desc, test.Mismatch(": expected appeared cycles: ", diff))
+		}
+		if diff := cmp.Diff(testCase.expectedDisappeared, cycleStrings(disappeared)); diff != "" {
+			t.Error(desc, test.Mismatch(": expected disappeared cycles: ", diff))
+		}
+	}
+}
+
+func buildCycle(resolution internal.Resolution, nodes ...string) cycles.Cycle {
+	cycle := cycles.Cycle{Resolution: resolution}
+	for i, node := range nodes {
+		cycle.Hops = append(cycle.Hops, cycles.Hop{
+			From: node,
+			To:   nodes[(i+1)%len(nodes)],
+		})
+	}
+	return cycle
+}
+
+func cycleStrings(found []cycles.Cycle) []string {
+	var strs []string
+	for _, cycle := range found {
+		strs = append(strs, cycle.String())
+	}
+	return strs
+}
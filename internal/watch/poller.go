@@ -0,0 +1,122 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Poller polls directories for changes to the files the module is built
+// from, the .go files and the go.mod and go.work files, skipping the
+// directories skipped by the build
+type Poller struct {
+	dirs     []string
+	interval time.Duration
+
+	files map[string]fileState
+}
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// NewPoller takes the state of the files below the directories changes are
+// reported against, see Wait
+func NewPoller(dirs []string, interval time.Duration) (*Poller, error) {
+	p := &Poller{
+		dirs:     dirs,
+		interval: interval,
+	}
+	files, err := p.scan()
+	if err != nil {
+		return nil, err
+	}
+	p.files = files
+	return p, nil
+}
+
+// Wait blocks until files were changed, added or removed since the poller
+// was created or Wait last returned, and returns their paths sorted. The
+// context's error is returned once it's done.
+func (p *Poller) Wait(ctx context.Context) ([]string, error) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+		files, err := p.scan()
+		if err != nil {
+			return nil, err
+		}
+		changed := changedFiles(p.files, files)
+		p.files = files
+		if len(changed) > 0 {
+			return changed, nil
+		}
+	}
+}
+
+func (p *Poller) scan() (map[string]fileState, error) {
+	files := make(map[string]fileState)
+	for _, dir := range p.dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			// removed while walking
+			if errors.Is(err, fs.ErrNotExist) && path != dir {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if path != dir && (strings.HasPrefix(d.Name(), ".") || strings.HasPrefix(d.Name(), "_")) {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if !isWatched(d.Name()) {
+				return nil
+			}
+			info, err := d.Info()
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			files[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+func isWatched(name string) bool {
+	return strings.HasSuffix(name, ".go") || name == "go.mod" || name == "go.work"
+}
+
+func changedFiles(before, after map[string]fileState) []string {
+	var changed []string
+	for path, state := range after {
+		if prev, ok := before[path]; !ok || prev != state {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	slices.Sort(changed)
+	return changed
+}
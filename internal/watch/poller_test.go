@@ -0,0 +1,125 @@
+package watch_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/samlitowitz/godepvis/internal/test"
+	"github.com/samlitowitz/godepvis/internal/watch"
+)
+
+func TestPoller_Wait(t *testing.T) {
+	testCases := map[string]struct {
+		// change changes the files of the module directory
+		change          func(dir string) error
+		expectedChanged []string
+	}{
+		"edited file": {
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "a", "a.go"), []byte("package a\n\nfunc A() {}\n"), 0o644)
+			},
+			expectedChanged: []string{"a/a.go"},
+		},
+		"added file": {
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "a", "b.go"), []byte("package a\n"), 0o644)
+			},
+			expectedChanged: []string{"a/b.go"},
+		},
+		"removed file": {
+			change: func(dir string) error {
+				return os.Remove(filepath.Join(dir, "a", "a.go"))
+			},
+			expectedChanged: []string{"a/a.go"},
+		},
+		"edited go.mod": {
+			change: func(dir string) error {
+				return os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/fake/fake\n\ngo 1.24.0\n"), 0o644)
+			},
+			expectedChanged: []string{"go.mod"},
+		},
+	}
+
+	for desc, testCase := range testCases {
+		func() {
+			dir := t.TempDir()
+			writeModule(t, dir)
+
+			poller, err := watch.NewPoller([]string{dir}, 10*time.Millisecond)
+			if err != nil {
+				t.Fatal(desc, ": NewPoller: ", err)
+			}
+			if err = testCase.change(dir); err != nil {
+				t.Fatal(desc, ": change: ", err)
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			changed, err := poller.Wait(ctx)
+			if err != nil {
+				t.Fatal(desc, ": Wait: ", err)
+			}
+			var actualChanged []string
+			for _, path := range changed {
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					t.Fatal(desc, ": ", err)
+				}
+				actualChanged = append(actualChanged, filepath.ToSlash(rel))
+			}
+			if diff := cmp.Diff(testCase.expectedChanged, actualChanged); diff != "" {
+				t.Error(desc, test.Mismatch(": expected changed files: ", diff))
+			}
+		}()
+	}
+}
+
+func TestPoller_Wait_IgnoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeModule(t, dir)
+
+	poller, err := watch.NewPoller([]string{dir}, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal("NewPoller: ", err)
+	}
+	// neither files other than .go files nor skipped directories
+	for _, path := range []string{"imports.dot", filepath.Join("_skipped", "a.go"), filepath.Join(".hidden", "a.go")} {
+		path = filepath.Join(dir, path)
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(path, []byte("package skipped\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	changed, err := poller.Wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected no changes, got %v: %v", changed, err)
+	}
+}
+
+func writeModule(t *testing.T, dir string) {
+	t.Helper()
+	files := map[string]string{
+		"go.mod":  "module github.com/fake/fake\n\ngo 1.24\n",
+		"a/a.go":  "package a\n",
+		"main.go": "package main\n",
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+#!/usr/bin/env bash
+
+# Builds the module from a git archive export of HEAD, the go command builds
+# module zips the same way so files dropped by export-ignore rules, e.g.
+# embedded assets, break go install
+
+set -e
+
+REF="${1:-HEAD}"
+ARCHIVE_DIR="$(mktemp -d)"
+trap 'rm -rf "$ARCHIVE_DIR"' EXIT
+
+echo "Export $REF"
+git archive "$REF" | tar -x -C "$ARCHIVE_DIR"
+
+echo "Build export"
+cd "$ARCHIVE_DIR"
+go build ./...
+go vet ./...
@@ -6,6 +6,9 @@ EXAMPLE_ASSETS=$ASSETS/examples
 EXAMPLES_DIR=$BASE_DIR/examples
 BUILD_FOR_MODULE_TEST_DIR=$BASE_DIR/internal/primitives/testdata/build-for-module
 
+# the PNGs are only rendered when Graphviz is installed, the SVGs never need it
+DOT="$(command -v dot)"
+
 echo "Remove existing example outputs"
 rm -rf $EXAMPLE_ASSETS/*
 
@@ -28,12 +31,18 @@ for d in $EXAMPLES_DIR/*/ ; do
     echo "Processing $d"
 
     echo "File Resolution"
-    godepvis $palette --path $d --resolution file --dot $outputDir/file.dot
-    dot -Tpng -o $outputDir/file.png $outputDir/file.dot
+    godepvis $palette --path $d --resolution file --output $outputDir/file.dot
+    godepvis $palette --path $d --resolution file --format svg --output $outputDir/file.svg
+    if [ -n "$DOT" ]; then
+      $DOT -Tpng -o $outputDir/file.png $outputDir/file.dot
+    fi
 
     echo "Package Resolution"
-    godepvis $palette --path $d --resolution package --dot $outputDir/package.dot
-    dot -Tpng -o $outputDir/package.png $outputDir/package.dot
+    godepvis $palette --path $d --resolution package --output $outputDir/package.dot
+    godepvis $palette --path $d --resolution package --format svg --output $outputDir/package.svg
+    if [ -n "$DOT" ]; then
+      $DOT -Tpng -o $outputDir/package.png $outputDir/package.dot
+    fi
 done
 
 for d in $BUILD_FOR_MODULE_TEST_DIR/*/ ; do
@@ -53,10 +62,16 @@ for d in $BUILD_FOR_MODULE_TEST_DIR/*/ ; do
     echo "Processing $d"
 
     echo "File Resolution"
-    godepvis $palette --path $d --resolution file --dot $outputDir/file.dot
-    dot -Tpng -o $outputDir/file.png $outputDir/file.dot
+    godepvis $palette --path $d --resolution file --output $outputDir/file.dot
+    godepvis $palette --path $d --resolution file --format svg --output $outputDir/file.svg
+    if [ -n "$DOT" ]; then
+      $DOT -Tpng -o $outputDir/file.png $outputDir/file.dot
+    fi
 
     echo "Package Resolution"
-    godepvis $palette --path $d --resolution package --dot $outputDir/package.dot
-    dot -Tpng -o $outputDir/package.png $outputDir/package.dot
+    godepvis $palette --path $d --resolution package --output $outputDir/package.dot
+    godepvis $palette --path $d --resolution package --format svg --output $outputDir/package.svg
+    if [ -n "$DOT" ]; then
+      $DOT -Tpng -o $outputDir/package.png $outputDir/package.dot
+    fi
 done